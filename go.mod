@@ -1,3 +1,5 @@
 module github.com/rafaelmgr12/jingo
 
 go 1.23
+
+require golang.org/x/text v0.22.0
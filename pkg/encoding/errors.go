@@ -1,6 +1,10 @@
 package encoding
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
 
 // ErrorCode represents specific error types that can occur during encoding
 type ErrorCode string
@@ -26,6 +30,11 @@ const (
 	ErrInvalidOptions ErrorCode = "invalid_options"
 )
 
+// ErrMaxRecordsExceeded is returned by Decode once a decoder has read the number of records set
+// with WithMaxRecords, whether or not the stream holds more. It tells a stream cut off by the
+// limit apart from one that ended on its own
+var ErrMaxRecordsExceeded = errors.New("decoder reached its maximum number of records")
+
 // JSONError represents a structured error that occurs during JSON processing
 type JSONError struct {
 	// Code identifies the specific type of error
@@ -117,3 +126,51 @@ func NewUnmarshalTypeError(expected, got string) *JSONError {
 	return NewJSONError(ErrUnmarshalFailure,
 		fmt.Sprintf("cannot unmarshal %s into %s", got, expected))
 }
+
+// UnmarshalTypeError describes a JSON value that cannot be stored in the Go value it maps to,
+// along with where it was found
+type UnmarshalTypeError struct {
+	// Value describes the JSON value, such as "string" or "negative number"
+	Value string
+
+	// Type is the Go type the value could not be stored in
+	Type reflect.Type
+
+	// Field is the innermost struct field holding the value, as StructName.FieldName, or empty
+	// when the value is not stored in a struct field
+	Field string
+
+	// Path is the location of the value in the document, such as users[0].age, or empty for the
+	// root value
+	Path string
+
+	// Err is the error that rejected the value, such as a *time.ParseError, or nil when its JSON
+	// type alone does not fit
+	Err error
+}
+
+// Error implements the error interface, naming the field and path when they are known
+func (e *UnmarshalTypeError) Error() string {
+	msg := "cannot unmarshal " + e.Value + " into "
+
+	if e.Field != "" {
+		msg += fmt.Sprintf("field %s (%v)", e.Field, e.Type)
+	} else {
+		msg += e.Type.String()
+	}
+
+	if e.Path != "" {
+		msg += " at path " + e.Path
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+// Unwrap returns the error that rejected the value, if any
+func (e *UnmarshalTypeError) Unwrap() error {
+	return e.Err
+}
@@ -1,9 +1,13 @@
 package encoding
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/rafaelmgr12/jingo/pkg/parser"
 )
@@ -24,13 +28,15 @@ func Marshal(v interface{}, opts ...Option) ([]byte, error) {
 			WithValue(v)
 	}
 
-	var b strings.Builder
-	if err := writeValue(&b, value); err != nil {
+	result, err := parser.MarshalWith(value, parser.MarshalOptions{
+		NormalizeNumbers: options.NormalizeNumbers,
+		NoEscapeHTML:     true,
+	})
+	if err != nil {
 		return nil, NewJSONError(ErrMarshalFailure, "failed to write value").
 			WithCause(err)
 	}
 
-	result := []byte(b.String())
 	if !options.DisableSizeLimit && len(result) > options.MaxSize {
 		return nil, NewSizeExceededError(len(result), options.MaxSize)
 	}
@@ -38,17 +44,59 @@ func Marshal(v interface{}, opts ...Option) ([]byte, error) {
 	return result, nil
 }
 
+// valueType is the reflect.Type of the parser.Value interface
+var valueType = reflect.TypeOf((*parser.Value)(nil)).Elem()
+
+// numberType is the reflect.Type of json.Number, which is written as a number rather than a string
+var numberType = reflect.TypeOf(json.Number(""))
+
+// timeType is the reflect.Type of time.Time, which is decoded from RFC 3339 strings
+var timeType = reflect.TypeOf(time.Time{})
+
+// durationType is the reflect.Type of time.Duration, which is decoded from time.ParseDuration
+// strings or from a number of nanoseconds
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// textUnmarshaler mirrors encoding.TextUnmarshaler from the standard library. Types implementing
+// it are decoded from JSON strings by passing the string contents to UnmarshalText.
+type textUnmarshaler interface {
+	UnmarshalText(text []byte) error
+}
+
+// textUnmarshalerType is the reflect.Type of the textUnmarshaler interface
+var textUnmarshalerType = reflect.TypeOf((*textUnmarshaler)(nil)).Elem()
+
 // Unmarshal parses JSON data and stores the result in the value pointed to by v.
 // The target value must be a non-nil pointer.
 func Unmarshal(data []byte, v interface{}, opts ...Option) error {
+	return unmarshal(string(data), v, opts)
+}
+
+// ParseInto parses the JSON text in input and unmarshals it into a new value of type T, which it
+// returns, so that typed consumers can write cfg, err := ParseInto[Config](data). It accepts the
+// same options and reports the same errors as Unmarshal, including the JSON path of type errors.
+// On failure the zero value of T is returned.
+func ParseInto[T any](input string, opts ...Option) (T, error) {
+	var result T
+
+	if err := unmarshal(input, &result, opts); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, nil
+}
+
+// unmarshal implements Unmarshal and ParseInto.
+func unmarshal(input string, v interface{}, opts []Option) error {
 	options, err := applyOptions(opts...)
 	if err != nil {
 		return NewJSONError(ErrInvalidOptions, "invalid options configuration").
 			WithCause(err)
 	}
 
-	if !options.DisableSizeLimit && len(data) > options.MaxSize {
-		return NewSizeExceededError(len(data), options.MaxSize)
+	if !options.DisableSizeLimit && len(input) > options.MaxSize {
+		return NewSizeExceededError(len(input), options.MaxSize)
 	}
 
 	rv := reflect.ValueOf(v)
@@ -56,7 +104,7 @@ func Unmarshal(data []byte, v interface{}, opts ...Option) error {
 		return NewInvalidTargetError("unmarshal target must be a non-nil pointer")
 	}
 
-	l := parser.NewLexer(string(data))
+	l := parser.NewLexer(input)
 	p := parser.NewParser(l)
 
 	value, err := p.ParseJSON()
@@ -65,10 +113,17 @@ func Unmarshal(data []byte, v interface{}, opts ...Option) error {
 			WithCause(err)
 	}
 
-	if err := unmarshalValue(value, rv.Elem()); err != nil {
-		return NewJSONError(ErrUnmarshalFailure, "failed to unmarshal value").
+	if err := unmarshalValue(value, rv.Elem(), options); err != nil {
+		jsonErr := NewJSONError(ErrUnmarshalFailure, "failed to unmarshal value").
 			WithCause(err).
 			WithValue(v)
+
+		var typeErr *UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			jsonErr.WithPath(typeErr.Path)
+		}
+
+		return jsonErr
 	}
 
 	return nil
@@ -80,6 +135,15 @@ func marshalValue(v reflect.Value) (parser.Value, error) {
 		v = v.Elem()
 	}
 
+	// Trees built by the parser are written as they are
+	if v.Type().Implements(valueType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return &parser.Null{Token: parser.Token{Type: parser.TokenNull}}, nil
+		}
+
+		return v.Interface().(parser.Value), nil
+	}
+
 	if v.Type().Implements(reflect.TypeOf((*Marshaler)(nil)).Elem()) {
 		marshaler := v.Interface().(Marshaler)
 
@@ -99,6 +163,15 @@ func marshalValue(v reflect.Value) (parser.Value, error) {
 		return value, nil
 	}
 
+	if v.Type() == numberType {
+		num := parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: v.String()})
+		if !num.IsValidNumber() {
+			return nil, fmt.Errorf("invalid number literal %q", v.String())
+		}
+
+		return num, nil
+	}
+
 	switch v.Kind() {
 	case reflect.String:
 		return &parser.StringLiteral{
@@ -221,25 +294,56 @@ func marshalValue(v reflect.Value) (parser.Value, error) {
 }
 
 // unmarshalValue converts a parser.Value to a reflect.Value
-func unmarshalValue(v parser.Value, rv reflect.Value) error {
-	if unmarshaler, ok := rv.Addr().Interface().(Unmarshaler); ok {
-		var b strings.Builder
+func unmarshalValue(v parser.Value, rv reflect.Value, opts *Options) error {
+	if rv.Type() == timeType {
+		return unmarshalTime(v, rv)
+	}
+
+	if rv.Type() == durationType {
+		return unmarshalDuration(v, rv)
+	}
 
-		if err := writeValue(&b, v); err != nil {
+	if !rv.CanAddr() {
+		if reflect.PointerTo(rv.Type()).Implements(textUnmarshalerType) {
+			return fmt.Errorf("cannot unmarshal into non-addressable %v", rv.Type())
+		}
+	} else if unmarshaler, ok := rv.Addr().Interface().(Unmarshaler); ok {
+		data, err := parser.Marshal(v)
+		if err != nil {
 			return NewJSONError(ErrUnmarshalFailure, "failed to write value").WithCause(err)
 		}
 
-		if err := unmarshaler.UnmarshalJSON([]byte(b.String())); err != nil {
+		if err := unmarshaler.UnmarshalJSON(data); err != nil {
 			return NewJSONError(ErrUnmarshalFailure, "failed to unmarshal value").WithCause(err)
 		}
 
 		return nil
+	} else if unmarshaler, ok := rv.Addr().Interface().(textUnmarshaler); ok {
+		if str, ok := v.(*parser.StringLiteral); ok {
+			if err := unmarshaler.UnmarshalText([]byte(str.Value)); err != nil {
+				return fmt.Errorf("cannot unmarshal %q into %v: %v", str.Value, rv.Type(), err)
+			}
+
+			return nil
+		}
 	}
 
 	if v == nil {
 		return NewJSONError(ErrUnmarshalFailure, "value is nil")
 	}
 
+	if rv.Kind() == reflect.Ptr {
+		if _, ok := v.(*parser.Null); ok {
+			return unmarshalNull(rv)
+		}
+
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+
+		return unmarshalValue(v, rv.Elem(), opts)
+	}
+
 	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
 		switch val := v.(type) {
 		case *parser.Object:
@@ -247,8 +351,8 @@ func unmarshalValue(v parser.Value, rv reflect.Value) error {
 
 			for k, v := range val.Pairs {
 				var mapValue interface{}
-				if err := unmarshalValue(v, reflect.ValueOf(&mapValue).Elem()); err != nil {
-					return fmt.Errorf("map key %q: %v", k, err)
+				if err := unmarshalValue(v, reflect.ValueOf(&mapValue).Elem(), opts); err != nil {
+					return atKey(err, k, "", fmt.Sprintf("map key %q", k))
 				}
 
 				obj[k] = mapValue
@@ -261,8 +365,8 @@ func unmarshalValue(v parser.Value, rv reflect.Value) error {
 
 			for i, elem := range val.Elements {
 				var arrayValue interface{}
-				if err := unmarshalValue(elem, reflect.ValueOf(&arrayValue).Elem()); err != nil {
-					return fmt.Errorf("index %d: %v", i, err)
+				if err := unmarshalValue(elem, reflect.ValueOf(&arrayValue).Elem(), opts); err != nil {
+					return atIndex(err, i)
 				}
 
 				arr[i] = arrayValue
@@ -274,9 +378,12 @@ func unmarshalValue(v parser.Value, rv reflect.Value) error {
 			rv.Set(reflect.ValueOf(val.Value))
 
 		case *parser.NumberLiteral:
-			if val.IsInt {
+			switch {
+			case opts.NumberMode == RawNumber:
+				rv.Set(reflect.ValueOf(val.JSONNumber()))
+			case opts.NumberMode == IntOrFloat && val.IsInt:
 				rv.Set(reflect.ValueOf(val.Int))
-			} else {
+			default:
 				rv.Set(reflect.ValueOf(val.Float))
 			}
 
@@ -295,10 +402,10 @@ func unmarshalValue(v parser.Value, rv reflect.Value) error {
 
 	switch val := v.(type) {
 	case *parser.Object:
-		return unmarshalObject(val, rv)
+		return unmarshalObject(val, rv, opts)
 
 	case *parser.Array:
-		return unmarshalArray(val, rv)
+		return unmarshalArray(val, rv, opts)
 
 	case *parser.StringLiteral:
 		return unmarshalString(val, rv)
@@ -318,22 +425,29 @@ func unmarshalValue(v parser.Value, rv reflect.Value) error {
 }
 
 // unmarshalObject handles unmarshaling of JSON objects into Go structs or maps
-func unmarshalObject(obj *parser.Object, rv reflect.Value) error {
+func unmarshalObject(obj *parser.Object, rv reflect.Value, opts *Options) error {
 	switch rv.Kind() {
 	case reflect.Map:
+		// JSON keys are always strings, so only maps keyed by a string type can hold them
+		keyType := rv.Type().Key()
+		if keyType.Kind() != reflect.String {
+			return fmt.Errorf("cannot unmarshal object into %v: map key must be string", rv.Type())
+		}
+
 		if rv.IsNil() {
 			rv.Set(reflect.MakeMap(rv.Type()))
 		}
 
+		elemType := rv.Type().Elem()
+
 		for k, v := range obj.Pairs {
-			elemType := rv.Type().Elem()
 			mapValue := reflect.New(elemType).Elem()
 
-			if err := unmarshalValue(v, mapValue); err != nil {
-				return fmt.Errorf("map value %q: %v", k, err)
+			if err := unmarshalValue(v, mapValue, opts); err != nil {
+				return atKey(err, k, "", fmt.Sprintf("map key %q", k))
 			}
 
-			rv.SetMapIndex(reflect.ValueOf(k), mapValue)
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(keyType), mapValue)
 		}
 
 	case reflect.Struct:
@@ -353,27 +467,27 @@ func unmarshalObject(obj *parser.Object, rv reflect.Value) error {
 			}
 
 			if v, ok := obj.Pairs[name]; ok {
-				if err := unmarshalValue(v, rv.Field(i)); err != nil {
-					return fmt.Errorf("field %s: %v", name, err)
+				if err := unmarshalValue(v, rv.Field(i), opts); err != nil {
+					return atKey(err, name, strings.TrimPrefix(t.Name()+"."+field.Name, "."), "field "+name)
 				}
 			}
 		}
 
 	default:
-		return fmt.Errorf("cannot unmarshal object into %v", rv.Type())
+		return &UnmarshalTypeError{Value: "object", Type: rv.Type()}
 	}
 
 	return nil
 }
 
 // unmarshalArray handles unmarshaling of JSON arrays into Go slices or arrays
-func unmarshalArray(arr *parser.Array, rv reflect.Value) error {
+func unmarshalArray(arr *parser.Array, rv reflect.Value, opts *Options) error {
 	switch rv.Kind() {
 	case reflect.Slice:
 		slice := reflect.MakeSlice(rv.Type(), len(arr.Elements), len(arr.Elements))
 		for i, elem := range arr.Elements {
-			if err := unmarshalValue(elem, slice.Index(i)); err != nil {
-				return fmt.Errorf("index %d: %v", i, err)
+			if err := unmarshalValue(elem, slice.Index(i), opts); err != nil {
+				return atIndex(err, i)
 			}
 		}
 
@@ -386,22 +500,35 @@ func unmarshalArray(arr *parser.Array, rv reflect.Value) error {
 		}
 
 		for i, elem := range arr.Elements {
-			if err := unmarshalValue(elem, rv.Index(i)); err != nil {
-				return fmt.Errorf("index %d: %v", i, err)
+			if err := unmarshalValue(elem, rv.Index(i), opts); err != nil {
+				return atIndex(err, i)
 			}
 		}
 
 	default:
-		return fmt.Errorf("cannot unmarshal array into %v", rv.Type())
+		return &UnmarshalTypeError{Value: "array", Type: rv.Type()}
 	}
 
 	return nil
 }
 
-// unmarshalString handles unmarshaling of JSON strings into Go strings
+// unmarshalString handles unmarshaling of JSON strings into Go strings, and of base64 strings
+// into byte slices
 func unmarshalString(str *parser.StringLiteral, rv reflect.Value) error {
+	// Like encoding/json, a string stored in a byte slice is standard base64
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		data, err := base64.StdEncoding.DecodeString(str.Value)
+		if err != nil {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("invalid base64 string (%v)", err), Type: rv.Type()}
+		}
+
+		rv.SetBytes(data)
+
+		return nil
+	}
+
 	if rv.Kind() != reflect.String {
-		return fmt.Errorf("cannot unmarshal string into %v", rv.Type())
+		return &UnmarshalTypeError{Value: "string", Type: rv.Type()}
 	}
 
 	rv.SetString(str.Value)
@@ -414,14 +541,14 @@ func unmarshalNumber(num *parser.NumberLiteral, rv reflect.Value) error {
 	switch rv.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if !num.IsInt {
-			return fmt.Errorf("cannot unmarshal float into %v", rv.Type())
+			return &UnmarshalTypeError{Value: "float", Type: rv.Type()}
 		}
 
 		rv.SetInt(num.Int)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if !num.IsInt || num.Int < 0 {
-			return fmt.Errorf("cannot unmarshal negative number into %v", rv.Type())
+			return &UnmarshalTypeError{Value: "negative number", Type: rv.Type()}
 		}
 
 		rv.SetUint(uint64(num.Int))
@@ -430,7 +557,7 @@ func unmarshalNumber(num *parser.NumberLiteral, rv reflect.Value) error {
 		rv.SetFloat(num.Float)
 
 	default:
-		return fmt.Errorf("cannot unmarshal number into %v", rv.Type())
+		return &UnmarshalTypeError{Value: "number", Type: rv.Type()}
 	}
 
 	return nil
@@ -439,7 +566,7 @@ func unmarshalNumber(num *parser.NumberLiteral, rv reflect.Value) error {
 // unmarshalBool handles unmarshaling of JSON booleans into Go bools
 func unmarshalBool(b *parser.Boolean, rv reflect.Value) error {
 	if rv.Kind() != reflect.Bool {
-		return fmt.Errorf("cannot unmarshal boolean into %v", rv.Type())
+		return &UnmarshalTypeError{Value: "boolean", Type: rv.Type()}
 	}
 
 	rv.SetBool(b.Value)
@@ -447,74 +574,106 @@ func unmarshalBool(b *parser.Boolean, rv reflect.Value) error {
 	return nil
 }
 
-// unmarshalNull handles unmarshaling of JSON null into Go values
-func unmarshalNull(rv reflect.Value) error {
-	switch rv.Kind() {
-	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+// unmarshalTime handles unmarshaling of RFC 3339 JSON strings into time.Time values
+func unmarshalTime(v parser.Value, rv reflect.Value) error {
+	switch val := v.(type) {
+	case *parser.StringLiteral:
+		t, err := time.Parse(time.RFC3339, val.Value)
+		if err != nil {
+			return &UnmarshalTypeError{Value: "invalid RFC 3339 timestamp", Type: rv.Type(), Err: err}
+		}
+
+		rv.Set(reflect.ValueOf(t))
+
+	case *parser.Null:
 		rv.Set(reflect.Zero(rv.Type()))
-		return nil
+
 	default:
-		return fmt.Errorf("cannot unmarshal null into %v", rv.Type())
+		return &UnmarshalTypeError{Value: "non-string value", Type: rv.Type()}
 	}
+
+	return nil
 }
 
-// writeValue writes a parser.Value to a strings.Builder
-func writeValue(b *strings.Builder, v parser.Value) error {
+// unmarshalDuration handles unmarshaling of JSON strings such as "1h30m" and of integer numbers of
+// nanoseconds, as encoding/json writes them, into time.Duration values
+func unmarshalDuration(v parser.Value, rv reflect.Value) error {
 	switch val := v.(type) {
-	case *parser.Object:
-		b.WriteString("{")
-
-		i := 0
-		for k, v := range val.Pairs {
-			if i > 0 {
-				b.WriteString(",")
-			}
-
-			fmt.Fprintf(b, "%q:", k)
+	case *parser.StringLiteral:
+		d, err := time.ParseDuration(val.Value)
+		if err != nil {
+			return &UnmarshalTypeError{Value: "invalid duration string", Type: rv.Type(), Err: err}
+		}
 
-			if err := writeValue(b, v); err != nil {
-				return err
-			}
+		rv.SetInt(int64(d))
 
-			i++
+	case *parser.NumberLiteral:
+		if !val.IsInt {
+			return &UnmarshalTypeError{Value: "float", Type: rv.Type()}
 		}
 
-		b.WriteString("}")
+		rv.SetInt(val.Int)
 
-	case *parser.Array:
-		b.WriteString("[")
+	case *parser.Null:
+		rv.Set(reflect.Zero(rv.Type()))
 
-		for i, v := range val.Elements {
-			if i > 0 {
-				b.WriteString(",")
-			}
+	default:
+		return &UnmarshalTypeError{Value: "non-string, non-number value", Type: rv.Type()}
+	}
 
-			if err := writeValue(b, v); err != nil {
-				return err
-			}
-		}
+	return nil
+}
 
-		b.WriteString("]")
+// unmarshalNull handles unmarshaling of JSON null into Go values
+func unmarshalNull(rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	default:
+		return &UnmarshalTypeError{Value: "null", Type: rv.Type()}
+	}
+}
 
-	case *parser.StringLiteral:
-		fmt.Fprintf(b, "%q", val.Value)
+// atKey records that err occurred in the value stored under key. A type error gets the key
+// prepended to its path, and field, when it names a struct field as StructName.FieldName, unless a
+// more deeply nested field was already recorded. Any other error is prefixed with context.
+func atKey(err error, key, field, context string) error {
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return fmt.Errorf("%s: %v", context, err)
+	}
 
-	case *parser.NumberLiteral:
-		b.WriteString(val.String())
+	if typeErr.Field == "" {
+		typeErr.Field = field
+	}
 
-	case *parser.Boolean:
-		if val.Value {
-			b.WriteString("true")
-		} else {
-			b.WriteString("false")
-		}
+	switch {
+	case typeErr.Path == "":
+		typeErr.Path = key
+	case strings.HasPrefix(typeErr.Path, "["):
+		typeErr.Path = key + typeErr.Path
+	default:
+		typeErr.Path = key + "." + typeErr.Path
+	}
 
-	case *parser.Null:
-		b.WriteString("null")
+	return err
+}
+
+// atIndex records that err occurred in the array element at index i. A type error gets the index
+// prepended to its path. Any other error is prefixed with the index.
+func atIndex(err error, i int) error {
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return fmt.Errorf("index %d: %v", i, err)
+	}
 
+	switch {
+	case typeErr.Path == "", strings.HasPrefix(typeErr.Path, "["):
+		typeErr.Path = fmt.Sprintf("[%d]%s", i, typeErr.Path)
 	default:
-		return fmt.Errorf("unknown value type: %T", v)
+		typeErr.Path = fmt.Sprintf("[%d].%s", i, typeErr.Path)
 	}
 
-	return nil
+	return err
 }
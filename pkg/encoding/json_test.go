@@ -1,12 +1,18 @@
 package encoding_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/netip"
 	"reflect"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/rafaelmgr12/jingo/pkg/encoding"
+	"github.com/rafaelmgr12/jingo/pkg/parser"
 )
 
 func TestUnmarshalWithByteInput(t *testing.T) {
@@ -451,3 +457,450 @@ func checkJSONError(t *testing.T, err error, expectedCode encoding.ErrorCode, ex
 		t.Errorf("expected JSONError, got %T: %v", err, err)
 	}
 }
+
+func TestUnmarshalTime(t *testing.T) {
+	type event struct {
+		Name      string     `json:"name"`
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt *time.Time `json:"updated_at"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}
+
+	input := []byte(`{
+		"name": "deploy",
+		"created_at": "2024-03-01T12:30:00Z",
+		"updated_at": "2024-03-02T08:00:00+02:00",
+		"deleted_at": null
+	}`)
+
+	var e event
+	if err := encoding.Unmarshal(input, &e); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC); !e.CreatedAt.Equal(want) {
+		t.Errorf("Expected created_at %v, got %v", want, e.CreatedAt)
+	}
+
+	if e.UpdatedAt == nil || !e.UpdatedAt.Equal(time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected updated_at %v", e.UpdatedAt)
+	}
+
+	if e.DeletedAt != nil {
+		t.Errorf("Expected nil deleted_at, got %v", e.DeletedAt)
+	}
+
+	err := encoding.Unmarshal([]byte(`{"created_at": "yesterday"}`), &e)
+	if err == nil {
+		t.Fatal("Expected error for invalid timestamp")
+	}
+
+	var typeErr *encoding.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Path != "created_at" || typeErr.Field != "event.CreatedAt" {
+		t.Fatalf("Expected a type error at created_at, got %v", err)
+	}
+
+	var parseErr *time.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Value != "yesterday" {
+		t.Errorf("Expected the time.ParseError to be kept, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "invalid RFC 3339 timestamp into field event.CreatedAt") {
+		t.Errorf("Expected path-qualified timestamp error, got %v", err)
+	}
+}
+
+func TestUnmarshalDuration(t *testing.T) {
+	type config struct {
+		Timeout  time.Duration   `json:"timeout"`
+		Interval time.Duration   `json:"interval"`
+		Retries  []time.Duration `json:"retries"`
+		Grace    *time.Duration  `json:"grace"`
+	}
+
+	input := []byte(`{
+		"timeout": "1h30m",
+		"interval": 1500000000,
+		"retries": ["100ms", 2000],
+		"grace": "-2.5s"
+	}`)
+
+	var c config
+	if err := encoding.Unmarshal(input, &c); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := 90 * time.Minute; c.Timeout != want {
+		t.Errorf("Expected timeout %v, got %v", want, c.Timeout)
+	}
+
+	if want := 1500 * time.Millisecond; c.Interval != want {
+		t.Errorf("Expected interval %v, got %v", want, c.Interval)
+	}
+
+	if len(c.Retries) != 2 || c.Retries[0] != 100*time.Millisecond || c.Retries[1] != 2000 {
+		t.Errorf("Unexpected retries %v", c.Retries)
+	}
+
+	if c.Grace == nil || *c.Grace != -2500*time.Millisecond {
+		t.Errorf("Unexpected grace %v", c.Grace)
+	}
+
+	err := encoding.Unmarshal([]byte(`{"timeout": "soon"}`), &c)
+	if err == nil {
+		t.Fatal("Expected error for invalid duration")
+	}
+
+	var typeErr *encoding.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Path != "timeout" || typeErr.Field != "config.Timeout" {
+		t.Fatalf("Expected a type error at timeout, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), `time: invalid duration "soon"`) {
+		t.Errorf("Expected the time.ParseDuration error to be kept, got %v", err)
+	}
+
+	err = encoding.Unmarshal([]byte(`{"retries": ["1s", "later"]}`), &c)
+	if !errors.As(err, &typeErr) || typeErr.Path != "retries[1]" {
+		t.Errorf("Expected a type error at retries[1] for an invalid duration string, got %v", err)
+	}
+
+	err = encoding.Unmarshal([]byte(`{"retries": [1, 1.5]}`), &c)
+	if !errors.As(err, &typeErr) || typeErr.Path != "retries[1]" {
+		t.Errorf("Expected a type error at retries[1] for a fractional duration, got %v", err)
+	}
+}
+
+// upperText is a test type that implements encoding.TextUnmarshaler
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty text")
+	}
+
+	*u = upperText(strings.ToUpper(string(text)))
+
+	return nil
+}
+
+func TestUnmarshalTextUnmarshaler(t *testing.T) {
+	type host struct {
+		Addr  netip.Addr  `json:"addr"`
+		Name  upperText   `json:"name"`
+		Alias *upperText  `json:"alias"`
+		Peers []upperText `json:"peers"`
+	}
+
+	var h host
+
+	input := []byte(`{"addr": "192.168.0.1", "name": "web", "alias": "www", "peers": ["a", "b"]}`)
+	if err := encoding.Unmarshal(input, &h); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if h.Addr != netip.MustParseAddr("192.168.0.1") {
+		t.Errorf("Unexpected addr %v", h.Addr)
+	}
+
+	if h.Name != "WEB" || h.Alias == nil || *h.Alias != "WWW" {
+		t.Errorf("Unexpected names %q, %v", h.Name, h.Alias)
+	}
+
+	if !reflect.DeepEqual(h.Peers, []upperText{"A", "B"}) {
+		t.Errorf("Unexpected peers %v", h.Peers)
+	}
+
+	err := encoding.Unmarshal([]byte(`{"addr": "not-an-ip"}`), &h)
+	if err == nil || !strings.Contains(err.Error(), "field addr") {
+		t.Errorf("Expected field-qualified error, got %v", err)
+	}
+
+	var names map[string]upperText
+
+	err = encoding.Unmarshal([]byte(`{"a": "x", "b": ""}`), &names)
+	if expected := `map key "b": cannot unmarshal "" into encoding_test.upperText`; err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestMarshalNormalizeNumbers(t *testing.T) {
+	value, err := parser.Parse(`{"big": 1e3, "ratio": 2.50}`)
+	if err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	data, err := encoding.Marshal(value)
+	if err != nil {
+		t.Fatalf("Error marshaling value: %v", err)
+	}
+
+	if expected := `{"big":1e3,"ratio":2.50}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	data, err = encoding.Marshal(value, encoding.WithNormalizeNumbers())
+	if err != nil {
+		t.Fatalf("Error marshaling value: %v", err)
+	}
+
+	if expected := `{"big":1000,"ratio":2.5}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestUnmarshalTypeErrorPath(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	type Payload struct {
+		User   User           `json:"user"`
+		Users  []User         `json:"users"`
+		Scores map[string]int `json:"scores"`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		path     string
+	}{
+		{"Nested field", `{"user": {"age": "ten"}}`, "cannot unmarshal string into field User.Age (int) at path user.age", "user.age"},
+		{"Slice element", `{"users": [{"age": 1}, {"name": 7}]}`, "cannot unmarshal number into field User.Name (string) at path users[1].name", "users[1].name"},
+		{"Map value", `{"scores": {"math": true}}`, "cannot unmarshal boolean into field Payload.Scores (int) at path scores.math", "scores.math"},
+		{"Root", `[1]`, "cannot unmarshal array into encoding_test.Payload", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+
+			err := encoding.Unmarshal([]byte(tt.input), &p)
+
+			var typeErr *encoding.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("Expected an UnmarshalTypeError, got %v", err)
+			}
+
+			if typeErr.Error() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, typeErr.Error())
+			}
+
+			var jsonErr *encoding.JSONError
+			if !errors.As(err, &jsonErr) || jsonErr.Path != tt.path {
+				t.Errorf("Expected the JSONError path %q, got %v", tt.path, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTypedMaps(t *testing.T) {
+	type Point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+
+	type Color string
+
+	var counts map[string]int
+	if err := encoding.Unmarshal([]byte(`{"a": 1, "b": 2}`), &counts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+
+	var points map[string]Point
+	if err := encoding.Unmarshal([]byte(`{"origin": {"x": 0, "y": 0}, "p": {"x": 3, "y": -4}}`), &points); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := map[string]Point{"origin": {}, "p": {X: 3, Y: -4}}; !reflect.DeepEqual(points, expected) {
+		t.Errorf("Expected %v, got %v", expected, points)
+	}
+
+	var named map[Color][]string
+	if err := encoding.Unmarshal([]byte(`{"red": ["apple"]}`), &named); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := map[Color][]string{"red": {"apple"}}; !reflect.DeepEqual(named, expected) {
+		t.Errorf("Expected %v, got %v", expected, named)
+	}
+
+	err := encoding.Unmarshal([]byte(`{"p": {"x": 1, "y": "two"}}`), &points)
+
+	var typeErr *encoding.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Path != "p.y" {
+		t.Errorf("Expected a type error at path p.y, got %v", err)
+	}
+
+	var byID map[int]string
+
+	err = encoding.Unmarshal([]byte(`{"1": "a"}`), &byID)
+	if err == nil || !strings.Contains(err.Error(), "map key must be string") {
+		t.Errorf("Expected an error for a non-string key type, got %v", err)
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	type Config struct {
+		Host  string   `json:"host"`
+		Port  int      `json:"port"`
+		Tags  []string `json:"tags"`
+		Debug bool     `json:"debug"`
+	}
+
+	cfg, err := encoding.ParseInto[Config](`{"host": "example.com", "port": 8080, "tags": ["a", "b"], "debug": true}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Config{Host: "example.com", Port: 8080, Tags: []string{"a", "b"}, Debug: true}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+
+	t.Run("Non-struct types", func(t *testing.T) {
+		ports, err := encoding.ParseInto[[]int](`[80, 443]`)
+		if err != nil || !reflect.DeepEqual(ports, []int{80, 443}) {
+			t.Errorf("Expected [80 443], got %v (%v)", ports, err)
+		}
+
+		lookup, err := encoding.ParseInto[map[string]bool](`{"a": true}`)
+		if err != nil || !reflect.DeepEqual(lookup, map[string]bool{"a": true}) {
+			t.Errorf("Expected map[a:true], got %v (%v)", lookup, err)
+		}
+	})
+
+	t.Run("Type error with path", func(t *testing.T) {
+		cfg, err := encoding.ParseInto[Config](`{"host": "example.com", "port": "eighty"}`)
+
+		var jsonErr *encoding.JSONError
+		if !errors.As(err, &jsonErr) || jsonErr.Path != "port" {
+			t.Fatalf("Expected a JSONError at path port, got %v", err)
+		}
+
+		if !reflect.DeepEqual(cfg, Config{}) {
+			t.Errorf("Expected the zero value on failure, got %+v", cfg)
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		if _, err := encoding.ParseInto[Config](`{"host": }`); err == nil {
+			t.Error("Expected an error for malformed JSON")
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		if _, err := encoding.ParseInto[Config](`{"host": "example.com"}`, encoding.WithMaxSize(8)); err == nil {
+			t.Error("Expected an error for input over the maximum size")
+		}
+	})
+}
+
+func TestUnmarshalBase64(t *testing.T) {
+	type Attachment struct {
+		Name string `json:"name"`
+		Data []byte `json:"data"`
+	}
+
+	type Message struct {
+		Attachments []Attachment `json:"attachments"`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []byte
+		err      string
+	}{
+		{"Valid", `{"attachments": [{"name": "a", "data": "aGVsbG8gd29ybGQ="}]}`, []byte("hello world"), ""},
+		{"Empty", `{"attachments": [{"data": ""}]}`, []byte{}, ""},
+		{"Array of numbers", `{"attachments": [{"data": [104, 105]}]}`, []byte("hi"), ""},
+		{"Malformed", `{"attachments": [{"data": "aGVsbG8*"}]}`, nil, "cannot unmarshal invalid base64 string (illegal base64 data at input byte 7) into field Attachment.Data ([]uint8) at path attachments[0].data"},
+		{"Bad padding", `{"attachments": [{"data": "aGVsbG8"}]}`, nil, "cannot unmarshal invalid base64 string (illegal base64 data at input byte 4) into field Attachment.Data ([]uint8) at path attachments[0].data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Message
+
+			err := encoding.Unmarshal([]byte(tt.input), &m)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if got := m.Attachments[0].Data; !bytes.Equal(got, tt.expected) {
+					t.Errorf("Expected %q, got %q", tt.expected, got)
+				}
+
+				return
+			}
+
+			var typeErr *encoding.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("Expected an UnmarshalTypeError, got %v", err)
+			}
+
+			if typeErr.Error() != tt.err {
+				t.Errorf("Expected %q, got %q", tt.err, typeErr.Error())
+			}
+		})
+	}
+}
+
+func TestUnmarshalNumberMode(t *testing.T) {
+	const input = `{"id": 9007199254740993, "price": 1.50, "big": 1e3}`
+
+	tests := []struct {
+		name     string
+		opts     []encoding.Option
+		expected map[string]interface{}
+	}{
+		{"Default", nil, map[string]interface{}{"id": int64(9007199254740993), "price": 1.5, "big": 1000.0}},
+		{"IntOrFloat", []encoding.Option{encoding.WithNumberMode(encoding.IntOrFloat)}, map[string]interface{}{"id": int64(9007199254740993), "price": 1.5, "big": 1000.0}},
+		{"Float64", []encoding.Option{encoding.WithNumberMode(encoding.Float64)}, map[string]interface{}{"id": 9007199254740992.0, "price": 1.5, "big": 1000.0}},
+		{"RawNumber", []encoding.Option{encoding.WithNumberMode(encoding.RawNumber)}, map[string]interface{}{"id": json.Number("9007199254740993"), "price": json.Number("1.50"), "big": json.Number("1e3")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			if err := encoding.Unmarshal([]byte(input), &got, tt.opts...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("RawNumber round trip", func(t *testing.T) {
+		var got []interface{}
+		if err := encoding.Unmarshal([]byte(`[1.50, 2]`), &got, encoding.WithNumberMode(encoding.RawNumber)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := encoding.Marshal(got)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if string(data) != `[1.50,2]` {
+			t.Errorf("Expected [1.50,2], got %s", data)
+		}
+	})
+
+	t.Run("Unknown mode", func(t *testing.T) {
+		var got interface{}
+		if err := encoding.Unmarshal([]byte(`[1]`), &got, encoding.WithNumberMode(encoding.NumberMode(7))); err == nil {
+			t.Error("Expected an error, got nil")
+		}
+	})
+}
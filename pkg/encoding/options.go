@@ -14,6 +14,25 @@ const (
 	MaximumMaxSize = 1024 * 1024 * 1024
 )
 
+// NumberMode selects the Go type that numbers take when they are unmarshaled into an empty
+// interface, such as the values of a map[string]interface{}.
+type NumberMode int
+
+const (
+	// IntOrFloat stores integers that fit in an int64 as int64 and every other number as float64,
+	// so that IDs and counts keep their exact value. It is the default.
+	IntOrFloat NumberMode = iota
+
+	// Float64 stores every number as float64, like encoding/json does. Integers beyond 2^53 lose
+	// precision, and code expecting the types of encoding/json gets them.
+	Float64
+
+	// RawNumber stores every number as a json.Number holding its original literal, such as 1.50
+	// or 1e3, which keeps any precision and leaves the conversion to the caller. Marshal writes
+	// json.Number values back as numbers.
+	RawNumber
+)
+
 // Options holds all configuration options for the JSON parser
 type Options struct {
 	// MaxSize defines the maximum size of the input that the parser will accept
@@ -27,6 +46,20 @@ type Options struct {
 
 	// BufferSize defines the size of the internal buffer
 	BufferSize int
+
+	// NormalizeNumbers writes numbers in the canonical RFC 8785 form instead of their original literal
+	NormalizeNumbers bool
+
+	// MaxRecordSize bounds the number of bytes a single record read by a decoder may span, so a
+	// malformed stream cannot make it buffer input indefinitely. Zero means no limit
+	MaxRecordSize int
+
+	// MaxRecords bounds the number of records a decoder reads from its stream, so a producer that
+	// never stops cannot keep a consumer loop running forever. Zero means no limit
+	MaxRecords int
+
+	// NumberMode selects the Go type of numbers unmarshaled into an empty interface
+	NumberMode NumberMode
 }
 
 // Validate checks if the options are valid
@@ -102,6 +135,55 @@ func WithBufferSize(size int) Option {
 	}
 }
 
+// WithNormalizeNumbers writes numbers in the canonical RFC 8785 form, so 1e3 becomes 1000
+func WithNormalizeNumbers() Option {
+	return func(o *Options) error {
+		o.NormalizeNumbers = true
+
+		return nil
+	}
+}
+
+// WithMaxRecordSize bounds the number of bytes a single decoded record may span
+func WithMaxRecordSize(size int) Option {
+	return func(o *Options) error {
+		if size <= 0 {
+			return fmt.Errorf("max record size must be positive, got %d", size)
+		}
+
+		o.MaxRecordSize = size
+
+		return nil
+	}
+}
+
+// WithMaxRecords bounds the number of records a decoder reads before Decode returns
+// ErrMaxRecordsExceeded
+func WithMaxRecords(n int) Option {
+	return func(o *Options) error {
+		if n <= 0 {
+			return fmt.Errorf("max records must be positive, got %d", n)
+		}
+
+		o.MaxRecords = n
+
+		return nil
+	}
+}
+
+// WithNumberMode selects the Go type of numbers unmarshaled into an empty interface
+func WithNumberMode(mode NumberMode) Option {
+	return func(o *Options) error {
+		if mode < IntOrFloat || mode > RawNumber {
+			return fmt.Errorf("unknown number mode %d", mode)
+		}
+
+		o.NumberMode = mode
+
+		return nil
+	}
+}
+
 // applyOptions applies the given options to the default options
 func applyOptions(opts ...Option) (*Options, error) {
 	options := defaultOptions()
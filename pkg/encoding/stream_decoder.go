@@ -19,6 +19,7 @@ type streamDecoder struct {
 	mutex      sync.Mutex
 	buffer     []byte
 	bufferSize int // Added to track buffer size
+	records    int // Records read so far, checked against MaxRecords
 }
 
 // NewDecoder creates a new JSONDecoder implementation
@@ -35,7 +36,7 @@ func NewDecoder(r io.Reader, opts ...Option) (JSONDecoder, error) {
 
 	reader := bufio.NewReader(r)
 	lexer := parser.NewLexer(reader)
-	parser := parser.NewParser(lexer)
+	parser := parser.NewParser(lexer, parser.WithMaxDocumentSize(options.MaxRecordSize))
 
 	return &streamDecoder{
 		reader:     reader,
@@ -52,12 +53,18 @@ func (d *streamDecoder) Decode(v interface{}) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	if d.options.MaxRecords > 0 && d.records >= d.options.MaxRecords {
+		return ErrMaxRecordsExceeded
+	}
+
 	value, err := d.parser.ParseJSON()
 	if err != nil {
 		return NewJSONError(ErrInvalidJSON, "failed to parse JSON stream").WithCause(err)
 	}
 
-	return unmarshalValue(value, reflect.ValueOf(v).Elem())
+	d.records++
+
+	return unmarshalValue(value, reflect.ValueOf(v).Elem(), d.options)
 }
 
 // More implements JSONDecoder.More
@@ -3,6 +3,7 @@ package encoding_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -190,3 +191,93 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoderMaxRecordSize(t *testing.T) {
+	record := `{"id": 1, "tags": ["` + strings.Repeat("x", 4096) + `"]}`
+
+	decoder, err := encoding.NewDecoder(strings.NewReader(record), encoding.WithMaxRecordSize(1024))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result map[string]interface{}
+
+	err = decoder.Decode(&result)
+	if err == nil || !strings.Contains(err.Error(), "document exceeds maximum size of 1024 bytes") {
+		t.Fatalf("Expected the record size limit in the error, got %v", err)
+	}
+
+	decoder, err = encoding.NewDecoder(strings.NewReader(record), encoding.WithMaxRecordSize(len(record)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatalf("Unexpected error for a record within the limit: %v", err)
+	}
+
+	// The limit applies to each record, not to the stream read so far
+	decoder, err = encoding.NewDecoder(strings.NewReader(strings.Repeat(`{"a": 1}`, 10)), encoding.WithMaxRecordSize(20))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatalf("Unexpected error for record %d: %v", i, err)
+		}
+	}
+
+	if _, err := encoding.NewDecoder(strings.NewReader(record), encoding.WithMaxRecordSize(0)); err == nil {
+		t.Error("Expected an error for a non-positive record size")
+	}
+}
+
+func TestDecoderMaxRecords(t *testing.T) {
+	stream := strings.Repeat(`{"id": 1} `, 10)
+
+	decoder, err := encoding.NewDecoder(strings.NewReader(stream), encoding.WithMaxRecords(3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := 0
+
+	for decoded <= 10 {
+		var result map[string]interface{}
+
+		err := decoder.Decode(&result)
+		if errors.Is(err, encoding.ErrMaxRecordsExceeded) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error after %d records: %v", decoded, err)
+		}
+
+		decoded++
+	}
+
+	if decoded != 3 {
+		t.Errorf("Expected the decoder to stop after 3 records, got %d", decoded)
+	}
+
+	var result map[string]interface{}
+	if err := decoder.Decode(&result); !errors.Is(err, encoding.ErrMaxRecordsExceeded) {
+		t.Errorf("Expected the limit error to repeat, got %v", err)
+	}
+
+	// A stream that ends before the limit reports its end, not the limit
+	decoder, _ = encoding.NewDecoder(strings.NewReader(`{"id": 1}`), encoding.WithMaxRecords(3))
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := decoder.Decode(&result); err == nil || errors.Is(err, encoding.ErrMaxRecordsExceeded) {
+		t.Errorf("Expected an end-of-input error, got %v", err)
+	}
+
+	if _, err := encoding.NewDecoder(strings.NewReader(stream), encoding.WithMaxRecords(0)); err == nil {
+		t.Error("Expected an error for a non-positive record count")
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CheckAcyclic reports whether the tree rooted at v is a proper tree, in which every node can be
+// reached along a single path. Trees built by hand may reuse a node pointer, in which case editing
+// the node through one path changes the other too, or may contain a container that holds itself,
+// directly or not, on which Marshal, Walk and every other traversal would never end. CheckAcyclic
+// returns an error naming the JSON Pointers of the first node found twice, or nil when there is
+// none. See MarshalOptions.CheckCycles for a guard against cycles only.
+func CheckAcyclic(v Value) error {
+	return checkTree(v, false)
+}
+
+// checkTree returns an error for the first cycle in the tree rooted at v and, unless allowShared
+// is set, for the first node reached along two paths.
+func checkTree(v Value, allowShared bool) error {
+	// open maps the containers being visited to their pointers, seen the nodes visited already
+	open := make(map[Value]string)
+	seen := make(map[Value]string)
+
+	var check func(pointer string, v Value) error
+
+	check = func(pointer string, v Value) error {
+		if first, ok := open[v]; ok {
+			return fmt.Errorf("cycle: the value at %q contains itself at %q", first, pointer)
+		}
+
+		if first, ok := seen[v]; ok {
+			if allowShared {
+				return nil
+			}
+
+			return fmt.Errorf("shared node: the value at %q is also at %q", first, pointer)
+		}
+
+		seen[v] = pointer
+
+		switch val := v.(type) {
+		case *Object:
+			open[v] = pointer
+
+			for _, k := range val.SortedKeys() {
+				if err := check(pointer+"/"+escapePointerToken(k), val.Pairs[k]); err != nil {
+					return err
+				}
+			}
+
+			delete(open, v)
+		case *Array:
+			open[v] = pointer
+
+			for i, elem := range val.Elements {
+				if err := check(pointer+"/"+strconv.Itoa(i), elem); err != nil {
+					return err
+				}
+			}
+
+			delete(open, v)
+		}
+
+		return nil
+	}
+
+	return check("", v)
+}
@@ -0,0 +1,88 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestCheckAcyclic(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func() parser.Value
+		expected string
+	}{
+		{
+			name:  "Parsed tree",
+			build: func() parser.Value { return mustParse(t, `{"a": [1, {"b": null}], "c": {}}`) },
+		},
+		{
+			name: "Self reference",
+			build: func() parser.Value {
+				root := mustParse(t, `{"a": {"b": 1}}`).(*parser.Object)
+				root.Pairs["a"].(*parser.Object).Pairs["loop"] = root
+
+				return root
+			},
+			expected: `cycle: the value at "" contains itself at "/a/loop"`,
+		},
+		{
+			name: "Array holding itself",
+			build: func() parser.Value {
+				root := mustParse(t, `{"list": [1]}`).(*parser.Object)
+				list := root.Pairs["list"].(*parser.Array)
+				list.Elements = append(list.Elements, list)
+
+				return root
+			},
+			expected: `cycle: the value at "/list" contains itself at "/list/1"`,
+		},
+		{
+			name: "Shared node",
+			build: func() parser.Value {
+				root := mustParse(t, `{"a": {"x": 1}}`).(*parser.Object)
+				root.Pairs["b"] = root.Pairs["a"]
+
+				return root
+			},
+			expected: `shared node: the value at "/a" is also at "/b"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parser.CheckAcyclic(tt.build())
+
+			switch {
+			case tt.expected == "" && err != nil:
+				t.Errorf("Unexpected error: %v", err)
+			case tt.expected != "" && (err == nil || err.Error() != tt.expected):
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestMarshalCheckCycles(t *testing.T) {
+	opts := parser.MarshalOptions{CheckCycles: true}
+
+	shared := mustParse(t, `{"a": [1]}`).(*parser.Object)
+	shared.Pairs["b"] = shared.Pairs["a"]
+
+	got, err := parser.MarshalWith(shared, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(got) != `{"a":[1],"b":[1]}` {
+		t.Errorf("Expected shared nodes to be written twice, got %s", got)
+	}
+
+	cyclic := mustParse(t, `{"a": [1]}`).(*parser.Object)
+	cyclic.Pairs["a"].(*parser.Array).Elements[0] = cyclic
+
+	if _, err := parser.MarshalWith(cyclic, opts); err == nil || !strings.HasPrefix(err.Error(), "cycle:") {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+}
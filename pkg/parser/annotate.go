@@ -0,0 +1,20 @@
+package parser
+
+// AnnotatePaths returns the tree rooted at v indented by two spaces, with every member and element
+// preceded by a line comment holding its JSON Pointer, such as // /servers/0/port, to show where
+// each value of a large document lives when a snippet is copied out of it. Strings are written
+// without the HTML escaping of MarshalIndent, so they read as in the source. The root value, whose
+// pointer is empty, is not annotated, and a pointer holding a line break is written quoted as a
+// Go string literal so that it stays on the line of its comment.
+//
+// The result is JSONC, not strict JSON: it can be read back with Parse and WithComments, while
+// JSON parsers without comment support reject it. It is empty if v cannot be marshaled, such as
+// when it holds a NaN number.
+func AnnotatePaths(v Value) string {
+	data, err := MarshalWith(v, MarshalOptions{Indent: "  ", ColonSpace: true, NoEscapeHTML: true, annotate: true})
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
@@ -0,0 +1,63 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestAnnotatePaths(t *testing.T) {
+	v := mustParse(t, `{"servers": [{"port": 8080, "tags": []}], "a/b": {"c~d": "<x>"}, "": null}`)
+
+	expected := `{
+  // /
+  "": null,
+  // /a~1b
+  "a/b": {
+    // /a~1b/c~0d
+    "c~d": "<x>"
+  },
+  // /servers
+  "servers": [
+    // /servers/0
+    {
+      // /servers/0/port
+      "port": 8080,
+      // /servers/0/tags
+      "tags": []
+    }
+  ]
+}`
+
+	got := parser.AnnotatePaths(v)
+	if got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	back, err := parser.Parse(got, parser.WithComments())
+	if err != nil || !parser.Equal(back, v) {
+		t.Errorf("Expected the output to parse as JSONC into the same tree, got %v", err)
+	}
+
+	if _, err := parser.Parse(got); err == nil {
+		t.Error("Expected the output to be rejected as strict JSON")
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Empty containers", `{"a": {}}`, "{\n  // /a\n  \"a\": {}\n}"},
+		{"Empty root", `[]`, "[]"},
+		{"Line break in a key", `{"a\nb": 1}`, "{\n  // \"/a\\nb\"\n  \"a\\nb\": 1\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.AnnotatePaths(mustParse(t, tt.input)); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package parser
+
+// arenaChunkSize is the number of nodes of each type in the first chunk of an Arena. Each new
+// chunk doubles the size of the previous one.
+const arenaChunkSize = 64
+
+// Arena allocates the nodes of parsed trees in bulk, for servers that parse one document per
+// request and drop the tree when the request ends. Nodes are taken from chunks that are kept
+// across parses, along with the maps of objects and the element slices of arrays, so that a
+// steady stream of similar documents stops allocating nodes altogether, and the garbage collector
+// tracks a few large chunks instead of every node.
+//
+// Reset makes every node allocated so far available again. Trees parsed with the arena must not
+// be used after Reset, nor any value taken from them, such as the result of ResolvePointer: their
+// nodes are reused by the next parse, which overwrites them in place. Copy what must outlive the
+// request, for example with Extract, before calling Reset. An Arena is not safe for concurrent
+// use, so each goroutine parsing concurrently needs its own.
+type Arena struct {
+	objects  slab[Object]
+	arrays   slab[Array]
+	strings  slab[StringLiteral]
+	numbers  slab[NumberLiteral]
+	booleans slab[Boolean]
+	nulls    slab[Null]
+}
+
+// NewArena returns an empty Arena.
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+// Reset releases every node allocated from the arena, invalidating every tree parsed with it.
+// The memory of the nodes is kept for the next parses.
+func (a *Arena) Reset() {
+	a.objects.reset(func(o *Object) {
+		pairs := o.Pairs
+		clear(pairs)
+		*o = Object{Pairs: pairs}
+	})
+	a.arrays.reset(func(arr *Array) {
+		clear(arr.Elements)
+		*arr = Array{Elements: arr.Elements[:0]}
+	})
+	a.strings.reset(func(s *StringLiteral) { *s = StringLiteral{} })
+	a.numbers.reset(func(n *NumberLiteral) { *n = NumberLiteral{} })
+	a.booleans.reset(func(b *Boolean) { *b = Boolean{} })
+	a.nulls.reset(func(n *Null) { *n = Null{} })
+}
+
+// object returns an empty object with the given opening token.
+func (a *Arena) object(token Token) *Object {
+	o := a.objects.alloc()
+	o.Token = token
+
+	if o.Pairs == nil {
+		o.Pairs = make(map[string]Value)
+	}
+
+	return o
+}
+
+// array returns an empty array with the given opening token.
+func (a *Arena) array(token Token) *Array {
+	arr := a.arrays.alloc()
+	arr.Token = token
+
+	if arr.Elements == nil {
+		arr.Elements = []Value{}
+	}
+
+	return arr
+}
+
+// slab hands out values of type T from chunks of growing size. Values are never moved, so
+// pointers to them remain valid until reset.
+type slab[T any] struct {
+	chunks [][]T
+	// chunk is the index of the chunk values are taken from, and used the number taken from it.
+	chunk, used int
+}
+
+// alloc returns the next free value, which holds whatever reset left in it.
+func (s *slab[T]) alloc() *T {
+	for s.chunk < len(s.chunks) && s.used == len(s.chunks[s.chunk]) {
+		s.chunk++
+		s.used = 0
+	}
+
+	if s.chunk == len(s.chunks) {
+		size := arenaChunkSize
+		if len(s.chunks) > 0 {
+			size = 2 * len(s.chunks[len(s.chunks)-1])
+		}
+
+		s.chunks = append(s.chunks, make([]T, size))
+	}
+
+	v := &s.chunks[s.chunk][s.used]
+	s.used++
+
+	return v
+}
+
+// reset passes every value handed out so far to release, and makes them available again.
+func (s *slab[T]) reset(release func(*T)) {
+	for i := 0; i <= s.chunk && i < len(s.chunks); i++ {
+		chunk := s.chunks[i]
+		if i == s.chunk {
+			chunk = chunk[:s.used]
+		}
+
+		for j := range chunk {
+			release(&chunk[j])
+		}
+	}
+
+	s.chunk, s.used = 0, 0
+}
@@ -0,0 +1,86 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestArena(t *testing.T) {
+	arena := parser.NewArena()
+
+	documents := []string{
+		`{"id": 1, "tags": ["a", "b"], "owner": {"name": "Ada", "admin": true}, "note": null}`,
+		`[{"x": 1.5}, {"x": -2}, [], {}]`,
+		recordsDataset(200),
+		`{"id": 2}`,
+	}
+
+	var first parser.Value
+
+	for i, input := range documents {
+		value, err := parser.Parse(input, parser.WithArena(arena))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !parser.Equal(value, mustParse(t, input)) {
+			t.Errorf("Document %d: expected %s, got %#v", i, input, value)
+		}
+
+		switch i {
+		case 0:
+			first = value
+		case len(documents) - 1:
+			// The nodes of the first document are reused by the last one
+			if value != first {
+				t.Error("Expected the root of the first document to be reused")
+			}
+		}
+
+		arena.Reset()
+	}
+
+	t.Run("Array hint", func(t *testing.T) {
+		p := parser.NewParser(parser.NewLexer(`[1, 2]`), parser.WithArena(arena), parser.WithArrayHint(128))
+
+		value, err := p.ParseJSON()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if elements := value.(*parser.Array).Elements; len(elements) != 2 || cap(elements) < 128 {
+			t.Errorf("Expected the hint to apply, got len=%d cap=%d", len(elements), cap(elements))
+		}
+
+		arena.Reset()
+	})
+}
+
+func BenchmarkArena(b *testing.B) {
+	input := recordsDataset(100)
+
+	b.Run("Heap", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.Parse(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Arena", func(b *testing.B) {
+		arena := parser.NewArena()
+
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.Parse(input, parser.WithArena(arena)); err != nil {
+				b.Fatal(err)
+			}
+
+			arena.Reset()
+		}
+	})
+}
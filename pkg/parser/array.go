@@ -0,0 +1,336 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Map returns a new array holding the result of calling fn on each element of a, in order. A nil
+// result is stored as null. a itself is left untouched. Map on a nil array returns an empty
+// array.
+func (a *Array) Map(fn func(i int, v Value) Value) *Array {
+	result := newArray(a)
+	if a == nil {
+		return result
+	}
+
+	result.Elements = make([]Value, len(a.Elements))
+
+	for i, elem := range a.Elements {
+		v := fn(i, elem)
+		if v == nil {
+			v = NewNull()
+		}
+
+		result.Elements[i] = v
+	}
+
+	return result
+}
+
+// Filter returns a new array holding only the elements of a for which keep returns true, in
+// their original order. The elements are shared with a rather than copied, and a itself is left
+// untouched. Filter on a nil array returns an empty array.
+func (a *Array) Filter(keep func(i int, v Value) bool) *Array {
+	result := newArray(a)
+	if a == nil {
+		return result
+	}
+
+	for i, elem := range a.Elements {
+		if keep(i, elem) {
+			result.Elements = append(result.Elements, elem)
+		}
+	}
+
+	return result
+}
+
+// Chunk splits the elements of a into consecutive arrays of at most size elements each, in
+// order, for sending them in batches to APIs that limit the size of a request. Every chunk holds
+// exactly size elements except the last, which holds the rest. A size of zero or less puts all
+// the elements into a single chunk. Each chunk has its own slice of elements, but the elements
+// themselves are shared with a rather than copied, and a itself is left untouched. Chunk returns
+// no chunks for an empty or nil array.
+func (a *Array) Chunk(size int) []*Array {
+	if a == nil || len(a.Elements) == 0 {
+		return nil
+	}
+
+	if size <= 0 {
+		size = len(a.Elements)
+	}
+
+	chunks := make([]*Array, 0, (len(a.Elements)+size-1)/size)
+
+	for start := 0; start < len(a.Elements); start += size {
+		chunk := newArray(a)
+		chunk.Elements = append(chunk.Elements, a.Elements[start:min(start+size, len(a.Elements))]...)
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// SortBy returns a new array holding the elements of a sorted by less, which reports whether its
+// first argument sorts before its second, such as by comparing the "id" member of objects. The
+// sort is stable: elements that neither sorts before the other keep their original order, so
+// sorting the same array twice gives the same result, and sorting by several keys can be done by
+// sorting by the least significant one first. The elements are shared with a rather than copied,
+// and a itself is left untouched. SortBy on a nil array returns an empty array.
+func (a *Array) SortBy(less func(a, b Value) bool) *Array {
+	result := newArray(a)
+	if a == nil {
+		return result
+	}
+
+	result.Elements = append(result.Elements, a.Elements...)
+
+	sort.SliceStable(result.Elements, func(i, j int) bool {
+		return less(result.Elements[i], result.Elements[j])
+	})
+
+	return result
+}
+
+// Dedup returns a new array holding the elements of a without duplicates, keeping the first
+// occurrence of each value in its original position, such as to clean up a list of IDs or
+// records. Elements are duplicates when they are Equal, so nested objects and arrays are compared
+// by value, whatever their key order or number form. Elements are grouped by their Fingerprint
+// and only compared with Equal to the kept elements sharing it, so deduplication takes O(n) time
+// rather than the O(n²) of comparing every pair. The elements are shared with a rather than
+// copied, and a itself is left untouched. Dedup on a nil array returns an empty array.
+func (a *Array) Dedup() *Array {
+	result := newArray(a)
+	if a == nil {
+		return result
+	}
+
+	kept := make(map[string][]Value, len(a.Elements))
+
+elements:
+	for _, elem := range a.Elements {
+		fingerprint := Fingerprint(elem)
+
+		for _, other := range kept[fingerprint] {
+			if Equal(elem, other) {
+				continue elements
+			}
+		}
+
+		kept[fingerprint] = append(kept[fingerprint], elem)
+		result.Elements = append(result.Elements, elem)
+	}
+
+	return result
+}
+
+// FindIndices returns the indices of the elements of a for which pred returns true, in order, for
+// locating elements to replace or remove later, which Filter cannot do as it drops positions. It
+// returns an empty slice when no element matches or a is nil.
+func (a *Array) FindIndices(pred func(v Value) bool) []int {
+	indices := []int{}
+	if a == nil {
+		return indices
+	}
+
+	for i, elem := range a.Elements {
+		if pred(elem) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// Find returns the index and value of the first element of a for which pred returns true, and
+// true, or -1, nil and false when no element matches or a is nil.
+func (a *Array) Find(pred func(v Value) bool) (int, Value, bool) {
+	if a == nil {
+		return -1, nil, false
+	}
+
+	for i, elem := range a.Elements {
+		if pred(elem) {
+			return i, elem, true
+		}
+	}
+
+	return -1, nil, false
+}
+
+// GroupBy partitions the elements of a by the key keyFn returns for each of them, such as the
+// "category" member of a record, into an object mapping every key to an array of the elements
+// that returned it, in their original order. Only the key decides the group: elements of
+// different types for which keyFn returns the same key, such as the number 1 and the string "1"
+// mapped through their String form, share a group. The elements are shared with a rather than
+// copied, and a itself is left untouched. GroupBy on a nil array returns an empty object.
+func (a *Array) GroupBy(keyFn func(v Value) string) *Object {
+	result := newObject()
+	if a == nil {
+		return result
+	}
+
+	for _, elem := range a.Elements {
+		key := keyFn(elem)
+
+		group, ok := result.Pairs[key].(*Array)
+		if !ok {
+			group = newArray(nil)
+			result.Pairs[key] = group
+		}
+
+		group.Elements = append(group.Elements, elem)
+	}
+
+	return result
+}
+
+// Strings returns the elements of a as a []string when every element is a string, such as a list
+// of tags, or nil and false when any is not. An empty or nil array gives an empty slice and true.
+func (a *Array) Strings() ([]string, bool) {
+	return typedSlice(a, func(v Value) (string, bool) {
+		s, ok := v.(*StringLiteral)
+		if !ok {
+			return "", false
+		}
+
+		return s.Value, true
+	})
+}
+
+// Ints returns the elements of a as a []int64 when every element is a number that fits in an
+// int64, as for GetIntOr, or nil and false when any is not. Numbers with a fraction or exponent
+// such as 1.5 or 1e3 are not integers. An empty or nil array gives an empty slice and true.
+func (a *Array) Ints() ([]int64, bool) {
+	return typedSlice(a, func(v Value) (int64, bool) {
+		n, ok := v.(*NumberLiteral)
+		if !ok || !n.IsValidNumber() || !n.IsInt {
+			return 0, false
+		}
+
+		return n.Int, true
+	})
+}
+
+// Floats returns the elements of a as a []float64 when every element is a number, integers
+// included, or nil and false when any is not. An empty or nil array gives an empty slice and true.
+func (a *Array) Floats() ([]float64, bool) {
+	return typedSlice(a, func(v Value) (float64, bool) {
+		n, ok := v.(*NumberLiteral)
+		if !ok || !n.IsValidNumber() {
+			return 0, false
+		}
+
+		return n.Float, true
+	})
+}
+
+// Bools returns the elements of a as a []bool when every element is a boolean, or nil and false
+// when any is not. An empty or nil array gives an empty slice and true.
+func (a *Array) Bools() ([]bool, bool) {
+	return typedSlice(a, func(v Value) (bool, bool) {
+		b, ok := v.(*Boolean)
+		if !ok {
+			return false, false
+		}
+
+		return b.Value, true
+	})
+}
+
+// typedSlice converts every element of a with convert, returning nil and false as soon as one
+// cannot be converted.
+func typedSlice[T any](a *Array, convert func(Value) (T, bool)) ([]T, bool) {
+	if a == nil {
+		return []T{}, true
+	}
+
+	result := make([]T, 0, len(a.Elements))
+
+	for _, elem := range a.Elements {
+		v, ok := convert(elem)
+		if !ok {
+			return nil, false
+		}
+
+		result = append(result, v)
+	}
+
+	return result, true
+}
+
+// WrapInArray returns a new array holding v as its only element, [v], for handing a single value
+// to an API that expects a collection. A nil v is stored as null, giving [null]. v is shared
+// rather than copied.
+func WrapInArray(v Value) *Array {
+	if v == nil {
+		v = NewNull()
+	}
+
+	result := newArray(nil)
+	result.Elements = append(result.Elements, v)
+
+	return result
+}
+
+// ZipArrays returns a new array pairing the elements of a and b by index, as two-element arrays
+// [a[i], b[i]], for combining the parallel columns some APIs return, so ["x", "y"] and [1, 2]
+// give [["x", 1], ["y", 2]]. The arrays must have the same length: when they do not, ZipArrays
+// returns an error naming both lengths rather than dropping or padding elements. Callers that
+// want to zip up to the shorter one can slice the Elements of the longer one first. The elements
+// are shared with a and b rather than copied, and a nil array counts as empty.
+func ZipArrays(a, b *Array) (*Array, error) {
+	return zip(a, b, func(x, y Value) Value {
+		pair := newArray(nil)
+		pair.Elements = append(pair.Elements, x, y)
+
+		return pair
+	})
+}
+
+// ZipToObjects is like ZipArrays, but pairs the elements of a and b as objects holding a[i] under
+// keyA and b[i] under keyB, so ids [1, 2] and names ["x", "y"] zipped with "id" and "name" give
+// [{"id": 1, "name": "x"}, {"id": 2, "name": "y"}]. When keyA and keyB are equal, each object
+// only holds the element of b.
+func ZipToObjects(a, b *Array, keyA, keyB string) (*Array, error) {
+	return zip(a, b, func(x, y Value) Value {
+		return newObject().Set(keyA, x).Set(keyB, y)
+	})
+}
+
+// zip returns a new array holding pair called on the elements of a and b at each index, for
+// ZipArrays and ZipToObjects.
+func zip(a, b *Array, pair func(x, y Value) Value) (*Array, error) {
+	var x, y []Value
+	if a != nil {
+		x = a.Elements
+	}
+
+	if b != nil {
+		y = b.Elements
+	}
+
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("cannot zip arrays of different lengths %d and %d", len(x), len(y))
+	}
+
+	result := newArray(nil)
+	result.Elements = make([]Value, len(x))
+
+	for i := range x {
+		result.Elements[i] = pair(x[i], y[i])
+	}
+
+	return result, nil
+}
+
+// newArray returns an empty array carrying the opening token of from, or a synthesized one when
+// from is nil.
+func newArray(from *Array) *Array {
+	if from == nil {
+		return &Array{Token: Token{Type: TokenBracketOpen, Literal: "["}, Elements: []Value{}}
+	}
+
+	return &Array{Token: from.Token, Elements: []Value{}}
+}
@@ -0,0 +1,355 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestArrayMap(t *testing.T) {
+	arr := mustParse(t, `[{"id": 1}, {"id": 2}, {"name": "x"}]`).(*parser.Array)
+
+	ids := arr.Map(func(_ int, v parser.Value) parser.Value {
+		id, _ := v.(*parser.Object).Get("id")
+
+		return id
+	})
+
+	got, err := parser.Marshal(ids)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if string(got) != "[1,2,null]" {
+		t.Errorf("Expected [1,2,null], got %s", got)
+	}
+
+	if _, ok := arr.Elements[0].(*parser.Object); !ok || len(arr.Elements) != 3 {
+		t.Error("Expected the original array to be untouched")
+	}
+
+	var nilArr *parser.Array
+	if got := nilArr.Map(func(_ int, v parser.Value) parser.Value { return v }); got == nil || len(got.Elements) != 0 {
+		t.Errorf("Expected an empty array for nil, got %#v", got)
+	}
+}
+
+func TestArrayFilter(t *testing.T) {
+	arr := mustParse(t, `[1, "a", 2, null, 3]`).(*parser.Array)
+
+	var indexes []int
+
+	numbers := arr.Filter(func(i int, v parser.Value) bool {
+		indexes = append(indexes, i)
+		_, ok := v.(*parser.NumberLiteral)
+
+		return ok
+	})
+
+	got, err := parser.Marshal(numbers)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if string(got) != "[1,2,3]" {
+		t.Errorf("Expected [1,2,3], got %s", got)
+	}
+
+	if !reflect.DeepEqual(indexes, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("Expected keep to be called in order, got %v", indexes)
+	}
+
+	if numbers.Elements[0] != arr.Elements[0] || len(arr.Elements) != 5 {
+		t.Error("Expected elements to be shared and the original left untouched")
+	}
+
+	if empty := arr.Filter(func(int, parser.Value) bool { return false }); len(empty.Elements) != 0 {
+		t.Errorf("Expected no elements, got %d", len(empty.Elements))
+	}
+}
+
+func TestArrayChunk(t *testing.T) {
+	arr := mustParse(t, `[1, 2, 3, 4, 5]`).(*parser.Array)
+
+	tests := []struct {
+		name     string
+		size     int
+		expected []string
+	}{
+		{"Last chunk shorter", 2, []string{"[1,2]", "[3,4]", "[5]"}},
+		{"Exact", 5, []string{"[1,2,3,4,5]"}},
+		{"Larger than the array", 10, []string{"[1,2,3,4,5]"}},
+		{"Single elements", 1, []string{"[1]", "[2]", "[3]", "[4]", "[5]"}},
+		{"Zero size", 0, []string{"[1,2,3,4,5]"}},
+		{"Negative size", -1, []string{"[1,2,3,4,5]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+
+			for _, chunk := range arr.Chunk(tt.size) {
+				data, err := parser.Marshal(chunk)
+				if err != nil {
+					t.Fatalf("Marshal failed: %v", err)
+				}
+
+				got = append(got, string(data))
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	chunks := arr.Chunk(2)
+	if chunks[0].Elements[0] != arr.Elements[0] {
+		t.Error("Expected elements to be shared")
+	}
+
+	chunks[0].Elements = append(chunks[0].Elements, parser.NewNull())
+	if chunks[1].Elements[0] != arr.Elements[2] || len(arr.Elements) != 5 {
+		t.Error("Expected appending to a chunk to leave the others and the original untouched")
+	}
+
+	if got := (&parser.Array{}).Chunk(3); len(got) != 0 {
+		t.Errorf("Expected no chunks for an empty array, got %d", len(got))
+	}
+}
+
+func TestArraySortBy(t *testing.T) {
+	arr := mustParse(t, `[{"id": 3, "n": "a"}, {"id": 1, "n": "b"}, {"id": 3, "n": "c"}, {"id": 2, "n": "d"}, {"id": 1, "n": "e"}]`).(*parser.Array)
+	before, _ := parser.Marshal(arr)
+
+	byID := func(a, b parser.Value) bool {
+		return parser.GetIntOr(a, "id", 0) < parser.GetIntOr(b, "id", 0)
+	}
+
+	sorted := arr.SortBy(byID)
+
+	// Elements with the same id keep their original order
+	data, _ := parser.Marshal(sorted)
+	expected := `[{"id":1,"n":"b"},{"id":1,"n":"e"},{"id":2,"n":"d"},{"id":3,"n":"a"},{"id":3,"n":"c"}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	if after, _ := parser.Marshal(arr); string(after) != string(before) {
+		t.Errorf("Expected the original to be left untouched, got %s", after)
+	}
+
+	if sorted.Elements[0] != arr.Elements[1] {
+		t.Error("Expected elements to be shared")
+	}
+
+	if got := (*parser.Array)(nil).SortBy(byID); got == nil || len(got.Elements) != 0 {
+		t.Errorf("Expected an empty array for a nil array, got %v", got)
+	}
+}
+
+func TestArrayDedup(t *testing.T) {
+	arr := mustParse(t, `[1, "1", 1.0, {"a": 1, "b": [2]}, null, {"b": [2], "a": 1}, [1, 2], [2, 1], null,
+		9007199254740993, 9007199254740992, 9007199254740993]`).(*parser.Array)
+	before, _ := parser.Marshal(arr)
+
+	deduped := arr.Dedup()
+
+	data, _ := parser.Marshal(deduped)
+	expected := `[1,"1",{"a":1,"b":[2]},null,[1,2],[2,1],9007199254740993,9007199254740992]`
+
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	if deduped.Elements[2] != arr.Elements[3] {
+		t.Error("Expected the first occurrence to be kept and shared")
+	}
+
+	if after, _ := parser.Marshal(arr); string(after) != string(before) {
+		t.Errorf("Expected the original to be left untouched, got %s", after)
+	}
+
+	var nilArray *parser.Array
+	if got := nilArray.Dedup(); got == nil || len(got.Elements) != 0 {
+		t.Errorf("Expected an empty array, got %v", got)
+	}
+}
+
+func TestArrayFindIndices(t *testing.T) {
+	arr := mustParse(t, `[1, "a", 2, null, 3]`).(*parser.Array)
+
+	isNumber := func(v parser.Value) bool {
+		_, ok := v.(*parser.NumberLiteral)
+
+		return ok
+	}
+
+	if got := arr.FindIndices(isNumber); !reflect.DeepEqual(got, []int{0, 2, 4}) {
+		t.Errorf("Expected [0 2 4], got %v", got)
+	}
+
+	none := func(parser.Value) bool { return false }
+	if got := arr.FindIndices(none); got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty slice for no matches, got %#v", got)
+	}
+
+	if got := (*parser.Array)(nil).FindIndices(isNumber); got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty slice for a nil array, got %#v", got)
+	}
+}
+
+func TestArrayFind(t *testing.T) {
+	arr := mustParse(t, `[null, "a", 2, "b"]`).(*parser.Array)
+
+	isString := func(v parser.Value) bool {
+		_, ok := v.(*parser.StringLiteral)
+
+		return ok
+	}
+
+	if i, v, ok := arr.Find(isString); !ok || i != 1 || v != arr.Elements[1] {
+		t.Errorf("Expected the first string at index 1, got %d, %v, %v", i, v, ok)
+	}
+
+	if i, v, ok := arr.Find(func(parser.Value) bool { return false }); ok || i != -1 || v != nil {
+		t.Errorf("Expected no match, got %d, %v, %v", i, v, ok)
+	}
+
+	if i, v, ok := (*parser.Array)(nil).Find(isString); ok || i != -1 || v != nil {
+		t.Errorf("Expected no match for a nil array, got %d, %v, %v", i, v, ok)
+	}
+}
+
+func TestArrayGroupBy(t *testing.T) {
+	arr := mustParse(t, `[{"c": "a", "n": 1}, {"c": "b", "n": 2}, {"c": "a", "n": 3}, {"n": 4}, {"c": 1}, {"c": "1"}]`).(*parser.Array)
+
+	byCategory := func(v parser.Value) string {
+		c, _ := v.(*parser.Object).Get("c")
+		if c == nil {
+			return ""
+		}
+
+		return c.String()
+	}
+
+	grouped := arr.GroupBy(byCategory)
+
+	data, _ := parser.Marshal(grouped)
+	expected := `{"":[{"n":4}],"1":[{"c":1},{"c":"1"}],"a":[{"c":"a","n":1},{"c":"a","n":3}],"b":[{"c":"b","n":2}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	if group, _ := grouped.Get("b"); group.(*parser.Array).Elements[0] != arr.Elements[1] {
+		t.Error("Expected elements to be shared")
+	}
+
+	if got := (*parser.Array)(nil).GroupBy(byCategory); got == nil || len(got.Pairs) != 0 {
+		t.Errorf("Expected an empty object for a nil array, got %v", got)
+	}
+}
+
+func TestArrayTypedSlices(t *testing.T) {
+	tags := mustParse(t, `["go", "json", ""]`).(*parser.Array)
+	ints := mustParse(t, `[1, -2, 0]`).(*parser.Array)
+	floats := mustParse(t, `[1.5, 2, -1e3]`).(*parser.Array)
+	bools := mustParse(t, `[true, false]`).(*parser.Array)
+	mixed := mustParse(t, `[1, "1", null]`).(*parser.Array)
+	empty := mustParse(t, `[]`).(*parser.Array)
+
+	if got, ok := tags.Strings(); !ok || !reflect.DeepEqual(got, []string{"go", "json", ""}) {
+		t.Errorf("Strings: unexpected %v, %v", got, ok)
+	}
+
+	if got, ok := ints.Ints(); !ok || !reflect.DeepEqual(got, []int64{1, -2, 0}) {
+		t.Errorf("Ints: unexpected %v, %v", got, ok)
+	}
+
+	if got, ok := floats.Floats(); !ok || !reflect.DeepEqual(got, []float64{1.5, 2, -1000}) {
+		t.Errorf("Floats: unexpected %v, %v", got, ok)
+	}
+
+	if got, ok := bools.Bools(); !ok || !reflect.DeepEqual(got, []bool{true, false}) {
+		t.Errorf("Bools: unexpected %v, %v", got, ok)
+	}
+
+	mismatches := []struct {
+		name    string
+		convert func() (any, bool)
+	}{
+		{"Strings of numbers", func() (any, bool) { return ints.Strings() }},
+		{"Ints of fractions", func() (any, bool) { return floats.Ints() }},
+		{"Floats of strings", func() (any, bool) { return tags.Floats() }},
+		{"Bools of mixed", func() (any, bool) { return mixed.Bools() }},
+		{"Ints of mixed", func() (any, bool) { return mixed.Ints() }},
+	}
+
+	for _, tt := range mismatches {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := tt.convert(); ok || !reflect.ValueOf(got).IsNil() {
+				t.Errorf("Expected nil and false, got %v, %v", got, ok)
+			}
+		})
+	}
+
+	for _, arr := range []*parser.Array{empty, nil} {
+		if got, ok := arr.Strings(); !ok || got == nil || len(got) != 0 {
+			t.Errorf("Expected an empty slice and true for %v, got %#v, %v", arr, got, ok)
+		}
+	}
+}
+
+func TestWrapInArray(t *testing.T) {
+	value := mustParse(t, `{"a": 1}`)
+
+	arr := parser.WrapInArray(value)
+	if len(arr.Elements) != 1 || arr.Elements[0] != value {
+		t.Errorf("Expected the value as the only element, got %v", arr)
+	}
+
+	if data, _ := parser.Marshal(parser.WrapInArray(nil)); string(data) != `[null]` {
+		t.Errorf("Expected [null] for nil, got %s", data)
+	}
+}
+
+func TestZipArrays(t *testing.T) {
+	ids := mustParse(t, `[1, 2, 3]`).(*parser.Array)
+	names := mustParse(t, `["a", {"n": "b"}, null]`).(*parser.Array)
+
+	zipped, err := parser.ZipArrays(ids, names)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if data, _ := parser.Marshal(zipped); string(data) != `[[1,"a"],[2,{"n":"b"}],[3,null]]` {
+		t.Errorf("Unexpected pairs %s", data)
+	}
+
+	if zipped.Elements[1].(*parser.Array).Elements[1] != names.Elements[1] {
+		t.Error("Expected the elements to be shared")
+	}
+
+	objects, err := parser.ZipToObjects(ids, names, "id", "name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if data, _ := parser.Marshal(objects); string(data) != `[{"id":1,"name":"a"},{"id":2,"name":{"n":"b"}},{"id":3,"name":null}]` {
+		t.Errorf("Unexpected objects %s", data)
+	}
+
+	_, err = parser.ZipArrays(ids, mustParse(t, `[1]`).(*parser.Array))
+	if err == nil || err.Error() != "cannot zip arrays of different lengths 3 and 1" {
+		t.Errorf("Expected a length mismatch error, got %v", err)
+	}
+
+	if _, err := parser.ZipToObjects(nil, ids, "a", "b"); err == nil {
+		t.Error("Expected a nil array to count as empty")
+	}
+
+	if empty, err := parser.ZipArrays(nil, &parser.Array{}); err != nil || len(empty.Elements) != 0 {
+		t.Errorf("Expected an empty array, got %v, %v", empty, err)
+	}
+}
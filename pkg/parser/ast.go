@@ -1,13 +1,33 @@
 package parser
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
+// nodeInfo holds metadata that the parser attaches to AST nodes. It is embedded in every node type
+// and takes no part in serialization.
+type nodeInfo struct {
+	// leadingComments are the comments found before the node.
+	leadingComments []string
+	// trailingComments are the comments found before the closing token of a container, or after
+	// the root value.
+	trailingComments []string
+	// meta is the user data attached with SetMeta.
+	meta any
+	// source is the source text of the node, recorded under PreserveFormatting.
+	source *source
+}
+
+// info returns the metadata of the node.
+func (n *nodeInfo) info() *nodeInfo { return n }
+
 // Object represents a JSON object - a collection of key-value pairs.
 type Object struct {
+	nodeInfo
 	// Token is the opening '{' token
 	Token Token
 	// Pairs are the key-value pairs in the object.
@@ -17,23 +37,26 @@ type Object struct {
 // TokenLiteral returns the literal value of the token that defines the object.
 func (o *Object) TokenLiteral() string { return o.Token.Literal }
 
-// String returns a simplified string representation of the object.
+// String returns the object as compact JSON, with its keys sorted and escaped like Marshal does.
+// An object that cannot be marshaled, such as one holding a NaN, is written with the String of
+// each of its members instead.
 func (o *Object) String() string {
+	if data, err := MarshalWith(o, MarshalOptions{NoEscapeHTML: true}); err == nil {
+		return string(data)
+	}
+
 	var b strings.Builder
 
 	b.WriteString("{")
 
-	i := 0
-	for k, v := range o.Pairs {
+	for i, k := range o.SortedKeys() {
 		if i > 0 {
 			b.WriteString(", ")
 		}
 
-		b.WriteString(k)
+		b.WriteString(strconv.Quote(k))
 		b.WriteString(": ")
-		b.WriteString(v.String())
-
-		i++
+		b.WriteString(o.Pairs[k].String())
 	}
 
 	b.WriteString("}")
@@ -41,11 +64,15 @@ func (o *Object) String() string {
 	return b.String()
 }
 
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (o *Object) GoString() string { return goString(o) }
+
 // valueNode is a placeholder method to ensure type safety within the Value interface.
 func (o *Object) valueNode() {}
 
 // Array represents a JSON array - an ordered list of values.
 type Array struct {
+	nodeInfo
 	// Token is the opening '[' token.
 	Token Token
 	// Elements are the values in the array.
@@ -55,14 +82,39 @@ type Array struct {
 // TokenLiteral returns the literal value of the token that defines the array.
 func (a *Array) TokenLiteral() string { return a.Token.Literal }
 
-// String returns a simplified string representation of the array.
-func (a *Array) String() string { return "[]" } // Simplified for now
+// String returns the array as compact JSON, escaped like Marshal does. An array that cannot be
+// marshaled, such as one holding a NaN, is written with the String of each of its elements instead.
+func (a *Array) String() string {
+	if data, err := MarshalWith(a, MarshalOptions{NoEscapeHTML: true}); err == nil {
+		return string(data)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("[")
+
+	for i, elem := range a.Elements {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(elem.String())
+	}
+
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (a *Array) GoString() string { return goString(a) }
 
 // valueNode is a placeholder method to ensure type safety within the Value interface.
 func (a *Array) valueNode() {}
 
 // StringLiteral represents a JSON string value.
 type StringLiteral struct {
+	nodeInfo
 	// Token is the string token.
 	Token Token
 	// Value is the actual string value.
@@ -75,11 +127,15 @@ func (s *StringLiteral) TokenLiteral() string { return s.Token.Literal }
 // String returns the actual string value.
 func (s *StringLiteral) String() string { return s.Value }
 
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (s *StringLiteral) GoString() string { return goString(s) }
+
 // valueNode is a placeholder method to ensure type safety within the Value interface.
 func (s *StringLiteral) valueNode() {}
 
 // NumberLiteral represents a JSON number value.
 type NumberLiteral struct {
+	nodeInfo
 	// Token is the number token.
 	Token Token
 	// Value is the number as a string (we'll parse it when needed).
@@ -92,14 +148,20 @@ type NumberLiteral struct {
 	IsInt bool
 	// IsValid is a flag to indicate if the number is valid JSON number.
 	IsValid bool
+	// Overflowed is set when the literal is an integer outside the range of int64. Such numbers
+	// are stored in Float only, with IsInt false, and may have lost precision.
+	Overflowed bool
 }
 
 // NewNumberLiteral creates a new NumberLiteral with proper validation and parsing
 func NewNumberLiteral(token Token) *NumberLiteral {
-	n := &NumberLiteral{
-		Token: token,
-		Value: token.Literal,
-	}
+	return initNumberLiteral(&NumberLiteral{}, token)
+}
+
+// initNumberLiteral sets up the zero NumberLiteral n like NewNumberLiteral and returns it.
+func initNumberLiteral(n *NumberLiteral, token Token) *NumberLiteral {
+	n.Token = token
+	n.Value = token.Literal
 
 	isInt := true // Assume it's an integer initially
 
@@ -131,12 +193,24 @@ func NewNumberLiteral(token Token) *NumberLiteral {
 
 	if isInt {
 		i, err := strconv.ParseInt(token.Literal, 10, 64)
-		if err != nil {
+
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			// Keep the closest float64 rather than rejecting the number
+			f, err := strconv.ParseFloat(token.Literal, 64)
+			if err != nil {
+				return setInvalidNumberLiteral(n)
+			}
+
+			n.Float = f
+			n.Overflowed = true
+			isInt = false
+		case err != nil:
 			return setInvalidNumberLiteral(n)
+		default:
+			n.Int = i
+			n.Float = float64(i)
 		}
-
-		n.Int = i
-		n.Float = float64(i)
 	} else {
 		f, err := strconv.ParseFloat(token.Literal, 64)
 		if err != nil {
@@ -155,6 +229,7 @@ func NewNumberLiteral(token Token) *NumberLiteral {
 func setInvalidNumberLiteral(n *NumberLiteral) *NumberLiteral {
 	n.IsValid = false
 	n.IsInt = false
+	n.Overflowed = false
 	n.Int = 0
 	n.Float = 0
 
@@ -173,6 +248,9 @@ func (n *NumberLiteral) String() string {
 	return fmt.Sprintf("%f", n.Float)
 }
 
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (n *NumberLiteral) GoString() string { return goString(n) }
+
 // valueNode is a placeholder method to ensure type safety within the Value interface.
 func (n *NumberLiteral) valueNode() {}
 
@@ -181,8 +259,16 @@ func (n *NumberLiteral) IsValidNumber() bool {
 	return n.IsValid
 }
 
+// JSONNumber returns the original literal of the number as a json.Number, such as 1.50 or 1e3
+// written exactly as in the source, so that it can be handed to code using encoding/json with
+// its precision intact.
+func (n *NumberLiteral) JSONNumber() json.Number {
+	return json.Number(n.Value)
+}
+
 // Boolean represents a JSON boolean value (true or false).
 type Boolean struct {
+	nodeInfo
 	// Token is the boolean token.
 	Token Token
 	// Value is the actual boolean value.
@@ -195,20 +281,87 @@ func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
 // String returns the boolean value as a string.
 func (b *Boolean) String() string { return b.Token.Literal }
 
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (b *Boolean) GoString() string { return goString(b) }
+
 // valueNode is a placeholder method to ensure type safety within the Value interface.
 func (b *Boolean) valueNode() {}
 
 // Null represents a JSON null value.
 type Null struct {
+	nodeInfo
 	// Token is the null token.
 	Token Token
 }
 
+// NewNull creates a new Null value.
+func NewNull() *Null {
+	return &Null{Token: Token{Type: TokenNull, Literal: "null"}}
+}
+
 // TokenLiteral returns the literal value of the token that defines the null value.
 func (n *Null) TokenLiteral() string { return n.Token.Literal }
 
 // String returns the string representation of the null value.
 func (n *Null) String() string { return "null" }
 
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (n *Null) GoString() string { return goString(n) }
+
 // valueNode is a placeholder method to ensure type safety within the Value interface.
 func (n *Null) valueNode() {}
+
+// RawMessage is a value held as its serialized JSON text, so that JSON produced elsewhere can be
+// spliced into a tree without being parsed, and subtrees that a proxy passes through, designated
+// with ParserOptions.RawPaths or deferred with LazyKeys, are never built. Bytes is only checked
+// to hold exactly one JSON value when the RawMessage is marshaled: it is then parsed with the
+// options of the parser that made the RawMessage, or with DefaultParserOptions for one built in
+// code, and written like the value it holds, so that the options of Marshal, such as Indent or
+// ASCIIOnly, apply to it as well and comments accepted by the parser are dropped. Other functions
+// of this package treat a RawMessage as opaque: they do not look inside it, and Equal compares it
+// by its bytes.
+type RawMessage struct {
+	nodeInfo
+	// Token is the first token of the value, for a RawMessage made by the parser.
+	Token Token
+	// Bytes is the JSON text of the value.
+	Bytes []byte
+	// options holds the options Bytes was accepted with, for a RawMessage made by the parser.
+	options *ParserOptions
+	// deferred holds the options to parse Bytes with, for a member deferred by LazyKeys.
+	deferred *ParserOptions
+}
+
+// TokenLiteral returns the literal value of the first token of the raw value.
+func (r *RawMessage) TokenLiteral() string { return r.Token.Literal }
+
+// String returns the JSON text of the raw value.
+func (r *RawMessage) String() string { return string(r.Bytes) }
+
+// parse parses Bytes, which must hold exactly one JSON value, with the options it was accepted
+// with, or DefaultParserOptions.
+func (r *RawMessage) parse() (Value, error) {
+	opts := DefaultParserOptions()
+	if r.options != nil {
+		opts = *r.options
+	}
+
+	return parseDeferred(r.Bytes, opts)
+}
+
+// GoString returns the value as compact JSON, so that %#v formats it readably.
+func (r *RawMessage) GoString() string { return goString(r) }
+
+// valueNode is a placeholder method to ensure type safety within the Value interface.
+func (r *RawMessage) valueNode() {}
+
+// goString renders v as compact JSON for GoString, without the HTML escaping of Marshal, falling
+// back to its String method when v cannot be marshaled, such as a number with an invalid literal.
+func goString(v Value) string {
+	b, err := MarshalWith(v, MarshalOptions{NoEscapeHTML: true})
+	if err != nil {
+		return v.String()
+	}
+
+	return string(b)
+}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrMemoryBudget is wrapped by the ParseError returned when a parse would take the parses in
+// progress over the budget set by SetMemoryBudget.
+var ErrMemoryBudget = errors.New("memory budget exceeded")
+
+// BudgetMode tells what a parse does when the memory budget set by SetMemoryBudget is not enough
+// for it. See SetMemoryBudgetMode.
+type BudgetMode int
+
+const (
+	// BudgetReject fails the parse at once with a ParseError wrapping ErrMemoryBudget. This is
+	// the default, suited to a server that would rather answer a request with an error than
+	// let it queue.
+	BudgetReject BudgetMode = iota
+	// BudgetBlock makes the parse wait until the parses in progress have released enough of the
+	// budget. Waiting is not bounded by Timeout, whose clock only starts once the parse does.
+	BudgetBlock
+)
+
+// budgetChunk is the number of bytes a parse of input of unknown size reserves at a time, so that
+// the budget is not locked for every token.
+const budgetChunk = 64 << 10
+
+// memoryBudget is the budget shared by all the parses of the process.
+var memoryBudget = struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// limit is the budget in bytes, or zero when there is none. It is only stored under mu, but
+	// loaded without it to check whether there is a budget at all.
+	limit atomic.Int64
+	// used is the number of bytes reserved by the parses in progress.
+	used int64
+	// mode is what a parse does when the budget is not enough for it.
+	mode BudgetMode
+}{}
+
+func init() {
+	memoryBudget.cond = sync.NewCond(&memoryBudget.mu)
+}
+
+// SetMemoryBudget bounds the memory taken by all the parses in progress in the process, so that a
+// burst of large payloads reaching a server at once cannot exhaust its memory. It complements the
+// limits of a single parse, such as MaxDocumentSize, which cannot account for how many parses run
+// concurrently. A value of zero or less removes the budget, which is the default.
+//
+// A parse is charged for the bytes of input it reads, which the memory of the tree it builds is
+// proportional to, so the budget should leave room for the few times the input size a tree takes.
+// Every function that builds a tree is charged: ParseJSON and so Parse, ParseBytes and ParseReader,
+// ParsePartial, ParseEmbedded, ParseOrderedMap, ParseFields, and Object.Resolve and Marshal, which
+// parse the text of a RawMessage. ValidateStream, CountRecords and StreamArray, whose memory use
+// does not grow with the size of the input, are not. The charge is released when the function
+// returns, even though the caller keeps the tree.
+//
+// When the size of the input is known, such as for a string, a []byte or a regular file, the whole
+// of it is reserved before parsing starts, and what happens when it does not fit is set by
+// SetMemoryBudgetMode. Input of unknown size, such as a pipe or a network connection, is instead
+// reserved as it is read, and as waiting while holding part of the budget could leave two parses
+// waiting on each other, such a parse fails once over the budget whatever the mode. An input larger
+// than the whole budget always fails. A parse that fails before reading any of the document leaves
+// the Parser as it was, so that ParseJSON can be called again to retry it, such as by a stream
+// decoder once the load has gone down.
+//
+// The budget can be changed while parses are in progress: they keep what they reserved, and
+// parses waiting under BudgetBlock check again against the new budget.
+func SetMemoryBudget(bytes int64) {
+	memoryBudget.mu.Lock()
+	defer memoryBudget.mu.Unlock()
+
+	memoryBudget.limit.Store(max(bytes, 0))
+	memoryBudget.cond.Broadcast()
+}
+
+// SetMemoryBudgetMode sets what a parse does when the memory budget set by SetMemoryBudget is not
+// enough for it, such as BudgetBlock to queue parses in a batch job rather than fail them.
+func SetMemoryBudgetMode(mode BudgetMode) {
+	memoryBudget.mu.Lock()
+	defer memoryBudget.mu.Unlock()
+
+	memoryBudget.mode = mode
+	memoryBudget.cond.Broadcast()
+}
+
+// reserveBudget takes n bytes from the memory budget, waiting for them when wait is true and the
+// mode is BudgetBlock. It returns an error wrapping ErrMemoryBudget when they cannot be had.
+func reserveBudget(n int64, wait bool) error {
+	memoryBudget.mu.Lock()
+	defer memoryBudget.mu.Unlock()
+
+	for limit := memoryBudget.limit.Load(); limit > 0 && memoryBudget.used+n > limit; limit = memoryBudget.limit.Load() {
+		if !wait || memoryBudget.mode != BudgetBlock || n > limit {
+			return fmt.Errorf("%w: %d bytes needed, %d of %d in use", ErrMemoryBudget, n, memoryBudget.used, limit)
+		}
+
+		memoryBudget.cond.Wait()
+	}
+
+	memoryBudget.used += n
+
+	return nil
+}
+
+// releaseBudget gives n bytes back to the memory budget.
+func releaseBudget(n int64) {
+	if n == 0 {
+		return
+	}
+
+	memoryBudget.mu.Lock()
+	defer memoryBudget.mu.Unlock()
+
+	memoryBudget.used -= n
+	memoryBudget.cond.Broadcast()
+}
+
+// budgeted reports whether a memory budget is set. It does not lock the budget, so that parses
+// without one pay no more than an atomic load.
+func budgeted() bool {
+	return memoryBudget.limit.Load() > 0
+}
+
+// reserveDocument charges the document about to be parsed to the memory budget: the input left
+// after the previous document when its size is known, or a first chunk otherwise. When the budget
+// is not enough, it records the error and reports false, having read nothing of the document.
+func (p *Parser) reserveDocument() bool {
+	if !budgeted() {
+		return true
+	}
+
+	if p.lexer.total >= 0 {
+		return p.reserve(int64(max(p.lexer.total-p.budgetFrom, 0)), true)
+	}
+
+	for p.budgetNext = p.budgetFrom; p.lexer.consumed() >= p.budgetNext; {
+		p.budgetNext += budgetChunk
+		if !p.reserve(budgetChunk, false) {
+			p.releaseReserved()
+			return false
+		}
+	}
+
+	return true
+}
+
+// reserveRead charges the input read beyond what was reserved so far to the memory budget, a
+// chunk at a time, for input of unknown size. Part of the document has been read by then, so
+// failing stops the parse for good.
+func (p *Parser) reserveRead() {
+	for !p.halted && p.lexer.consumed() >= p.budgetNext {
+		p.budgetNext += budgetChunk
+		p.halted = !p.reserve(budgetChunk, false)
+	}
+}
+
+// reserve takes n bytes from the memory budget for the parse, recording an error and reporting
+// false when they cannot be had.
+func (p *Parser) reserve(n int64, wait bool) bool {
+	if err := reserveBudget(n, wait); err != nil {
+		p.errors = append(p.errors, &ParseError{
+			Line:    p.currentToken.Line,
+			Column:  p.currentToken.Column,
+			Message: err.Error(),
+			Path:    p.pointer(),
+			err:     ErrMemoryBudget,
+		})
+
+		return false
+	}
+
+	p.budgetReserved += n
+
+	return true
+}
+
+// releaseDocument gives back to the memory budget what the parse reserved.
+func (p *Parser) releaseDocument() {
+	p.releaseReserved()
+	p.budgetFrom = p.lexer.consumed()
+}
+
+// releaseReserved gives back to the memory budget what was reserved so far.
+func (p *Parser) releaseReserved() {
+	releaseBudget(p.budgetReserved)
+	p.budgetReserved = 0
+	p.budgetNext = 0
+}
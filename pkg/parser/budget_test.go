@@ -0,0 +1,185 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	t.Cleanup(func() {
+		parser.SetMemoryBudget(0)
+		parser.SetMemoryBudgetMode(parser.BudgetReject)
+	})
+
+	parser.SetMemoryBudget(16)
+
+	if _, err := parser.Parse(`{"a": [1, 2]}`); err != nil {
+		t.Errorf("Expected a document within the budget to parse, got %v", err)
+	}
+
+	_, err := parser.Parse(`{"a": [1, 2, 3, 4, 5]}`)
+	if !errors.Is(err, parser.ErrMemoryBudget) {
+		t.Fatalf("Expected ErrMemoryBudget, got %v", err)
+	}
+
+	if expected := "Line 1, Column 1: memory budget exceeded: 22 bytes needed, 0 of 16 in use"; err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err)
+	}
+
+	// Input of unknown size is charged as it is read and fails once over the budget, even
+	// when parses may block
+	parser.SetMemoryBudgetMode(parser.BudgetBlock)
+
+	reader := iotest.OneByteReader(strings.NewReader(`[` + strings.Repeat(`"abcdefgh", `, 20000) + `1]`))
+	if _, err := parser.NewParser(parser.NewLexer(reader)).ParseJSON(); !errors.Is(err, parser.ErrMemoryBudget) {
+		t.Errorf("Expected ErrMemoryBudget for a reader, got %v", err)
+	}
+
+	parser.SetMemoryBudget(0)
+
+	if _, err := parser.Parse(`{"a": [1, 2, 3, 4, 5]}`); err != nil {
+		t.Errorf("Expected no limit once the budget is removed, got %v", err)
+	}
+}
+
+func TestMemoryBudgetRetry(t *testing.T) {
+	t.Cleanup(func() { parser.SetMemoryBudget(0) })
+
+	parser.SetMemoryBudget(16)
+
+	// A document turned down before being read can be parsed again once the budget allows it
+	p := parser.NewParser(parser.NewLexer(`[1, 2, 3, 4, 5, 6] [7]`))
+	if _, err := p.ParseJSON(); !errors.Is(err, parser.ErrMemoryBudget) {
+		t.Fatalf("Expected ErrMemoryBudget, got %v", err)
+	}
+
+	parser.SetMemoryBudget(64)
+
+	for _, expected := range []string{`[1,2,3,4,5,6]`, `[7]`} {
+		v, err := p.ParseJSON()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if data, _ := parser.Marshal(v); string(data) != expected {
+			t.Errorf("Expected %s, got %s", expected, data)
+		}
+	}
+}
+
+func TestMemoryBudgetEntryPoints(t *testing.T) {
+	t.Cleanup(func() { parser.SetMemoryBudget(0) })
+
+	input := `{"a": [1, 2, 3], "b": {"c": [4, 5, 6]}}`
+
+	lazy, err := parser.Parse(input, parser.WithLazyKeys("b"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parser.SetMemoryBudget(8)
+
+	tests := []struct {
+		name  string
+		parse func() error
+	}{
+		{"ParseOrderedMap", func() error {
+			_, err := parser.ParseOrderedMap(input)
+			return err
+		}},
+		{"ParseFields", func() error {
+			_, err := parser.ParseFields(input, "a")
+			return err
+		}},
+		{"ParseEmbedded", func() error {
+			_, _, err := parser.ParseEmbedded("data="+input, 0)
+			return err
+		}},
+		{"Resolve", func() error {
+			_, err := lazy.(*parser.Object).Resolve("b")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parse(); !errors.Is(err, parser.ErrMemoryBudget) {
+				t.Errorf("Expected ErrMemoryBudget, got %v", err)
+			}
+		})
+	}
+
+	// Validation builds no tree, so it is not charged
+	if err := parser.ValidateStream(strings.NewReader(input)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMemoryBudgetConcurrent(t *testing.T) {
+	t.Cleanup(func() {
+		parser.SetMemoryBudget(0)
+		parser.SetMemoryBudgetMode(parser.BudgetReject)
+	})
+
+	parser.SetMemoryBudget(32)
+
+	input := `{"items": [1, 2, 3, 4, 5, 6]}` // 29 bytes, so one parse at a time
+	release := make(chan struct{})
+	held := make(chan struct{})
+
+	first := make(chan error)
+	go func() {
+		_, err := parser.Parse(input, parser.WithOnValue(func(path string, v parser.Value) error {
+			if path == "" {
+				close(held)
+				<-release
+			}
+
+			return nil
+		}))
+		first <- err
+	}()
+
+	<-held
+
+	// Rejected while the first parse holds the budget
+	if _, err := parser.Parse(input); !errors.Is(err, parser.ErrMemoryBudget) {
+		t.Errorf("Expected ErrMemoryBudget, got %v", err)
+	}
+
+	// Blocked until the first parse ends
+	parser.SetMemoryBudgetMode(parser.BudgetBlock)
+
+	second := make(chan error)
+	go func() {
+		_, err := parser.Parse(input)
+		second <- err
+	}()
+
+	select {
+	case err := <-second:
+		t.Fatalf("Expected the parse to wait for the budget, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	if err := <-first; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := <-second; err != nil {
+		t.Errorf("Expected the waiting parse to succeed, got %v", err)
+	}
+
+	// A document larger than the whole budget never fits, so it is rejected rather than left
+	// waiting
+	if _, err := parser.Parse(input + strings.Repeat(" ", 8)); !errors.Is(err, parser.ErrMemoryBudget) {
+		t.Errorf("Expected ErrMemoryBudget for a document over the budget, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CanonicalBytes serializes the tree rooted at v in the JSON Canonicalization Scheme of RFC 8785,
+// for computing and verifying signatures over JSON bodies, such as with HTTP message signatures:
+// two documents that hold the same data give the same bytes however they were formatted.
+//
+// The form is that of RFC 8785 exactly: no whitespace, object members sorted by the UTF-16 code
+// units of their keys, every number rendered from its float64 value in the shortest form that
+// round-trips, as ECMAScript does, and strings escaped only where JSON requires it, with the
+// short escapes \b, \f, \n, \r and \t and lowercase \u00xx for other control characters. Unlike
+// the output of MarshalWith, which may change as options are added, this form is a stability
+// contract: a future version of this package will only change it to fix a departure from
+// RFC 8785, so signatures computed with it remain verifiable across versions.
+//
+// Comments and source formatting are dropped, and RawMessage values are parsed and canonicalized.
+// It returns an error for a value that RFC 8785 cannot represent: NaN, an infinity, an invalid
+// number, a string that is not valid UTF-8, or malformed raw JSON. Integers beyond 2^53 lose
+// precision, as the float64 values RFC 8785 is defined over do.
+func CanonicalBytes(v Value) ([]byte, error) {
+	b := encodeBuffer{buf: make([]byte, 0, 64)}
+
+	if err := writeCanonical(&b, v); err != nil {
+		return nil, err
+	}
+
+	return b.buf, nil
+}
+
+// writeCanonical writes the RFC 8785 form of v to b.
+func writeCanonical(b *encodeBuffer, v Value) error {
+	switch val := v.(type) {
+	case *Object:
+		keys := make([]string, 0, len(val.Pairs))
+		for k := range val.Pairs {
+			keys = append(keys, k)
+		}
+
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+		b.writeByte('{')
+
+		for i, k := range keys {
+			if i > 0 {
+				b.writeByte(',')
+			}
+
+			if err := writeCanonicalString(b, k); err != nil {
+				return err
+			}
+
+			b.writeByte(':')
+
+			if err := writeCanonical(b, val.Pairs[k]); err != nil {
+				return err
+			}
+		}
+
+		b.writeByte('}')
+
+	case *Array:
+		b.writeByte('[')
+
+		for i, elem := range val.Elements {
+			if i > 0 {
+				b.writeByte(',')
+			}
+
+			if err := writeCanonical(b, elem); err != nil {
+				return err
+			}
+		}
+
+		b.writeByte(']')
+
+	case *StringLiteral:
+		return writeCanonicalString(b, val.Value)
+
+	case *NumberLiteral:
+		if !val.IsValidNumber() {
+			return fmt.Errorf("invalid number: %q", val.Value)
+		}
+
+		s, err := formatCanonicalNumber(val.Float)
+		if err != nil {
+			return err
+		}
+
+		b.writeString(s)
+
+	case *Boolean:
+		if val.Value {
+			b.writeString("true")
+		} else {
+			b.writeString("false")
+		}
+
+	case *Null:
+		b.writeString("null")
+
+	case *RawMessage:
+		parsed, err := val.parse()
+		if err != nil {
+			return fmt.Errorf("invalid raw JSON: %w", err)
+		}
+
+		return writeCanonical(b, parsed)
+
+	case json.Marshaler:
+		marshaled, err := marshalerValue(val)
+		if err != nil {
+			return err
+		}
+
+		return writeCanonical(b, marshaled)
+
+	default:
+		return fmt.Errorf("unknown value type: %T", v)
+	}
+
+	return nil
+}
+
+// writeCanonicalString writes s as a JSON string in the form required by RFC 8785.
+func writeCanonicalString(b *encodeBuffer, s string) error {
+	const hex = "0123456789abcdef"
+
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("invalid UTF-8 in string %q", s)
+	}
+
+	b.writeByte('"')
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.writeByte('\\')
+			b.writeByte(c)
+		case '\b':
+			b.writeString(`\b`)
+		case '\f':
+			b.writeString(`\f`)
+		case '\n':
+			b.writeString(`\n`)
+		case '\r':
+			b.writeString(`\r`)
+		case '\t':
+			b.writeString(`\t`)
+		default:
+			if c < 0x20 {
+				b.writeString(`\u00`)
+				b.writeByte(hex[c>>4])
+				b.writeByte(hex[c&0xf])
+			} else {
+				b.writeByte(c) // Multi-byte characters are copied byte by byte
+			}
+		}
+	}
+
+	b.writeByte('"')
+
+	return nil
+}
+
+// lessUTF16 reports whether a sorts before b when both are compared as sequences of UTF-16 code
+// units, the order RFC 8785 sorts object keys in. It differs from the byte order of UTF-8 for
+// characters outside the Basic Multilingual Plane, which sort before U+E000 to U+FFFF.
+func lessUTF16(a, b string) bool {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+
+		if ra != rb {
+			return utf16Units(ra) < utf16Units(rb)
+		}
+
+		a, b = a[na:], b[nb:]
+	}
+
+	return len(a) < len(b)
+}
+
+// utf16Units returns the first UTF-16 code unit of r, which is its high surrogate for a character
+// outside the Basic Multilingual Plane, and the second one, or zero when there is none, packed
+// together so that comparing the results compares the code units.
+func utf16Units(r rune) uint32 {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		return uint32(r1)<<16 | uint32(r2)
+	}
+
+	return uint32(r) << 16
+}
@@ -0,0 +1,130 @@
+package parser_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestCanonicalBytes(t *testing.T) {
+	// Examples from RFC 8785, sections 3.2.2 and 3.2.3
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Primitive data types", `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`},
+		{"Sorting of object properties", `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+			"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"},
+		{"Escapes", `["\b\f\t\u0001\u001f\u007f <&> \u2028"]`, "[\"\\b\\f\\t\\u0001\\u001f\u007f <&> \u2028\"]"},
+		{"Nested", `{"b": [{"z": 1, "a": {}}], "a": []}`, `{"a":[],"b":[{"a":{},"z":1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.CanonicalBytes(mustParse(t, tt.input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCanonicalBytesNumbers(t *testing.T) {
+	// Test vectors from RFC 8785, appendix B
+	tests := []struct {
+		bits     uint64
+		expected string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			n := &parser.NumberLiteral{Float: math.Float64frombits(tt.bits), IsValid: true}
+
+			got, err := parser.CanonicalBytes(n)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// NaN and Infinity have no JSON representation
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000} {
+		n := &parser.NumberLiteral{Float: math.Float64frombits(bits), IsValid: true}
+		if _, err := parser.CanonicalBytes(n); err == nil {
+			t.Errorf("Expected an error for %v", n.Float)
+		}
+	}
+}
+
+func TestCanonicalBytesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    parser.Value
+		expected string
+	}{
+		{"Invalid UTF-8", &parser.StringLiteral{Value: "a\xffb"}, `invalid UTF-8 in string "a\xffb"`},
+		{"Invalid number", &parser.NumberLiteral{Value: "1x"}, `invalid number: "1x"`},
+		{"Malformed raw JSON", &parser.Array{Elements: []parser.Value{&parser.RawMessage{Bytes: []byte(`{"a"}`)}}},
+			`invalid raw JSON: Line 1, Column 5: expected : after key "a", got }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parser.CanonicalBytes(tt.value); err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	raw := &parser.RawMessage{Bytes: []byte(` {"b": 1.0, "a": "x"} `)}
+	if got, err := parser.CanonicalBytes(raw); err != nil || string(got) != `{"a":"x","b":1}` {
+		t.Errorf("Expected raw JSON to be canonicalized, got %s and %v", got, err)
+	}
+}
@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The encodings reported by DetectEncoding.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingUTF32LE = "utf-32le"
+	EncodingUTF32BE = "utf-32be"
+)
+
+// Byte order marks, longest first where one is a prefix of another.
+var (
+	bomUTF32LE = []byte{0xff, 0xfe, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xfe, 0xff}
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// DetectEncoding reports the Unicode encoding of the JSON text in b, as one of EncodingUTF8,
+// EncodingUTF8BOM, EncodingUTF16LE, EncodingUTF16BE, EncodingUTF32LE or EncodingUTF32BE, which
+// are named after their IANA charsets. A byte order mark decides when there is one. Without one,
+// the pattern of zero bytes at the start is used, as suggested by RFC 4627: JSON text begins with
+// two ASCII characters, so for instance "[" encoded as UTF-16LE starts with 5b 00. Anything else
+// is reported as UTF-8, the only encoding RFC 8259 allows for interchange.
+func DetectEncoding(b []byte) string {
+	switch {
+	case bytes.HasPrefix(b, bomUTF32LE):
+		return EncodingUTF32LE
+	case bytes.HasPrefix(b, bomUTF32BE):
+		return EncodingUTF32BE
+	case bytes.HasPrefix(b, bomUTF8):
+		return EncodingUTF8BOM
+	case bytes.HasPrefix(b, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(b, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+
+	if len(b) >= 4 {
+		switch {
+		case b[0] == 0 && b[1] == 0 && b[2] == 0 && b[3] != 0:
+			return EncodingUTF32BE
+		case b[0] != 0 && b[1] == 0 && b[2] == 0 && b[3] == 0:
+			return EncodingUTF32LE
+		case b[0] == 0 && b[1] != 0 && b[2] == 0 && b[3] != 0:
+			return EncodingUTF16BE
+		case b[0] != 0 && b[1] == 0 && b[2] != 0 && b[3] == 0:
+			return EncodingUTF16LE
+		}
+	} else if len(b) >= 2 {
+		switch {
+		case b[0] == 0 && b[1] != 0:
+			return EncodingUTF16BE
+		case b[0] != 0 && b[1] == 0:
+			return EncodingUTF16LE
+		}
+	}
+
+	return EncodingUTF8
+}
+
+// checkEncoding records an EncodingError when the input starts with a byte order mark or is
+// detected to be in an encoding other than UTF-8. It must be called before the first token is
+// read.
+func (l *Lexer) checkEncoding() {
+	encoding := DetectEncoding([]byte(l.input[:min(len(l.input), 4)]))
+
+	switch encoding {
+	case EncodingUTF8:
+		return
+	case EncodingUTF8BOM:
+		l.encodingError(encoding, 0, "input starts with a UTF-8 byte order mark, which JSON text must not begin with")
+	default:
+		l.encodingError(encoding, 0, "input is encoded as %s, expected %s; transcode it or use ParseBytesAny",
+			encoding, EncodingUTF8)
+	}
+}
+
+// checkCharacter records an EncodingError when the current character, which cannot start a
+// token, is a byte order mark or a byte that is not valid UTF-8.
+func (l *Lexer) checkCharacter() {
+	offset := l.offset + l.position
+
+	switch {
+	case l.ch == '\ufeff':
+		l.encodingError(EncodingUTF8BOM, offset, "unexpected byte order mark at byte offset %d", offset)
+	case l.ch == utf8.RuneError && l.readPosition-l.position == 1:
+		l.encodingError("", offset, "invalid UTF-8 byte 0x%02x at byte offset %d", l.input[l.position], offset)
+	}
+}
+
+// encodingError records the first EncodingError of the input, at the position of the current
+// character.
+func (l *Lexer) encodingError(detected string, offset int, format string, a ...interface{}) {
+	if l.encodingErr != nil {
+		return
+	}
+
+	err := &EncodingError{
+		Detected: detected,
+		Expected: EncodingUTF8,
+		Offset:   offset,
+		message:  fmt.Sprintf(format, a...),
+	}
+	l.encodingErr = &ParseError{Line: l.line, Column: l.column, Message: err.message, err: err}
+}
+
+// encodingErrorAt returns the EncodingError that explains a failure at token, if any: one about
+// the whole input explains every failure, but one about a character only a failure at its
+// position.
+func (l *Lexer) encodingErrorAt(token Token) *ParseError {
+	err := l.encodingErr
+	if err == nil || err.err.(*EncodingError).Offset > 0 && (token.Line != err.Line || token.Column != err.Column) {
+		return nil
+	}
+
+	return err
+}
+
+// ParseBytesAny parses a complete JSON document from b like ParseBytes, after detecting its
+// encoding with DetectEncoding, removing any byte order mark and transcoding UTF-16 and UTF-32
+// to UTF-8. This accepts the exports of tools that write UTF-16, as is common on .NET and Java.
+// Characters that cannot be decoded, such as unpaired surrogates or a truncated code unit at the
+// end, are replaced with U+FFFD. UTF-8 input without a byte order mark is parsed in place.
+func ParseBytesAny(b []byte, opts ...Option) (Value, error) {
+	return ParseBytes(toUTF8(b), opts...)
+}
+
+// toUTF8 returns the JSON text in b converted to UTF-8 without a byte order mark.
+func toUTF8(b []byte) []byte {
+	switch encoding := DetectEncoding(b); encoding {
+	case EncodingUTF8BOM:
+		return b[len(bomUTF8):]
+	case EncodingUTF16LE, EncodingUTF16BE:
+		order := binary.ByteOrder(binary.LittleEndian)
+		if encoding == EncodingUTF16BE {
+			order = binary.BigEndian
+		}
+
+		if bytes.HasPrefix(b, bomUTF16LE) || bytes.HasPrefix(b, bomUTF16BE) {
+			b = b[2:]
+		}
+
+		units := make([]uint16, 0, len(b)/2)
+		for ; len(b) >= 2; b = b[2:] {
+			units = append(units, order.Uint16(b))
+		}
+
+		out := []byte(string(utf16.Decode(units)))
+		if len(b) > 0 {
+			out = utf8.AppendRune(out, utf8.RuneError)
+		}
+
+		return out
+	case EncodingUTF32LE, EncodingUTF32BE:
+		order := binary.ByteOrder(binary.LittleEndian)
+		if encoding == EncodingUTF32BE {
+			order = binary.BigEndian
+		}
+
+		if bytes.HasPrefix(b, bomUTF32LE) || bytes.HasPrefix(b, bomUTF32BE) {
+			b = b[4:]
+		}
+
+		out := make([]byte, 0, len(b)/4)
+		for ; len(b) >= 4; b = b[4:] {
+			out = utf8.AppendRune(out, rune(order.Uint32(b))) // Invalid code points append U+FFFD
+		}
+
+		if len(b) > 0 {
+			out = utf8.AppendRune(out, utf8.RuneError)
+		}
+
+		return out
+	default:
+		return b
+	}
+}
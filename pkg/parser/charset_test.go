@@ -0,0 +1,166 @@
+package parser_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the given byte order, preceded by a byte order mark when bom
+// is set.
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var b []byte
+
+	if bom {
+		b = order.AppendUint16(b, 0xfeff)
+	}
+
+	for _, unit := range utf16.Encode([]rune(s)) {
+		b = order.AppendUint16(b, unit)
+	}
+
+	return b
+}
+
+// encodeUTF32 encodes s as UTF-32 in the given byte order, preceded by a byte order mark when bom
+// is set.
+func encodeUTF32(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var b []byte
+
+	if bom {
+		b = order.AppendUint32(b, 0xfeff)
+	}
+
+	for _, r := range s {
+		b = order.AppendUint32(b, uint32(r))
+	}
+
+	return b
+}
+
+func TestDetectEncoding(t *testing.T) {
+	const doc = `{"name": "Zoë 🎉"}`
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{"UTF-8", []byte(doc), parser.EncodingUTF8},
+		{"UTF-8 with BOM", append([]byte{0xef, 0xbb, 0xbf}, doc...), parser.EncodingUTF8BOM},
+		{"UTF-16LE with BOM", encodeUTF16(doc, binary.LittleEndian, true), parser.EncodingUTF16LE},
+		{"UTF-16BE with BOM", encodeUTF16(doc, binary.BigEndian, true), parser.EncodingUTF16BE},
+		{"UTF-16LE", encodeUTF16(doc, binary.LittleEndian, false), parser.EncodingUTF16LE},
+		{"UTF-16BE", encodeUTF16(doc, binary.BigEndian, false), parser.EncodingUTF16BE},
+		{"UTF-32LE with BOM", encodeUTF32(doc, binary.LittleEndian, true), parser.EncodingUTF32LE},
+		{"UTF-32BE", encodeUTF32(doc, binary.BigEndian, false), parser.EncodingUTF32BE},
+		{"Short UTF-16LE", encodeUTF16("1", binary.LittleEndian, false), parser.EncodingUTF16LE},
+		{"Empty", nil, parser.EncodingUTF8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.DetectEncoding(tt.input); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseBytesAny(t *testing.T) {
+	const doc = `{"name": "Zoë 🎉", "tags": ["a"]}`
+
+	inputs := map[string][]byte{
+		"UTF-8":             []byte(doc),
+		"UTF-8 with BOM":    append([]byte{0xef, 0xbb, 0xbf}, doc...),
+		"UTF-16LE with BOM": encodeUTF16(doc, binary.LittleEndian, true),
+		"UTF-16BE":          encodeUTF16(doc, binary.BigEndian, false),
+		"UTF-32LE":          encodeUTF32(doc, binary.LittleEndian, false),
+		"UTF-32BE with BOM": encodeUTF32(doc, binary.BigEndian, true),
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			value, err := parser.ParseBytesAny(input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !parser.Equal(value, mustParse(t, doc)) {
+				t.Errorf("Expected %s, got %v", doc, value)
+			}
+		})
+	}
+
+	if _, err := parser.ParseBytesAny(encodeUTF16(`{"a": 1`, binary.LittleEndian, true)); err == nil {
+		t.Error("Expected a parse error for a truncated UTF-16 document")
+	}
+}
+
+func TestEncodingError(t *testing.T) {
+	const doc = `{"name": "Zoë", "tags": ["a"]}`
+
+	tests := []struct {
+		name     string
+		input    []byte
+		detected string
+		offset   int
+		expected string
+	}{
+		{"UTF-8 BOM", append([]byte{0xef, 0xbb, 0xbf}, doc...), parser.EncodingUTF8BOM, 0,
+			"Line 1, Column 1: input starts with a UTF-8 byte order mark, which JSON text must not begin with"},
+		{"UTF-16LE", encodeUTF16(doc, binary.LittleEndian, false), parser.EncodingUTF16LE, 0,
+			"Line 1, Column 1: input is encoded as utf-16le, expected utf-8; transcode it or use ParseBytesAny"},
+		{"UTF-16BE with BOM", encodeUTF16(doc, binary.BigEndian, true), parser.EncodingUTF16BE, 0,
+			"Line 1, Column 1: input is encoded as utf-16be, expected utf-8; transcode it or use ParseBytesAny"},
+		{"UTF-32LE", encodeUTF32(doc, binary.LittleEndian, false), parser.EncodingUTF32LE, 0,
+			"Line 1, Column 1: input is encoded as utf-32le, expected utf-8; transcode it or use ParseBytesAny"},
+		{"BOM mid-stream", []byte("[1,\n\ufeff2]"), parser.EncodingUTF8BOM, 4,
+			"Line 2, Column 1: unexpected byte order mark at byte offset 4"},
+		{"Invalid UTF-8", []byte("{\"a\": 1, \"b\": \xc3(}"), "", 14,
+			"Line 1, Column 15: invalid UTF-8 byte 0xc3 at byte offset 14"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, parse := range map[string]func() (parser.Value, error){
+				"Bytes":  func() (parser.Value, error) { return parser.ParseBytes(tt.input) },
+				"Reader": func() (parser.Value, error) { return parser.ParseReader(bytes.NewReader(tt.input)) },
+			} {
+				_, err := parse()
+				if err == nil || err.Error() != tt.expected {
+					t.Fatalf("%s: expected error %q, got %v", name, tt.expected, err)
+				}
+
+				var encErr *parser.EncodingError
+				if !errors.As(err, &encErr) {
+					t.Fatalf("%s: expected an EncodingError, got %T", name, err)
+				}
+
+				if encErr.Detected != tt.detected || encErr.Expected != parser.EncodingUTF8 || encErr.Offset != tt.offset {
+					t.Errorf("%s: expected %q, %q and offset %d, got %q, %q and %d", name,
+						tt.detected, parser.EncodingUTF8, tt.offset, encErr.Detected, encErr.Expected, encErr.Offset)
+				}
+			}
+		})
+	}
+
+	t.Run("Not encoding errors", func(t *testing.T) {
+		// Syntax errors are not EncodingErrors, even in a document with a bad character after them
+		for _, input := range []string{`{"a": 1,}`, "[1 2 \xff]", "[\"\xff\"", "[tru\ufeff]"} {
+			var encErr *parser.EncodingError
+			if _, err := parser.Parse(input); err == nil || errors.As(err, &encErr) {
+				t.Errorf("Expected a syntax error for %q, got %v", input, err)
+			}
+		}
+
+		// Invalid UTF-8 within strings is decoded as U+FFFD
+		if _, err := parser.Parse("[\"a\xffb\"]"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
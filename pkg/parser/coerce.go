@@ -0,0 +1,53 @@
+package parser
+
+// Truthy reports whether v is truthy under lenient, JavaScript-like rules. It is meant for
+// feature-flag style configuration where "true", 1 and true should all enable something, and is
+// deliberately not part of strict boolean access, for which v should be asserted to *Boolean.
+//
+// The falsy values are exactly: false, null, any number equal to zero, the empty string, the
+// empty array, the empty object, and a nil Value. Everything else is truthy, including the
+// strings "false" and "0".
+func Truthy(v Value) bool {
+	switch val := v.(type) {
+	case *Boolean:
+		return val.Value
+	case *Null:
+		return false
+	case *NumberLiteral:
+		return val.Float != 0
+	case *StringLiteral:
+		return val.Value != ""
+	case *Array:
+		return len(val.Elements) > 0
+	case *Object:
+		return len(val.Pairs) > 0
+	default:
+		return false
+	}
+}
+
+// AsNumber returns the value of v as a float64 under lenient rules, for APIs that quote numbers
+// inconsistently, so that both 42 and "42" read as 42. Like Truthy, it is deliberately separate
+// from strict numeric access, for which v should be asserted to *NumberLiteral.
+//
+// A number returns its Float. A string is converted only when its whole value is a valid JSON
+// number literal, with no sign other than a leading minus, no leading zeros and no surrounding
+// whitespace, so "42", "-1.5" and "1e3" convert but "+1", "007", " 42", "0x1F", "NaN" and ""
+// do not. Booleans, null, containers, other strings and a nil Value report false.
+func AsNumber(v Value) (float64, bool) {
+	switch val := v.(type) {
+	case *NumberLiteral:
+		return val.Float, val.IsValid
+	case *StringLiteral:
+		token := NewLexer(val.Value).NextToken()
+		if token.Type != TokenNumber || token.Literal != val.Value {
+			return 0, false
+		}
+
+		n := NewNumberLiteral(token)
+
+		return n.Float, n.IsValid
+	default:
+		return 0, false
+	}
+}
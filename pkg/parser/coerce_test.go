@@ -0,0 +1,64 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestTruthy(t *testing.T) {
+	value := mustParse(t, `[false, null, 0, -0.0, "", [], {}, true, 1, 0.5, "true", "false", "0", [0], {"a": null}]`)
+
+	expected := []bool{false, false, false, false, false, false, false, true, true, true, true, true, true, true, true}
+
+	for i, elem := range value.(*parser.Array).Elements {
+		if got := parser.Truthy(elem); got != expected[i] {
+			t.Errorf("Element %d (%s): expected %v, got %v", i, elem.TokenLiteral(), expected[i], got)
+		}
+	}
+
+	if parser.Truthy(nil) {
+		t.Error("Expected nil Value to be falsy")
+	}
+}
+
+func TestAsNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		ok       bool
+	}{
+		{`42`, 42, true},
+		{`-1.5e2`, -150, true},
+		{`"42"`, 42, true},
+		{`"-0.25"`, -0.25, true},
+		{`"1e3"`, 1000, true},
+		{`"+1"`, 0, false},
+		{`"007"`, 0, false},
+		{`" 42"`, 0, false},
+		{`"42 "`, 0, false},
+		{`"1."`, 0, false},
+		{`"0x1F"`, 0, false},
+		{`"NaN"`, 0, false},
+		{`""`, 0, false},
+		{`"12abc"`, 0, false},
+		{`true`, 0, false},
+		{`null`, 0, false},
+		{`[1]`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := mustParse(t, "["+tt.input+"]").(*parser.Array).Elements[0]
+
+			got, ok := parser.AsNumber(v)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("Expected %v, %v, got %v, %v", tt.expected, tt.ok, got, ok)
+			}
+		})
+	}
+
+	if _, ok := parser.AsNumber(nil); ok {
+		t.Error("Expected nil Value to not be a number")
+	}
+}
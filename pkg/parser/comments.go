@@ -0,0 +1,79 @@
+package parser
+
+import "slices"
+
+// LeadingComments returns the comments that precede v in the source. Comments are only recorded
+// when the parser runs with both AllowComments and RetainComments enabled, and are returned
+// verbatim, including their // or /* */ delimiters.
+//
+// The attachment rules are:
+//   - A comment is attached as leading trivia to the first value that starts after it. For object
+//     members this includes comments before the key, the colon and any preceding comma.
+//   - Comments before the closing '}' or ']' of a container, with no value after them, trail
+//     that container. See TrailingComments.
+//   - Comments after the root value trail the root value.
+func LeadingComments(v Value) []string {
+	if n, ok := v.(interface{ info() *nodeInfo }); ok {
+		return n.info().leadingComments
+	}
+
+	return nil
+}
+
+// TrailingComments returns the comments that follow the last element of a container, or the
+// root value of a document. See LeadingComments for the attachment rules.
+func TrailingComments(v Value) []string {
+	if n, ok := v.(interface{ info() *nodeInfo }); ok {
+		return n.info().trailingComments
+	}
+
+	return nil
+}
+
+// trailingCommentsToken is the reference token appended to the pointer of a value to key the
+// comments trailing it in the map of ParseWithComments. A lone "~" is not a valid token of a
+// JSON Pointer, so the key never names a value of the document.
+const trailingCommentsToken = "/~"
+
+// ParseWithComments parses input like Parse with comments allowed, and returns the comments
+// separately from the tree, in a map from JSON Pointers to the comments found at each location,
+// instead of attaching them to the nodes, so that tools such as config editors can show the help
+// written in comments next to each setting while working on a plain tree. Following the rules of
+// LeadingComments, the comments preceding a value are keyed by its pointer, so "/server/port"
+// holds the comments before the port member and "" those at the top of the document. The
+// comments trailing a container, before its closing bracket, and those after the root value, are
+// keyed by the pointer of the value followed by "/~", as in "/items/~" or "/~" for the end of the
+// document; the "~" token cannot occur in a pointer to a value. Locations without comments have
+// no entry, and comments are kept verbatim with their delimiters. opts are applied first, and
+// comments are always allowed and retained.
+func ParseWithComments(input string, opts ...Option) (Value, map[string][]string, error) {
+	v, err := Parse(input, append(slices.Clip(opts), WithRetainedComments())...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comments := make(map[string][]string)
+
+	_ = Walk(v, func(pointer string, v Value) error {
+		n, ok := v.(interface{ info() *nodeInfo })
+		if !ok {
+			return nil
+		}
+
+		info := n.info()
+
+		if len(info.leadingComments) > 0 {
+			comments[pointer] = info.leadingComments
+		}
+
+		if len(info.trailingComments) > 0 {
+			comments[pointer+trailingCommentsToken] = info.trailingComments
+		}
+
+		info.leadingComments, info.trailingComments = nil, nil
+
+		return nil
+	})
+
+	return v, comments, nil
+}
@@ -0,0 +1,337 @@
+package parser
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnifiedDiff renders the differences between the documents a and b in the +/- line format of
+// git, for showing changes to a config in a review comment or on the command line. It returns the
+// empty string when the documents are Equal. Otherwise the output starts with a "--- a" and a
+// "+++ b" header, followed by one hunk per changed location, in key order: a "@@ pointer @@"
+// line naming the location by its JSON Pointer, or "(root)" for the whole document, then the old
+// value printed over "-" lines and the new one over "+" lines.
+//
+// A member present on only one side is shown as added or removed, with only "+" or "-" lines.
+// Objects present on both sides are compared member by member and arrays element by element, by
+// index, so the elements past the end of the shorter array are added or removed. Any other
+// difference, including a change of type, shows the whole old and new values. Values are compared
+// like Equal, so 1 changed to 1.0 is not shown, and printed like MarshalIndent with two spaces, or
+// with String when they cannot be marshaled, such as invalid numbers.
+func UnifiedDiff(a, b Value) string {
+	var out strings.Builder
+
+	diffValues("", a, b, func(pointer string, a, b Value) {
+		writeHunk(&out, pointer, a, b)
+	})
+
+	if out.Len() == 0 {
+		return ""
+	}
+
+	return "--- a\n+++ b\n" + out.String()
+}
+
+// ChangedPaths returns the JSON Pointer of every location where the documents a and b differ,
+// in key order, such as to decide which parts of a cached result to recompute. It finds the same
+// locations as the hunks of UnifiedDiff: objects present on both sides are compared member by
+// member and arrays element by element, by index, and values are compared like Equal. A member or
+// element present on only one side, such as an added subtree, is reported once, by its own
+// pointer, rather than by the pointers of the leaves below it, and so is a value whose type
+// changed. A difference at the root is reported as the empty pointer "". It returns an empty
+// slice when the documents are Equal.
+func ChangedPaths(a, b Value) []string {
+	paths := []string{}
+
+	diffValues("", a, b, func(pointer string, _, _ Value) {
+		paths = append(paths, pointer)
+	})
+
+	return paths
+}
+
+// diffValues calls changed with the pointer and the old and new values of every location where a
+// and b, found at pointer, differ, in key order. A nil a or b stands for a missing value.
+func diffValues(pointer string, a, b Value, changed func(pointer string, a, b Value)) {
+	if a == nil && b == nil || a != nil && b != nil && Equal(a, b) {
+		return
+	}
+
+	switch x := a.(type) {
+	case *Object:
+		if y, ok := b.(*Object); ok {
+			keys := make([]string, 0, len(x.Pairs)+len(y.Pairs))
+			for k := range x.Pairs {
+				keys = append(keys, k)
+			}
+
+			for k := range y.Pairs {
+				if _, ok := x.Pairs[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				diffValues(pointer+"/"+escapePointerToken(k), x.Pairs[k], y.Pairs[k], changed)
+			}
+
+			return
+		}
+
+	case *Array:
+		if y, ok := b.(*Array); ok {
+			for i := 0; i < max(len(x.Elements), len(y.Elements)); i++ {
+				var old, elem Value
+				if i < len(x.Elements) {
+					old = x.Elements[i]
+				}
+
+				if i < len(y.Elements) {
+					elem = y.Elements[i]
+				}
+
+				diffValues(pointer+"/"+strconv.Itoa(i), old, elem, changed)
+			}
+
+			return
+		}
+	}
+
+	changed(pointer, a, b)
+}
+
+// writeHunk writes the hunk for the difference between a and b, found at pointer, to out.
+func writeHunk(out *strings.Builder, pointer string, a, b Value) {
+	if pointer == "" {
+		out.WriteString("@@ (root) @@\n")
+	} else {
+		out.WriteString("@@ " + pointer + " @@\n")
+	}
+
+	writeDiffLines(out, '-', a)
+	writeDiffLines(out, '+', b)
+}
+
+// writeDiffLines writes v printed over lines starting with sign to out. A nil v writes nothing.
+func writeDiffLines(out *strings.Builder, sign byte, v Value) {
+	if v == nil {
+		return
+	}
+
+	text := v.String()
+	if data, err := MarshalIndent(v, "", "  "); err == nil {
+		text = string(data)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		out.WriteByte(sign)
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+}
+
+// ArrayOpKind is the kind of an operation of the edit script returned by ArrayDiff.
+type ArrayOpKind int
+
+const (
+	// ArrayKeep keeps an element of the old array, which is Equal to an element of the new one.
+	ArrayKeep ArrayOpKind = iota
+	// ArrayInsert inserts an element of the new array.
+	ArrayInsert
+	// ArrayDelete deletes an element of the old array.
+	ArrayDelete
+)
+
+// ArrayOp is one operation of the edit script returned by ArrayDiff.
+type ArrayOp struct {
+	// Kind tells whether the element is kept, inserted or deleted.
+	Kind ArrayOpKind
+	// OldIndex is the index of the element in the old array, or -1 for an insertion.
+	OldIndex int
+	// NewIndex is the index of the element in the new array, or -1 for a deletion.
+	NewIndex int
+	// Value is the element: the one of the old array when it is kept or deleted, and the one of
+	// the new array when it is inserted.
+	Value Value
+}
+
+// ArrayDiff returns an edit script that turns a into b, for showing how a list changed in terms
+// of the items added, removed and moved rather than index by index. Reading the script in order,
+// each element of a is either kept or deleted and each element of b either kept or inserted, so
+// that the kept elements of a, taken in order, are Equal to the kept elements of b. A nil array
+// counts as empty, and the elements are shared with a and b rather than copied.
+//
+// The script is minimal: it holds as few insertions and deletions as possible, keeping a longest
+// common subsequence of the two arrays. When several scripts are minimal, which one is returned
+// is unspecified beyond being stable, but the deletions of a run of changes come before its
+// insertions. Moving an element shows as a deletion and an insertion. The script is found with
+// the linear space variant of the algorithm of Myers, in O((n+m)·d) time and O(n+m) memory for
+// arrays of n and m elements that take d insertions and deletions, so similar arrays are
+// compared in close to linear time, and each step compares two elements with Equal, which
+// descends into nested values.
+func ArrayDiff(a, b *Array) []ArrayOp {
+	var x, y []Value
+	if a != nil {
+		x = a.Elements
+	}
+
+	if b != nil {
+		y = b.Elements
+	}
+
+	n, m := len(x), len(y)
+
+	// vf and vb are shared by every search for a middle snake, which only needs them while it runs
+	d := &arrayDiffer{x: x, y: y, offset: n + m + 1, ops: make([]ArrayOp, 0, max(n, m))}
+	d.vf = make([]int, 2*d.offset+1)
+	d.vb = make([]int, 2*d.offset+1)
+
+	d.compare(0, n, 0, m)
+
+	// Move the deletions of each run of changes before its insertions, which does not change
+	// what the script does
+	for i := 0; i < len(d.ops); {
+		if d.ops[i].Kind == ArrayKeep {
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(d.ops) && d.ops[end].Kind != ArrayKeep {
+			end++
+		}
+
+		slices.SortStableFunc(d.ops[i:end], func(p, q ArrayOp) int {
+			return cmp.Compare(q.Kind, p.Kind) // ArrayDelete sorts before ArrayInsert
+		})
+
+		i = end
+	}
+
+	return d.ops
+}
+
+// arrayDiffer finds the edit script of ArrayDiff between the elements x and y.
+type arrayDiffer struct {
+	x, y []Value
+	// vf and vb hold, at offset+k, the furthest index reached on diagonal k by the forward and
+	// backward searches of middleSnake
+	vf, vb []int
+	offset int
+	ops    []ArrayOp
+}
+
+// compare appends the operations turning x[i:n] into y[j:m], splitting the problem in two at a
+// middle snake until one side is empty.
+func (d *arrayDiffer) compare(i, n, j, m int) {
+	for i < n && j < m && Equal(d.x[i], d.y[j]) {
+		d.keep(i, j)
+		i++
+		j++
+	}
+
+	suffix := 0
+	for i < n-suffix && j < m-suffix && Equal(d.x[n-1-suffix], d.y[m-1-suffix]) {
+		suffix++
+	}
+
+	n, m = n-suffix, m-suffix
+
+	switch {
+	case i == n:
+		for ; j < m; j++ {
+			d.ops = append(d.ops, ArrayOp{Kind: ArrayInsert, OldIndex: -1, NewIndex: j, Value: d.y[j]})
+		}
+	case j == m:
+		for ; i < n; i++ {
+			d.ops = append(d.ops, ArrayOp{Kind: ArrayDelete, OldIndex: i, NewIndex: -1, Value: d.x[i]})
+		}
+	default:
+		x, y, u, v := d.middleSnake(i, n, j, m)
+
+		d.compare(i, x, j, y)
+
+		for ; x < u; x, y = x+1, y+1 {
+			d.keep(x, y)
+		}
+
+		d.compare(u, n, v, m)
+	}
+
+	for k := range suffix {
+		d.keep(n+k, m+k)
+	}
+}
+
+// keep appends the operation keeping x[i] as y[j].
+func (d *arrayDiffer) keep(i, j int) {
+	d.ops = append(d.ops, ArrayOp{Kind: ArrayKeep, OldIndex: i, NewIndex: j, Value: d.x[i]})
+}
+
+// middleSnake returns the snake from (x, y) to (u, v), a run of equal elements, that lies in the
+// middle of a shortest path turning x[i:n] into y[j:m]. It searches forward from the start and
+// backward from the end at once until the two searches overlap, as in section 4 of the paper of
+// Myers.
+func (d *arrayDiffer) middleSnake(i, n, j, m int) (x, y, u, v int) {
+	rows, cols := n-i, m-j
+	delta := rows - cols
+	odd := delta%2 != 0
+	vf, vb, offset := d.vf, d.vb, d.offset
+
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	// The searches meet within (rows+cols+1)/2 edits
+	for e := 0; ; e++ {
+		for k := -e; k <= e; k += 2 {
+			var p int
+			if k == -e || k != e && vf[offset+k-1] < vf[offset+k+1] {
+				p = vf[offset+k+1] // down from diagonal k+1: an insertion
+			} else {
+				p = vf[offset+k-1] + 1 // right from diagonal k-1: a deletion
+			}
+
+			q := p - k
+			p0, q0 := p, q
+
+			for p < rows && q < cols && Equal(d.x[i+p], d.y[j+q]) {
+				p++
+				q++
+			}
+
+			vf[offset+k] = p
+
+			// The backward search has taken e-1 edits, on diagonal delta-k when it got there
+			if odd && delta-k >= -(e-1) && delta-k <= e-1 && p+vb[offset+delta-k] >= rows {
+				return i + p0, j + q0, i + p, j + q
+			}
+		}
+
+		for k := -e; k <= e; k += 2 {
+			var p int
+			if k == -e || k != e && vb[offset+k-1] < vb[offset+k+1] {
+				p = vb[offset+k+1]
+			} else {
+				p = vb[offset+k-1] + 1
+			}
+
+			q := p - k
+			p0, q0 := p, q
+
+			for p < rows && q < cols && Equal(d.x[n-1-p], d.y[m-1-q]) {
+				p++
+				q++
+			}
+
+			vb[offset+k] = p
+
+			if !odd && delta-k >= -e && delta-k <= e && p+vf[offset+delta-k] >= rows {
+				return n - p, m - q, n - p0, m - q0
+			}
+		}
+	}
+}
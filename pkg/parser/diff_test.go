@@ -0,0 +1,186 @@
+package parser_test
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{"Equal", `{"a": [1, 2.0], "b": {}}`, `{"b": {}, "a": [1, 2.00]}`, ""},
+		{"Modified, added and removed", `{"port": 80, "host": "a", "tls": true}`, `{"port": 8080, "host": "a", "debug": false}`,
+			"--- a\n+++ b\n" +
+				"@@ /debug @@\n+false\n" +
+				"@@ /port @@\n-80\n+8080\n" +
+				"@@ /tls @@\n-true\n"},
+		{"Nested", `{"server": {"tls": {"cert": "a.pem"}, "names": ["x", "y"]}}`, `{"server": {"tls": {"cert": "b.pem"}, "names": ["x"]}}`,
+			"--- a\n+++ b\n" +
+				"@@ /server/names/1 @@\n-\"y\"\n" +
+				"@@ /server/tls/cert @@\n-\"a.pem\"\n+\"b.pem\"\n"},
+		{"Type changed", `{"a~b": {"x": 1}}`, `{"a~b": [1]}`,
+			"--- a\n+++ b\n" +
+				"@@ /a~0b @@\n-{\n-  \"x\": 1\n-}\n+[\n+  1\n+]\n"},
+		{"Added container", `[]`, `[{"id": 1}]`,
+			"--- a\n+++ b\n" +
+				"@@ /0 @@\n+{\n+  \"id\": 1\n+}\n"},
+		{"Root", `{}`, `[]`,
+			"--- a\n+++ b\n" +
+				"@@ (root) @@\n-{}\n+[]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.UnifiedDiff(mustParse(t, tt.a), mustParse(t, tt.b)); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+
+	if got := parser.UnifiedDiff(nil, nil); got != "" {
+		t.Errorf("Expected no diff between two nil documents, got %q", got)
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []string
+	}{
+		{"Equal", `{"a": [1, 2.0]}`, `{"a": [1, 2.00]}`, []string{}},
+		{"Modified, added and removed", `{"port": 80, "host": "a", "tls": true}`, `{"port": 8080, "host": "a", "debug": false}`,
+			[]string{"/debug", "/port", "/tls"}},
+		{"Nested", `{"s": {"tls": {"cert": "a"}, "names": ["x", "y"]}}`, `{"s": {"tls": {"cert": "b"}, "names": ["x"]}}`,
+			[]string{"/s/names/1", "/s/tls/cert"}},
+		{"Added subtree", `{"a": 1}`, `{"a": 1, "b/c": {"d": [1, 2]}}`, []string{"/b~1c"}},
+		{"Type changed", `{"a": {"x": 1}}`, `{"a": [1]}`, []string{"/a"}},
+		{"Root", `{}`, `[]`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.ChangedPaths(mustParse(t, tt.a), mustParse(t, tt.b)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestArrayDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string // one letter per operation: k, i or d
+	}{
+		{"Equal", `[1, 2, 3]`, `[1, 2.0, 3]`, "kkk"},
+		{"Insert and delete", `[1, 2, 3, 4]`, `[1, 3, 5, 4]`, "kdkik"},
+		{"Replace deletes first", `[1, "x", 2]`, `[1, "y", 2]`, "kdik"},
+		{"Nested values", `[{"id": 1}, {"id": 2}]`, `[{"id": 2}, {"id": 3}]`, "dki"},
+		{"Moved element", `["a", "b", "c"]`, `["c", "a", "b"]`, "ikkd"},
+		{"From empty", `[]`, `[1, 2]`, "ii"},
+		{"To empty", `[1, 2]`, `[]`, "dd"},
+		{"Both empty", `[]`, `[]`, ""},
+		{"Longer script", `["a", "b", "c", "a", "b", "b", "a"]`, `["c", "b", "a", "b", "a", "c"]`, ""},
+		{"Interleaved", intsJSON(300, func(i int) int { return i % 7 }), intsJSON(280, func(i int) int { return i % 5 }), ""},
+		// Needs as many rounds as elements, which must not keep a copy of the search per round
+		{"Disjoint", intsJSON(3000, func(i int) int { return i }), intsJSON(3000, func(i int) int { return -1 - i }), ""},
+	}
+
+	letters := map[parser.ArrayOpKind]byte{parser.ArrayKeep: 'k', parser.ArrayInsert: 'i', parser.ArrayDelete: 'd'}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustParse(t, tt.a).(*parser.Array), mustParse(t, tt.b).(*parser.Array)
+			ops := parser.ArrayDiff(a, b)
+
+			var (
+				script  []byte
+				rebuilt []parser.Value
+				edits   int
+			)
+
+			for _, op := range ops {
+				script = append(script, letters[op.Kind])
+
+				switch op.Kind {
+				case parser.ArrayKeep:
+					if op.Value != a.Elements[op.OldIndex] || !parser.Equal(op.Value, b.Elements[op.NewIndex]) {
+						t.Errorf("Unexpected kept element %+v", op)
+					}
+
+					rebuilt = append(rebuilt, b.Elements[op.NewIndex])
+				case parser.ArrayInsert:
+					if op.OldIndex != -1 || op.Value != b.Elements[op.NewIndex] || op.NewIndex != len(rebuilt) {
+						t.Errorf("Unexpected insertion %+v", op)
+					}
+
+					rebuilt = append(rebuilt, op.Value)
+					edits++
+				case parser.ArrayDelete:
+					if op.NewIndex != -1 || op.Value != a.Elements[op.OldIndex] {
+						t.Errorf("Unexpected deletion %+v", op)
+					}
+
+					edits++
+				}
+			}
+
+			if tt.expected != "" && string(script) != tt.expected {
+				t.Errorf("Expected the script %s, got %s", tt.expected, script)
+			}
+
+			if !parser.Equal(&parser.Array{Elements: rebuilt}, b) {
+				t.Errorf("Expected the script to rebuild %s, got %v", tt.b, rebuilt)
+			}
+
+			if minimal := len(a.Elements) + len(b.Elements) - 2*lcsLength(a.Elements, b.Elements); edits != minimal {
+				t.Errorf("Expected %d edits, got %d in %s", minimal, edits, script)
+			}
+		})
+	}
+
+	if ops := parser.ArrayDiff(nil, mustParse(t, `[1]`).(*parser.Array)); len(ops) != 1 || ops[0].Kind != parser.ArrayInsert {
+		t.Errorf("Expected a nil array to count as empty, got %+v", ops)
+	}
+}
+
+// intsJSON returns a JSON array of n numbers, f(i) at index i.
+func intsJSON(n int, f func(i int) int) string {
+	elements := make([]string, n)
+	for i := range elements {
+		elements[i] = strconv.Itoa(f(i))
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b, by dynamic
+// programming.
+func lcsLength(a, b []parser.Value) int {
+	prev := make([]int, len(b)+1)
+
+	for i := range a {
+		cur := make([]int, len(b)+1)
+
+		for j := range b {
+			switch {
+			case parser.Equal(a[i], b[j]):
+				cur[j+1] = prev[j] + 1
+			default:
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+)
+
+// Equal reports whether a and b represent the same JSON value. Objects are equal when they have
+// the same keys with equal values, regardless of order, and arrays when they have equal elements
+// in the same order. Numbers are compared by value, so 1, 1.0 and 1e0 are equal, and integers are
+// compared exactly even beyond the precision of a float64.
+func Equal(a, b Value) bool {
+	return equalAt(a, b, nil, nil, equalNumbers)
+}
+
+// EqualIgnoring reports whether a and b are equal like Equal, except that the values at the
+// given dotted paths are not compared, and may be missing from either side. This is meant for
+// snapshot tests against live services, where fields such as "meta.requestId" change on every
+// call. A "*" segment matches any array index or object key, as in "items.*.id", and indexes may
+// also be written in brackets, as in "items[0].id".
+func EqualIgnoring(a, b Value, ignorePaths []string) bool {
+	patterns := make([][]string, len(ignorePaths))
+	for i, path := range ignorePaths {
+		patterns[i] = parsePath(path)
+	}
+
+	return equalAt(a, b, nil, patterns, equalNumbers)
+}
+
+// EqualApprox reports whether a and b are equal like Equal, except that two numbers are equal
+// when they differ by at most epsilon, to absorb the floating-point drift of computed values
+// across platforms. All other values, object keys included, are compared strictly. The tolerance
+// is absolute, not relative, and is applied to the float64 values of the numbers, so an integer
+// and a float are compared like two floats, 3 being within 0.01 of 3.001. Integers are compared
+// exactly first, but past 2^53, where float64 no longer holds every integer, two integers that
+// round to the same float64 are then equal for any epsilon, unlike with Equal. For magnitudes
+// where the spacing between float64 values exceeds epsilon, such as 1e20 with an epsilon below
+// 16384, the tolerance absorbs nothing and numbers must match as float64 values. A negative epsilon
+// makes EqualApprox compare numbers like Equal.
+func EqualApprox(a, b Value, epsilon float64) bool {
+	return equalAt(a, b, nil, nil, func(x, y *NumberLiteral) bool {
+		return equalNumbers(x, y) || math.Abs(x.Float-y.Float) <= epsilon
+	})
+}
+
+// EqualUnorderedArray reports whether a and b hold the same elements regardless of their order,
+// comparing them as multisets: every element of a must be Equal to a distinct element of b, and
+// both must have the same length, so [1, 1, 2] equals [2, 1, 1] but not [1, 2, 2]. It is meant for
+// tests against APIs that return items in no particular order. Arrays nested in the elements are
+// still compared in order by Equal. Each element of a is searched for among the unmatched elements
+// of b, so the comparison takes O(n²) time.
+func EqualUnorderedArray(a, b *Array) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if len(a.Elements) != len(b.Elements) {
+		return false
+	}
+
+	matched := make([]bool, len(b.Elements))
+
+	for _, x := range a.Elements {
+		found := false
+
+		for j, y := range b.Elements {
+			if !matched[j] && Equal(x, y) {
+				matched[j], found = true, true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalNumbers reports whether x and y are the same number, comparing integers exactly.
+func equalNumbers(x, y *NumberLiteral) bool {
+	if x.IsInt && y.IsInt {
+		return x.Int == y.Int
+	}
+
+	return x.Float == y.Float
+}
+
+// equalAt compares a and b found at location, skipping any location matched by ignore, and
+// comparing numbers with numbers.
+func equalAt(a, b Value, location []string, ignore [][]string, numbers func(x, y *NumberLiteral) bool) bool {
+	if ignored(location, ignore) {
+		return true
+	}
+
+	switch x := a.(type) {
+	case *Object:
+		y, ok := b.(*Object)
+		if !ok {
+			return false
+		}
+
+		for k, v := range x.Pairs {
+			other, ok := y.Pairs[k]
+			if !ok {
+				if !ignored(append(location, k), ignore) {
+					return false
+				}
+
+				continue
+			}
+
+			if !equalAt(v, other, append(location, k), ignore, numbers) {
+				return false
+			}
+		}
+
+		for k := range y.Pairs {
+			if _, ok := x.Pairs[k]; !ok && !ignored(append(location, k), ignore) {
+				return false
+			}
+		}
+
+		return true
+
+	case *Array:
+		y, ok := b.(*Array)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false
+		}
+
+		for i := range x.Elements {
+			if !equalAt(x.Elements[i], y.Elements[i], append(location, strconv.Itoa(i)), ignore, numbers) {
+				return false
+			}
+		}
+
+		return true
+
+	case *StringLiteral:
+		y, ok := b.(*StringLiteral)
+		return ok && x.Value == y.Value
+
+	case *NumberLiteral:
+		y, ok := b.(*NumberLiteral)
+		return ok && numbers(x, y)
+
+	case *Boolean:
+		y, ok := b.(*Boolean)
+		return ok && x.Value == y.Value
+
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+
+	case *RawMessage:
+		y, ok := b.(*RawMessage)
+		return ok && bytes.Equal(x.Bytes, y.Bytes)
+
+	default:
+		return a == nil && b == nil
+	}
+}
+
+// ignored reports whether location matches one of the ignore patterns.
+func ignored(location []string, ignore [][]string) bool {
+	for _, pattern := range ignore {
+		if matchPath(location, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,165 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"Key order", `{"a": 1, "b": [true, null]}`, `{"b": [true, null], "a": 1}`, true},
+		{"Number forms", `[1, 1.0, 1e0, 25e-1]`, `[1.0, 1, 10e-1, 2.5]`, true},
+		{"Large integers", `[9007199254740993]`, `[9007199254740992]`, false},
+		{"Missing key", `{"a": 1}`, `{"a": 1, "b": 2}`, false},
+		{"Array order", `[1, 2]`, `[2, 1]`, false},
+		{"Type mismatch", `["1"]`, `[1]`, false},
+		{"Nested difference", `{"a": {"b": "x"}}`, `{"a": {"b": "y"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.Equal(mustParse(t, tt.a), mustParse(t, tt.b)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEqualIgnoring(t *testing.T) {
+	a := mustParse(t, `{"meta": {"requestId": "1", "ok": true}, "items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]}`)
+	b := mustParse(t, `{"meta": {"requestId": "2", "ok": true, "took": 5}, "items": [{"id": 7, "name": "a"}, {"id": 8, "name": "b"}]}`)
+
+	tests := []struct {
+		name     string
+		ignore   []string
+		expected bool
+	}{
+		{"Nothing ignored", nil, false},
+		{"Wildcard and missing key", []string{"meta.requestId", "meta.took", "items.*.id"}, true},
+		{"Bracketed wildcard", []string{"meta.requestId", "meta.took", "items[*].id"}, true},
+		{"Single index only", []string{"meta.requestId", "meta.took", "items[0].id"}, false},
+		{"Whole subtree", []string{"meta", "items"}, true},
+		{"Not covering the difference", []string{"meta.requestId", "items.*.id"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.EqualIgnoring(a, b, tt.ignore); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEqualUnorderedArray(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"Same order", `[1, 2, 3]`, `[1, 2, 3]`, true},
+		{"Shuffled", `[{"id": 1}, {"id": 2}, "x"]`, `["x", {"id": 2}, {"id": 1}]`, true},
+		{"Repeated elements", `[1, 1, 2]`, `[2, 1, 1]`, true},
+		{"Different counts", `[1, 1, 2]`, `[1, 2, 2]`, false},
+		{"Different lengths", `[1, 2]`, `[2, 1, 1]`, false},
+		{"Number forms", `[1.0, 2]`, `[2, 1]`, true},
+		{"Nested arrays keep order", `[[1, 2]]`, `[[2, 1]]`, false},
+		{"Empty", `[]`, `[]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustParse(t, tt.a).(*parser.Array), mustParse(t, tt.b).(*parser.Array)
+
+			if got := parser.EqualUnorderedArray(a, b); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+
+			if got := parser.EqualUnorderedArray(b, a); got != tt.expected {
+				t.Errorf("Expected %v with the arguments swapped, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEqualApprox(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		epsilon  float64
+		expected bool
+	}{
+		{"Within epsilon", `{"x": 0.30000000000000004}`, `{"x": 0.3}`, 1e-9, true},
+		{"Beyond epsilon", `[1.5]`, `[1.6]`, 0.01, false},
+		{"Int and float", `[3]`, `[3.001]`, 0.01, true},
+		{"Large magnitude", `[1e20]`, `[100000000000000000001]`, 0.5, true},
+		{"Large integers compare as floats", `[9007199254740993]`, `[9007199254740992]`, 0.5, true},
+		{"Strings stay strict", `["1.0"]`, `["1"]`, 1, false},
+		{"Types stay strict", `[1]`, `[true]`, 1, false},
+		{"Negative epsilon", `[1.0]`, `[1]`, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+
+			if got := parser.EqualApprox(a, b, tt.epsilon); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEqualGo(t *testing.T) {
+	doc := mustParse(t, `{"a": 1, "b": [true, null, "x"], "c": {"d": 2.5}, "e": 9007199254740993}`)
+
+	expected := map[string]any{
+		"a": 1,
+		"b": []any{true, nil, "x"},
+		"c": map[string]float64{"d": 2.5},
+		"e": int64(9007199254740993),
+	}
+
+	if !parser.EqualGo(doc, expected) {
+		t.Errorf("Expected %#v to equal %v", doc, expected)
+	}
+
+	type name string
+
+	tests := []struct {
+		name     string
+		input    string
+		goVal    any
+		expected bool
+	}{
+		{"Int and float", `[1, 1.0, 2]`, []float64{1, 1, 2}, true},
+		{"Unsigned", `[7]`, [1]uint8{7}, true},
+		{"Negative against unsigned", `[-1]`, []uint{math.MaxUint}, false},
+		{"Large integer exactly", `[9007199254740993]`, []int64{9007199254740992}, false},
+		{"json.Number", `[1.50]`, []json.Number{"1.5"}, true},
+		{"Named types", `{"k": "v"}`, map[name]name{"k": "v"}, true},
+		{"Pointer", `{"k": "v"}`, &map[string]string{"k": "v"}, true},
+		{"Nil slice is null", `{"k": null}`, map[string][]int{"k": nil}, true},
+		{"Empty slice is not null", `{"k": null}`, map[string][]int{"k": {}}, false},
+		{"Missing key", `{"k": 1}`, map[string]int{"k": 1, "j": 2}, false},
+		{"Different string", `["x"]`, []string{"y"}, false},
+		{"Type mismatch", `["1"]`, []int{1}, false},
+		{"Parsed value", `[1, 2]`, mustParse(t, `[1.0, 2]`), true},
+		{"Unsupported type", `{"f": 1}`, map[string]func(){"f": nil}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.EqualGo(mustParse(t, tt.input), tt.goVal); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
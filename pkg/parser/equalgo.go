@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+)
+
+// EqualGo reports whether v represents the same JSON value as the Go value goVal, so that tests
+// can assert on a parsed tree with a literal such as map[string]any{"a": 1}. Maps with string keys
+// are compared with objects and slices and arrays with arrays, recursively, strings and booleans by
+// value, and numbers of any Go numeric type by value, so 1, int64(1) and 1.0 all equal the JSON
+// number 1. A json.Number is compared as the number it holds. Like encoding/json, nil, and nil
+// pointers, maps and slices, stand for null, and other pointers for the value they point to. A
+// goVal holding a Value is compared with Equal. Any other Go type never equals anything.
+func EqualGo(v Value, goVal interface{}) bool {
+	if other, ok := goVal.(Value); ok {
+		return Equal(v, other)
+	}
+
+	return equalGo(v, reflect.ValueOf(goVal))
+}
+
+// equalGo compares v with the Go value rv.
+func equalGo(v Value, rv reflect.Value) bool {
+	for rv.IsValid() && (rv.Kind() == reflect.Interface || rv.Kind() == reflect.Ptr) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || isNilGo(rv) {
+		_, ok := v.(*Null)
+		return ok
+	}
+
+	if rv.Type() == reflect.TypeOf(json.Number("")) {
+		n, ok := v.(*NumberLiteral)
+		return ok && Equal(n, NewNumberLiteral(Token{Type: TokenNumber, Literal: rv.String()}))
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String || rv.Len() != len(val.Pairs) {
+			return false
+		}
+
+		for k, child := range val.Pairs {
+			elem := rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()))
+			if !elem.IsValid() || !equalGo(child, elem) {
+				return false
+			}
+		}
+
+		return true
+
+	case *Array:
+		if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != len(val.Elements) {
+			return false
+		}
+
+		for i, elem := range val.Elements {
+			if !equalGo(elem, rv.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+
+	case *StringLiteral:
+		return rv.Kind() == reflect.String && rv.String() == val.Value
+
+	case *NumberLiteral:
+		return equalGoNumber(val, rv)
+
+	case *Boolean:
+		return rv.Kind() == reflect.Bool && rv.Bool() == val.Value
+
+	default:
+		return false
+	}
+}
+
+// isNilGo reports whether rv is a nil pointer, interface, map or slice.
+func isNilGo(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// equalGoNumber compares the number n with the Go number rv, exactly when both are integers.
+func equalGoNumber(n *NumberLiteral, rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n.IsInt {
+			return n.Int == rv.Int()
+		}
+
+		return n.Float == float64(rv.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 && n.IsInt {
+			return n.Int == int64(u)
+		}
+
+		return !n.IsInt && n.Float == float64(rv.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return n.Float == rv.Float()
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrTimeout is wrapped by the ParseError returned when parsing takes longer than Timeout.
+var ErrTimeout = errors.New("parse timeout exceeded")
+
+// ParseError describes a problem found while parsing a JSON document. It records the position of
+// the token that caused the failure so callers can point users at the exact location of the error.
+type ParseError struct {
+	// Line is the line number where the error occurred (1-based index).
+	Line int
+	// Column is the column number where the error occurred.
+	Column int
+	// Message is a human-readable description of the error.
+	Message string
+	// Path is the JSON Pointer of the value being parsed when the error occurred, such as
+	// /items/3/price, or the empty string at the top level. Errors in a container's own syntax,
+	// like a missing closing bracket, carry the path of the container.
+	Path string
+	// err is the error wrapped by the ParseError, if any. See Unwrap.
+	err error
+}
+
+// Error implements the error interface, prefixing the message with the error position.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Line %d, Column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Unwrap returns the error behind the ParseError, such as ErrTimeout or an *EncodingError, or nil
+// for a syntax error.
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+// EncodingError is wrapped by the ParseError returned for input that is not UTF-8 JSON text, so
+// that callers can tell it apart from a syntax error with errors.As and, for instance, answer an
+// HTTP request with an "unsupported encoding" message. It reports input in UTF-16 or UTF-32, a
+// byte order mark, at the start of the input or further on, and bytes that are not valid UTF-8
+// outside of strings. Invalid UTF-8 within strings is accepted and decoded as U+FFFD, like in
+// encoding/json. ParseBytesAny accepts every encoding that DetectEncoding reports.
+type EncodingError struct {
+	// Detected is the encoding found, named like DetectEncoding does: that of the input, or
+	// EncodingUTF8BOM for a byte order mark found after its start. It is the empty string for
+	// bytes that are not valid UTF-8.
+	Detected string
+	// Expected is the encoding the parser reads, EncodingUTF8.
+	Expected string
+	// Offset is the byte offset in the input of the problem, zero when it concerns the encoding
+	// of the whole input.
+	Offset int
+	// message describes the problem.
+	message string
+}
+
+// Error implements the error interface.
+func (e *EncodingError) Error() string {
+	return e.message
+}
+
+// NumberError describes a number reported by CheckNumbers.
+type NumberError struct {
+	// Path is the JSON Pointer of the number, or the empty string for the root.
+	Path string
+	// Number is the offending number.
+	Number *NumberLiteral
+	// Message describes the problem, such as "is not finite".
+	Message string
+}
+
+// Error implements the error interface, naming the number and its location.
+func (e *NumberError) Error() string {
+	literal := e.Number.Value
+	if literal == "" {
+		literal = strconv.FormatFloat(e.Number.Float, 'g', -1, 64)
+	}
+
+	return fmt.Sprintf("number %s at %q %s", literal, e.Path, e.Message)
+}
+
+// CoercionError describes a value reported by ParseTyped.
+type CoercionError struct {
+	// Path is the JSON Pointer of the value, or the empty string for the root.
+	Path string
+	// Value is the value as found in the document.
+	Value Value
+	// Type is the type the schema declares, such as "integer" or "string or null".
+	Type string
+	// Coerced reports whether the value was converted to Type, rather than kept as it was.
+	Coerced bool
+}
+
+// Error implements the error interface, naming the value, its location and what became of it.
+func (e *CoercionError) Error() string {
+	found := string(outlineType(e.Value))
+
+	switch val := e.Value.(type) {
+	case *StringLiteral:
+		found += " " + strconv.Quote(val.Value)
+	case *NumberLiteral:
+		if val.Value != "" {
+			found += " " + val.Value
+		} else {
+			found += " " + strconv.FormatFloat(val.Float, 'g', -1, 64)
+		}
+	case *Boolean:
+		found += " " + strconv.FormatBool(val.Value)
+	}
+
+	if e.Coerced {
+		return fmt.Sprintf("%s at %q was coerced to %s", found, e.Path, e.Type)
+	}
+
+	return fmt.Sprintf("%s at %q cannot be coerced to %s", found, e.Path, e.Type)
+}
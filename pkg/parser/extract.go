@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Extract returns a copy of the value identified by the JSON Pointer (RFC 6901) pointer in the
+// tree rooted at root, as a standalone document that shares no nodes with root, so that a large
+// document, such as a config, can be split into one document per section and each edited on its
+// own. The copy keeps the positions, comments and metadata of its nodes. It returns an error when
+// the pointer is malformed or does not resolve.
+func Extract(root Value, pointer string) (Value, error) {
+	v, ok := ResolvePointer(root, pointer)
+	if !ok {
+		return nil, fmt.Errorf("pointer %q does not resolve to a value", pointer)
+	}
+
+	return clone(v), nil
+}
+
+// clone returns a deep copy of the tree rooted at v. The recorded source of PreserveFormatting is
+// not copied, since it describes the original nodes.
+func clone(v Value) Value {
+	switch val := v.(type) {
+	case *Object:
+		result := &Object{Token: val.Token, Pairs: make(map[string]Value, len(val.Pairs))}
+		result.nodeInfo = cloneInfo(&val.nodeInfo)
+
+		for k, child := range val.Pairs {
+			result.Pairs[k] = clone(child)
+		}
+
+		return result
+
+	case *Array:
+		result := &Array{Token: val.Token, Elements: make([]Value, 0, len(val.Elements))}
+		result.nodeInfo = cloneInfo(&val.nodeInfo)
+
+		for _, elem := range val.Elements {
+			result.Elements = append(result.Elements, clone(elem))
+		}
+
+		return result
+
+	case *StringLiteral:
+		return &StringLiteral{nodeInfo: cloneInfo(&val.nodeInfo), Token: val.Token, Value: val.Value}
+
+	case *NumberLiteral:
+		copied := *val
+		copied.nodeInfo = cloneInfo(&val.nodeInfo)
+
+		return &copied
+
+	case *Boolean:
+		return &Boolean{nodeInfo: cloneInfo(&val.nodeInfo), Token: val.Token, Value: val.Value}
+
+	case *Null:
+		return &Null{nodeInfo: cloneInfo(&val.nodeInfo), Token: val.Token}
+
+	case *RawMessage:
+		copied := *val
+		copied.nodeInfo = cloneInfo(&val.nodeInfo)
+		copied.Bytes = slices.Clone(val.Bytes)
+
+		return &copied
+
+	default:
+		return v
+	}
+}
+
+// cloneInfo copies the comments and metadata of a node for clone.
+func cloneInfo(n *nodeInfo) nodeInfo {
+	return nodeInfo{
+		leadingComments:  slices.Clone(n.leadingComments),
+		trailingComments: slices.Clone(n.trailingComments),
+		meta:             n.meta,
+	}
+}
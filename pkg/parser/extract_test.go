@@ -0,0 +1,57 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestExtract(t *testing.T) {
+	root := mustParse(t, `{"db": {"host": "localhost", "ports": [5432, 5433]}, "cache": {"ttl": 60}}`)
+
+	tests := []struct {
+		name     string
+		pointer  string
+		expected string
+	}{
+		{"Section", "/db", `{"host": "localhost", "ports": [5432, 5433]}`},
+		{"Nested array", "/db/ports", `[5432, 5433]`},
+		{"Whole document", "", `{"db": {"host": "localhost", "ports": [5432, 5433]}, "cache": {"ttl": 60}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Extract(root, tt.pointer)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !parser.Equal(got, mustParse(t, tt.expected)) {
+				t.Errorf("Expected %s, got %#v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Independent of the parent", func(t *testing.T) {
+		got, err := parser.Extract(root, "/db")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got.(*parser.Object).Pairs["host"].(*parser.StringLiteral).Value = "db.internal"
+		got.(*parser.Object).Pairs["ports"].(*parser.Array).Elements = nil
+
+		original, _ := parser.ResolvePointer(root, "/db")
+		if !parser.Equal(original, mustParse(t, `{"host": "localhost", "ports": [5432, 5433]}`)) {
+			t.Errorf("Expected the parent to be unchanged, got %#v", original)
+		}
+	})
+
+	for _, pointer := range []string{"/missing", "/db/ports/2", "db"} {
+		t.Run("Unresolved "+pointer, func(t *testing.T) {
+			if _, err := parser.Extract(root, pointer); err == nil {
+				t.Errorf("Expected an error for %q, got nil", pointer)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package parser
+
+// ParseFields parses a JSON object but only retains the requested top-level keys. The values of
+// all other keys are lexed and validated but no AST is built for them, which saves allocations on
+// wide objects. Requested keys that are absent from the input are simply missing from the result.
+func ParseFields(input string, keys ...string) (*Object, error) {
+	p := NewParser(NewLexer(input))
+	p.start()
+
+	if !p.reserveDocument() {
+		return nil, p.errors[0]
+	}
+
+	defer p.releaseDocument()
+
+	if p.currentToken.Type != TokenBraceOpen {
+		p.addError("expected {, got %s", p.currentToken.Type)
+		return nil, p.errors[0]
+	}
+
+	p.fields = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		p.fields[key] = struct{}{}
+	}
+
+	value := p.parseContainer()
+	if len(p.errors) > 0 {
+		return nil, p.errors[0]
+	}
+
+	return value.(*Object), nil
+}
+
+// skipKeyValuePair validates a key-value pair without building its value.
+// It reports whether the pair was well-formed.
+func (p *Parser) skipKeyValuePair() bool {
+	return p.skipKey() && p.skipValue()
+}
+
+// skipKey validates the key at the current token and the colon after it, leaving the parser on
+// the token that starts the value. It reports whether both were well-formed.
+func (p *Parser) skipKey() bool {
+	if p.emptyKey() {
+		return false
+	}
+
+	if p.peekToken.Type != TokenColon {
+		if !p.endOfInput(p.peekToken) {
+			p.addErrorAt(p.peekToken, "expected : after key %q, got %s", p.currentToken.Literal, p.peekToken.Type)
+		}
+
+		return false
+	}
+
+	p.nextToken() // move past key
+	p.nextToken() // move past colon
+
+	return p.valueAfterColon()
+}
+
+// skipValue validates the current value without building its AST. Like parseValue, it leaves the
+// parser positioned on the last token of the value. It reports whether the value was well-formed.
+// Containers are tracked on an explicit stack rather than by recursion, so deep nesting cannot
+// overflow the goroutine stack, and they count toward MaxDepth like parsed ones.
+func (p *Parser) skipValue() bool {
+	base := len(p.skipped)
+	defer func() {
+		p.depth -= len(p.skipped) - base
+		p.skipped = p.skipped[:base]
+	}()
+
+	for {
+		// The current token starts a value
+		first := false
+
+		switch p.currentToken.Type {
+		case TokenBraceOpen, TokenBracketOpen:
+			if !p.enter() {
+				return false
+			}
+
+			p.skipped = append(p.skipped, p.currentToken)
+			first = true
+		default:
+			if p.parseValue() == nil {
+				return false
+			}
+		}
+
+		// Move to the next value, closing the containers that end first
+		for {
+			if len(p.skipped) == base {
+				return true
+			}
+
+			next, ok := p.skipStep(first)
+			if !ok {
+				return false
+			}
+
+			if next {
+				break
+			}
+
+			first = false
+		}
+	}
+}
+
+// skipStep moves to the next element or member of the innermost container being skipped, which
+// has none before it when first is set. It reports next when the parser is left on the token
+// that starts a value, or closes the container when it is complete. It reports ok false when the
+// container is malformed.
+func (p *Parser) skipStep(first bool) (next, ok bool) {
+	object := p.skipped[len(p.skipped)-1].Type == TokenBraceOpen
+
+	closing := TokenBracketClose
+	if object {
+		closing = TokenBraceClose
+	}
+
+	if p.peekToken.Type == closing {
+		p.nextToken() // move to the closing token
+		p.leave()
+		p.skipped = p.skipped[:len(p.skipped)-1]
+
+		return false, true
+	}
+
+	if !first {
+		if p.peekToken.Type != TokenComma {
+			if !p.endOfInput(p.peekToken) {
+				p.expectedComma(p.peekToken, object)
+			}
+
+			return false, false
+		}
+
+		p.nextToken() // move to comma
+
+		// Check for trailing comma
+		if p.peekToken.Type == closing {
+			p.addError("unexpected , before %s", closing)
+			return false, false
+		}
+	}
+
+	p.nextToken() // move past the opening token or comma
+
+	if !object {
+		return true, true
+	}
+
+	if p.currentToken.Type != TokenString {
+		if !p.endOfInput(p.currentToken) {
+			p.expectedKey()
+		}
+
+		return false, false
+	}
+
+	ok = p.skipKey()
+
+	return ok, ok
+}
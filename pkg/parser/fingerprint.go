@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// fingerprintSize is the number of bytes of the SHA-256 digest kept by Fingerprint.
+const fingerprintSize = 16
+
+// Fingerprint returns a short, stable identifier of the value rooted at v, for use as a map key or
+// deduplication token: the first 16 bytes of the SHA-256 digest of its canonical serialization,
+// written as 32 lowercase hexadecimal digits. Values that are Equal except for integers beyond the
+// precision of a float64, whatever their key order, number form or comments, have the same
+// fingerprint, and it does not change across runs or versions of Go.
+//
+// Distinct values collide with a probability of about n²/2¹²⁹ among n fingerprints, which is
+// below 10⁻²⁰ for a billion records, so a match can be treated as equality in practice. A tree
+// that cannot be marshaled, such as one holding a number built with an invalid literal, has no
+// canonical form and gets the empty string.
+func Fingerprint(v Value) string {
+	b, err := MarshalWith(v, MarshalOptions{NormalizeNumbers: true, InvalidUTF8: InvalidUTF8Keep, NoEscapeHTML: true})
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:fingerprintSize])
+}
@@ -0,0 +1,50 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := parser.Fingerprint(mustParse(t, `{"id": 1, "tags": ["a", "b"], "price": 1.50}`))
+
+	if len(base) != 32 {
+		t.Fatalf("Expected 32 hex digits, got %q", base)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		same  bool
+	}{
+		{"Key order and spacing", `{"tags":["a","b"],"price":1.50,"id":1}`, true},
+		{"Number form", `{"id": 1.0, "tags": ["a", "b"], "price": 15e-1}`, true},
+		{"Escapes", `{"id": 1, "tags": ["\u0061", "b"], "price": 1.5}`, true},
+		{"Element order", `{"id": 1, "tags": ["b", "a"], "price": 1.5}`, false},
+		{"Different value", `{"id": 2, "tags": ["a", "b"], "price": 1.5}`, false},
+		{"Type change", `{"id": "1", "tags": ["a", "b"], "price": 1.5}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parser.Fingerprint(mustParse(t, tt.input))
+			if (got == base) != tt.same {
+				t.Errorf("Expected same fingerprint: %v, got %s and %s", tt.same, base, got)
+			}
+		})
+	}
+
+	t.Run("Stable", func(t *testing.T) {
+		const expected = "44136fa355b3678a1146ad16f7e8649e"
+		if got := parser.Fingerprint(mustParse(t, `{}`)); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("Invalid number", func(t *testing.T) {
+		if got := parser.Fingerprint(&parser.NumberLiteral{Value: "1.2.3"}); got != "" {
+			t.Errorf("Expected an empty fingerprint, got %s", got)
+		}
+	})
+}
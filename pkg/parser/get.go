@@ -0,0 +1,90 @@
+package parser
+
+// lookupPath returns the value at the dotted path below root, in the syntax of parsePath. Array
+// indexes must be written without a sign or leading zeros. It reports false when root is nil or
+// a segment is missing, out of range, or applied to a scalar.
+func lookupPath(root Value, path string) (Value, bool) {
+	if root == nil {
+		return nil, false
+	}
+
+	return resolveTokens(root, parsePath(path))
+}
+
+// GetOr returns the value at the dotted path below root, such as "server.ports[0]", or def when
+// there is no value there: when root is nil, or when a key is missing, an index is out of range
+// or a segment goes through a string, number, boolean or null. A JSON null found at the path is
+// returned as is. The empty path denotes root itself. An empty segment denotes the empty key, so
+// "a." looks up the key "" in a, and "[]" looks it up in root.
+func GetOr(root Value, path string, def Value) Value {
+	if v, ok := lookupPath(root, path); ok {
+		return v
+	}
+
+	return def
+}
+
+// GetStringOr returns the string at the dotted path below root, or def when there is no value
+// there or the value is not a string. See GetOr for the path syntax.
+func GetStringOr(root Value, path, def string) string {
+	if v, ok := lookupPath(root, path); ok {
+		if s, ok := v.(*StringLiteral); ok {
+			return s.Value
+		}
+	}
+
+	return def
+}
+
+// GetIntOr returns the integer at the dotted path below root, or def when there is no value
+// there or the value is not a number that fits in an int64. Numbers with a fraction or exponent
+// such as 1.5 or 1e3 are not integers. See GetOr for the path syntax.
+func GetIntOr(root Value, path string, def int64) int64 {
+	if v, ok := lookupPath(root, path); ok {
+		if n, ok := v.(*NumberLiteral); ok && n.IsValidNumber() && n.IsInt {
+			return n.Int
+		}
+	}
+
+	return def
+}
+
+// GetFloatOr returns the number at the dotted path below root as a float64, or def when there is
+// no value there or the value is not a number. See GetOr for the path syntax.
+func GetFloatOr(root Value, path string, def float64) float64 {
+	if v, ok := lookupPath(root, path); ok {
+		if n, ok := v.(*NumberLiteral); ok && n.IsValidNumber() {
+			return n.Float
+		}
+	}
+
+	return def
+}
+
+// GetBoolOr returns the boolean at the dotted path below root, or def when there is no value
+// there or the value is not a boolean. See GetOr for the path syntax.
+func GetBoolOr(root Value, path string, def bool) bool {
+	if v, ok := lookupPath(root, path); ok {
+		if b, ok := v.(*Boolean); ok {
+			return b.Value
+		}
+	}
+
+	return def
+}
+
+// RequirePaths returns the dotted paths among paths that have no value below v, in the order they
+// were given, as a cheap precondition check before processing a document, such as
+// RequirePaths(req, "user.id", "auth.token"). A JSON null counts as present. The result is empty
+// when every path is present. See GetOr for the path syntax.
+func RequirePaths(v Value, paths ...string) []string {
+	var missing []string
+
+	for _, path := range paths {
+		if _, ok := lookupPath(v, path); !ok {
+			missing = append(missing, path)
+		}
+	}
+
+	return missing
+}
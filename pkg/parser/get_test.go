@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestGetOr(t *testing.T) {
+	config := mustParse(t, `{
+		"server": {"host": "example.com", "ports": [80, 443], "timeout": 2.5, "tls": true},
+		"debug": null
+	}`)
+	def := &parser.StringLiteral{Value: "default"}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"Nested key", "server.host", `"example.com"`},
+		{"Bracketed index", "server.ports[1]", "443"},
+		{"Dotted index", "server.ports.0", "80"},
+		{"Null", "debug", "null"},
+		{"Missing key", "server.user", `"default"`},
+		{"Index out of range", "server.ports[2]", `"default"`},
+		{"Through a scalar", "server.host.name", `"default"`},
+		{"Key on an array", "server.ports.first", `"default"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Marshal(parser.GetOr(config, tt.path, def))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Typed", func(t *testing.T) {
+		if got := parser.GetStringOr(config, "server.host", "localhost"); got != "example.com" {
+			t.Errorf("Expected example.com, got %s", got)
+		}
+
+		if got := parser.GetStringOr(config, "server.tls", "localhost"); got != "localhost" {
+			t.Errorf("Expected the default for a boolean, got %s", got)
+		}
+
+		if got := parser.GetIntOr(config, "server.ports[0]", 8080); got != 80 {
+			t.Errorf("Expected 80, got %d", got)
+		}
+
+		if got := parser.GetIntOr(config, "server.timeout", 30); got != 30 {
+			t.Errorf("Expected the default for a fraction, got %d", got)
+		}
+
+		if got := parser.GetFloatOr(config, "server.timeout", 30); got != 2.5 {
+			t.Errorf("Expected 2.5, got %v", got)
+		}
+
+		if got := parser.GetFloatOr(config, "server.ports[1]", 0); got != 443 {
+			t.Errorf("Expected 443, got %v", got)
+		}
+
+		if got := parser.GetBoolOr(config, "server.tls", false); !got {
+			t.Error("Expected true, got false")
+		}
+
+		if got := parser.GetBoolOr(config, "debug", true); !got {
+			t.Error("Expected the default for null, got false")
+		}
+	})
+
+	t.Run("Nil root", func(t *testing.T) {
+		if got := parser.GetOr(nil, "", def); got != def {
+			t.Errorf("Expected the default, got %v", got)
+		}
+
+		if got := parser.GetIntOr(nil, "a.b", 7); got != 7 {
+			t.Errorf("Expected 7, got %d", got)
+		}
+	})
+}
+
+func TestRequirePaths(t *testing.T) {
+	doc := mustParse(t, `{"user": {"id": 7, "roles": ["admin"]}, "auth": {"token": null}}`)
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{"All present", []string{"user.id", "user.roles[0]", "auth.token"}, nil},
+		{"Missing in order", []string{"user.name", "user.id", "auth.scope", "user.roles[1]"}, []string{"user.name", "auth.scope", "user.roles[1]"}},
+		{"Through a scalar", []string{"user.id.value"}, []string{"user.id.value"}},
+		{"No paths", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.RequirePaths(doc, tt.paths...); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
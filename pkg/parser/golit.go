@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoLiteral returns Go source for an expression that builds a tree Equal to v, for pasting
+// fixtures into tests. The expression uses the exported node types and NewNumberLiteral,
+// qualified with the package name parser, and is formatted with go/format, one member or element
+// per line with the keys of objects sorted, so it can be assigned to a parser.Value as is. Strings and keys are written as Go string literals with strconv.Quote, so any content,
+// invalid UTF-8 included, round-trips exactly. Numbers keep their original literal. Comments and
+// other metadata attached to the nodes are not written.
+func GoLiteral(v Value) string {
+	var b strings.Builder
+
+	writeGoLiteral(&b, v, 0)
+
+	// go/format aligns the values of the members that follow each other
+	const prefix = "package p\n\nvar v = "
+
+	formatted, err := format.Source([]byte(prefix + b.String() + "\n"))
+	if err != nil {
+		return b.String() // not expected, as the expression is valid Go
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(string(formatted), prefix), "\n")
+}
+
+// writeGoLiteral writes the Go expression for v, found at the given nesting depth, to b.
+func writeGoLiteral(b *strings.Builder, v Value, depth int) {
+	indent := strings.Repeat("\t", depth+1)
+
+	switch val := v.(type) {
+	case *Object:
+		if len(val.Pairs) == 0 {
+			b.WriteString("&parser.Object{Pairs: map[string]parser.Value{}}")
+			return
+		}
+
+		keys := make([]string, 0, len(val.Pairs))
+		for k := range val.Pairs {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		b.WriteString("&parser.Object{Pairs: map[string]parser.Value{\n")
+
+		for _, k := range keys {
+			b.WriteString(indent)
+			b.WriteString(strconv.Quote(k))
+			b.WriteString(": ")
+			writeGoLiteral(b, val.Pairs[k], depth+1)
+			b.WriteString(",\n")
+		}
+
+		b.WriteString(indent[1:])
+		b.WriteString("}}")
+
+	case *Array:
+		if len(val.Elements) == 0 {
+			b.WriteString("&parser.Array{Elements: []parser.Value{}}")
+			return
+		}
+
+		b.WriteString("&parser.Array{Elements: []parser.Value{\n")
+
+		for _, elem := range val.Elements {
+			b.WriteString(indent)
+			writeGoLiteral(b, elem, depth+1)
+			b.WriteString(",\n")
+		}
+
+		b.WriteString(indent[1:])
+		b.WriteString("}}")
+
+	case *StringLiteral:
+		b.WriteString("&parser.StringLiteral{Value: ")
+		b.WriteString(strconv.Quote(val.Value))
+		b.WriteString("}")
+
+	case *NumberLiteral:
+		b.WriteString("parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: ")
+		b.WriteString(strconv.Quote(numberLiteral(val)))
+		b.WriteString("})")
+
+	case *Boolean:
+		b.WriteString("&parser.Boolean{Value: ")
+		b.WriteString(strconv.FormatBool(val.Value))
+		b.WriteString("}")
+
+	case *RawMessage:
+		b.WriteString("&parser.RawMessage{Bytes: []byte(")
+		b.WriteString(strconv.Quote(string(val.Bytes)))
+		b.WriteString(")}")
+
+	default:
+		b.WriteString("parser.NewNull()")
+	}
+}
+
+// numberLiteral returns the literal of n, or the text Marshal renders it as when it has none.
+func numberLiteral(n *NumberLiteral) string {
+	if n.Value != "" {
+		return n.Value
+	}
+
+	if n.IsInt {
+		return strconv.FormatInt(n.Int, 10)
+	}
+
+	s, err := formatCanonicalNumber(n.Float)
+	if err != nil {
+		return strconv.FormatFloat(n.Float, 'g', -1, 64)
+	}
+
+	return s
+}
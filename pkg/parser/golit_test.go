@@ -0,0 +1,79 @@
+package parser_test
+
+import (
+	"go/format"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestGoLiteral(t *testing.T) {
+	t.Run("Output", func(t *testing.T) {
+		value := mustParse(t, `{"b": [1.50, true], "a": "x\"y", "e": {}, "n": null}`)
+		got := parser.GoLiteral(value)
+
+		expected := `&parser.Object{Pairs: map[string]parser.Value{
+	"a": &parser.StringLiteral{Value: "x\"y"},
+	"b": &parser.Array{Elements: []parser.Value{
+		parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: "1.50"}),
+		&parser.Boolean{Value: true},
+	}},
+	"e": &parser.Object{Pairs: map[string]parser.Value{}},
+	"n": parser.NewNull(),
+}}`
+		if got != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+
+		// The expected output pasted as code
+		built := &parser.Object{Pairs: map[string]parser.Value{
+			"a": &parser.StringLiteral{Value: "x\"y"},
+			"b": &parser.Array{Elements: []parser.Value{
+				parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: "1.50"}),
+				&parser.Boolean{Value: true},
+			}},
+			"e": &parser.Object{Pairs: map[string]parser.Value{}},
+			"n": parser.NewNull(),
+		}}
+		if !parser.Equal(built, value) {
+			t.Errorf("Expected the pasted output to build an equal tree, got %v", built)
+		}
+	})
+
+	t.Run("Gofmt layout", func(t *testing.T) {
+		inputs := []string{
+			`[{"k\n\t": ["é", "\u0000", "a\\b"], "deep": [[[]]]}, -1e-7, 123456789012, false]`,
+			`{"a": 1, "bbb": 2}`, // values aligned after keys of different lengths
+		}
+
+		for _, input := range inputs {
+			src := "package p\n\nvar v = " + parser.GoLiteral(mustParse(t, input)) + "\n"
+
+			formatted, err := format.Source([]byte(src))
+			if err != nil {
+				t.Fatalf("Expected valid Go source, got %v:\n%s", err, src)
+			}
+
+			if string(formatted) != src {
+				t.Errorf("Expected gofmt layout, got:\n%s\nformatted as:\n%s", src, formatted)
+			}
+		}
+	})
+
+	t.Run("Constructed values", func(t *testing.T) {
+		value := &parser.Array{Elements: []parser.Value{
+			&parser.NumberLiteral{Int: 42, Float: 42, IsInt: true, IsValid: true},
+			&parser.NumberLiteral{Float: 0.5, IsValid: true},
+			&parser.RawMessage{Bytes: []byte(`{"a":1}`)},
+		}}
+
+		expected := `&parser.Array{Elements: []parser.Value{
+	parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: "42"}),
+	parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: "0.5"}),
+	&parser.RawMessage{Bytes: []byte("{\"a\":1}")},
+}}`
+		if got := parser.GoLiteral(value); got != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// Interner returns a canonical instance of a string, so that equal strings seen many times share
+// a single backing allocation.
+type Interner interface {
+	// Intern returns a string equal to s, reusing a previously seen instance when there is one.
+	// s may reference memory that the caller reuses later, such as the input of ParseBytes, so
+	// an implementation that keeps s must keep a copy of it, as strings.Clone makes.
+	Intern(s string) string
+}
+
+// MapInterner is an Interner backed by a map. It is safe for concurrent use and can be shared
+// across any number of parses, so the keys of a large dataset are stored once however many
+// documents they appear in. The zero value is ready to use.
+type MapInterner struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+// NewMapInterner creates an empty MapInterner.
+func NewMapInterner() *MapInterner {
+	return &MapInterner{strings: make(map[string]string)}
+}
+
+// Intern returns the stored instance of s, storing a copy of s first if it has not been seen
+// before.
+func (in *MapInterner) Intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if interned, ok := in.strings[s]; ok {
+		return interned
+	}
+
+	if in.strings == nil {
+		in.strings = make(map[string]string)
+	}
+
+	s = strings.Clone(s)
+	in.strings[s] = s
+
+	return s
+}
+
+// Len returns the number of distinct strings stored.
+func (in *MapInterner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return len(in.strings)
+}
+
+// LRUInterner is an Interner that keeps at most a fixed number of strings, evicting the least
+// recently used one when full. It bounds the memory of the table for long-running processes whose
+// strings change over time, at the cost of occasionally storing a string again after it was
+// evicted. It is safe for concurrent use.
+type LRUInterner struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Most recently used first
+	strings  map[string]*list.Element
+}
+
+// NewLRUInterner creates an empty LRUInterner holding at most capacity strings. A capacity of
+// zero or less is treated as one.
+func NewLRUInterner(capacity int) *LRUInterner {
+	return &LRUInterner{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		strings:  make(map[string]*list.Element),
+	}
+}
+
+// Intern returns the stored instance of s, storing a copy of s first if it is not in the table.
+func (in *LRUInterner) Intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if elem, ok := in.strings[s]; ok {
+		in.order.MoveToFront(elem)
+		return elem.Value.(string)
+	}
+
+	if in.order.Len() >= in.capacity {
+		oldest := in.order.Back()
+		in.order.Remove(oldest)
+		delete(in.strings, oldest.Value.(string))
+	}
+
+	s = strings.Clone(s)
+	in.strings[s] = in.order.PushFront(s)
+
+	return s
+}
+
+// Len returns the number of strings stored.
+func (in *LRUInterner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.order.Len()
+}
@@ -0,0 +1,267 @@
+package parser_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+// parseInterned parses input with the given key interner.
+func parseInterned(t testing.TB, input string, interner parser.Interner) *parser.Array {
+	t.Helper()
+
+	p := parser.NewParser(parser.NewLexer(input))
+	p.KeyInterner = interner
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	return value.(*parser.Array)
+}
+
+// keyData returns a pointer to the backing storage of the key of a single-entry object.
+func keyData(v parser.Value) *byte {
+	for k := range v.(*parser.Object).Pairs {
+		return unsafe.StringData(k)
+	}
+
+	return nil
+}
+
+func TestKeyInterner(t *testing.T) {
+	interner := parser.NewMapInterner()
+
+	first := parseInterned(t, `[{"name": 1}, {"name": 2}]`, interner)
+	second := parseInterned(t, `[{"name": 3}]`, interner)
+
+	if interner.Len() != 1 {
+		t.Errorf("Expected 1 interned key, got %d", interner.Len())
+	}
+
+	shared := keyData(first.Elements[0])
+	if keyData(first.Elements[1]) != shared || keyData(second.Elements[0]) != shared {
+		t.Error("Expected repeated keys to share backing storage across parses")
+	}
+
+	var zero parser.MapInterner
+	if got := zero.Intern("key"); got != "key" {
+		t.Errorf("Expected zero MapInterner to be usable, got %q", got)
+	}
+}
+
+// TestInternerParseBytes checks that interned strings do not alias the input of ParseBytes, which
+// the caller may overwrite once the tree that used it is gone.
+func TestInternerParseBytes(t *testing.T) {
+	interners := []struct {
+		name     string
+		interner parser.Interner
+	}{
+		{"Map", parser.NewMapInterner()},
+		{"LRU", parser.NewLRUInterner(16)},
+	}
+
+	for _, tt := range interners {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []parser.Option{parser.WithKeyInterner(tt.interner), parser.WithInternValues()}
+
+			buf1 := []byte(`{"name": "ana", "role": "admin"}`)
+			if _, err := parser.ParseBytes(buf1, opts...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			second, err := parser.ParseBytes([]byte(`{"name": "ana", "role": "admin"}`), opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for i := range buf1 {
+				buf1[i] = 'x'
+			}
+
+			data, err := parser.Marshal(second)
+			if expected := `{"name":"ana","role":"admin"}`; err != nil || string(data) != expected {
+				t.Errorf("Expected %s, got %s, %v", expected, data, err)
+			}
+		})
+	}
+}
+
+// stringData returns a pointer to the backing storage of the string value of v.
+func stringData(v parser.Value) *byte {
+	return unsafe.StringData(v.(*parser.StringLiteral).Value)
+}
+
+func TestInternValues(t *testing.T) {
+	input := `[{"status": "active"}, {"status": "active"}, {"status": "deleted"}, "status"]`
+
+	value, err := parser.Parse(input, parser.WithInternValues())
+	if err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	elements := value.(*parser.Array).Elements
+	first := elements[0].(*parser.Object).Pairs["status"]
+	second := elements[1].(*parser.Object).Pairs["status"]
+	deleted := elements[2].(*parser.Object).Pairs["status"]
+
+	if first.String() != "active" || deleted.String() != "deleted" || elements[3].String() != "status" {
+		t.Fatalf("Expected values to be preserved, got %s", input)
+	}
+
+	if stringData(first) != stringData(second) {
+		t.Error("Expected repeated values to share backing storage")
+	}
+
+	interner := parser.NewMapInterner()
+
+	if _, err := parser.Parse(input, parser.WithKeyInterner(interner), parser.WithInternValues()); err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	if interner.Len() != 3 {
+		t.Errorf("Expected keys and values to share the table, got %d entries", interner.Len())
+	}
+}
+
+func TestLRUInterner(t *testing.T) {
+	interner := parser.NewLRUInterner(2)
+
+	a := interner.Intern(strings.Clone("a"))
+	b := interner.Intern("b")
+	interner.Intern("a") // a is now the most recently used
+	interner.Intern("c") // evicts b
+
+	if interner.Len() != 2 {
+		t.Errorf("Expected the table to stay at its capacity, got %d", interner.Len())
+	}
+
+	if got := interner.Intern(strings.Clone("a")); unsafe.StringData(got) != unsafe.StringData(a) {
+		t.Error("Expected a to survive eviction and be reused")
+	}
+
+	if got := interner.Intern("b"); unsafe.StringData(got) == unsafe.StringData(b) {
+		t.Error("Expected b to have been evicted and stored again")
+	}
+}
+
+// recordsDataset builds an array of n records sharing the same keys.
+func recordsDataset(n int) string {
+	var b strings.Builder
+
+	b.WriteString("[")
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		fmt.Fprintf(&b, `{"identifier": %d, "description": "row", "created_at_timestamp": %d, "is_active_record": true}`, i, i)
+	}
+
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// statusDataset builds an array of n records whose string values come from a small set of
+// statuses.
+func statusDataset(n int) string {
+	statuses := []string{"active", "pending", "suspended", "deleted"}
+
+	var b strings.Builder
+
+	b.WriteString("[")
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		fmt.Fprintf(&b, `{"id": %d, "status": %q, "plan": %q}`, i, statuses[i%len(statuses)], statuses[i%3])
+	}
+
+	b.WriteString("]")
+
+	return b.String()
+}
+
+func BenchmarkInternValues(b *testing.B) {
+	input := statusDataset(10000)
+
+	benchmarks := []struct {
+		name string
+		opts []parser.Option
+	}{
+		{"Without interning", nil},
+		{"With interning", []parser.Option{parser.WithInternValues()}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var (
+				before, after runtime.MemStats
+				result        parser.Value
+				err           error
+			)
+
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if result, err = parser.Parse(input, bm.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			// Memory still held by the last tree parsed
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "retained-B")
+			runtime.KeepAlive(result)
+		})
+	}
+}
+
+func BenchmarkKeyInterner(b *testing.B) {
+	input := recordsDataset(10000)
+
+	benchmarks := []struct {
+		name     string
+		interner func() parser.Interner
+	}{
+		{"Without interning", func() parser.Interner { return nil }},
+		{"With interning", func() parser.Interner { return parser.NewMapInterner() }},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var (
+				before, after runtime.MemStats
+				result        *parser.Array
+			)
+
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				result = parseInterned(b, input, bm.interner())
+			}
+
+			// Memory still held by the last tree parsed
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "retained-B")
+			runtime.KeepAlive(result)
+		})
+	}
+}
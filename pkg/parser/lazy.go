@@ -0,0 +1,72 @@
+package parser
+
+import "fmt"
+
+// Resolve returns the value stored under key, parsing it first if it was deferred by LazyKeys.
+// The parsed value replaces the RawMessage in o, so later calls return it without parsing again.
+// When the deferred text fails to parse, Resolve returns the *ParseError, whose position is
+// relative to the start of the deferred value, and leaves the RawMessage in place. Members that
+// were not deferred are returned as is. Resolve fails when o holds no member named key.
+func (o *Object) Resolve(key string) (Value, error) {
+	v, ok := o.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("no member %q", key)
+	}
+
+	raw, ok := v.(*RawMessage)
+	if !ok || raw.deferred == nil {
+		return v, nil
+	}
+
+	value, err := parseDeferred(raw.Bytes, *raw.deferred)
+	if err != nil {
+		return nil, err
+	}
+
+	copyInfo(value, raw)
+	o.Pairs[key] = value
+
+	return value, nil
+}
+
+// deferredOptions returns the options that a value deferred by LazyKeys is resolved with.
+func (p *Parser) deferredOptions() *ParserOptions {
+	opts := p.ParserOptions
+	opts.RawPaths, opts.LazyKeys = nil, nil
+	opts.OnValue, opts.StringValidator, opts.Arena, opts.ShouldDescend = nil, nil, nil, nil
+	opts.PreserveFormatting = false
+
+	return &opts
+}
+
+// parseDeferred parses src, which must hold exactly one JSON value of any kind, with opts.
+func parseDeferred(src []byte, opts ParserOptions) (Value, error) {
+	p := NewParser(NewLexer(src))
+	p.ParserOptions = opts
+	p.start()
+
+	if !p.reserveDocument() {
+		return nil, p.errors[0]
+	}
+
+	defer p.releaseDocument()
+
+	value := p.parseAny()
+	if !p.failed() && p.peekToken.Type != TokenEOF {
+		p.addErrorAt(p.peekToken, "unexpected %s after the value", p.peekToken.Type)
+	}
+
+	if p.failed() {
+		return nil, p.errors[0]
+	}
+
+	return value, nil
+}
+
+// copyInfo moves the comments attached to a deferred value over to its parsed value.
+func copyInfo(value Value, from *RawMessage) {
+	if node, ok := value.(interface{ info() *nodeInfo }); ok {
+		node.info().leadingComments = from.leadingComments
+		node.info().trailingComments = from.trailingComments
+	}
+}
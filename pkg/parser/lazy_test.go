@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestResolve(t *testing.T) {
+	input := `{"id": 1, "body": {"n": [1, 2]}, "items": [{"body": "x"}]}`
+
+	t.Run("Deferred", func(t *testing.T) {
+		root, err := parser.Parse(input, parser.WithLazyKeys("body"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		obj := root.(*parser.Object)
+		if _, ok := obj.Pairs["body"].(*parser.RawMessage); !ok {
+			t.Fatalf("Expected body to be deferred, got %T", obj.Pairs["body"])
+		}
+
+		nested := obj.Pairs["items"].(*parser.Array).Elements[0].(*parser.Object)
+		if _, ok := nested.Pairs["body"].(*parser.RawMessage); !ok {
+			t.Errorf("Expected the nested body to be deferred, got %T", nested.Pairs["body"])
+		}
+
+		out, err := parser.Marshal(root)
+		if err != nil || !strings.Contains(string(out), `"body":{"n":[1,2]}`) {
+			t.Errorf("Expected the deferred body, got %s (%v)", out, err)
+		}
+
+		body, err := obj.Resolve("body")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !parser.Equal(body, mustParse(t, `{"n": [1, 2]}`)) {
+			t.Errorf("Expected the parsed body, got %v", body)
+		}
+
+		if obj.Pairs["body"] != body {
+			t.Error("Expected the parsed body to replace the RawMessage")
+		}
+
+		if again, err := obj.Resolve("body"); err != nil || again != body {
+			t.Errorf("Expected the same value on the second call, got %v (%v)", again, err)
+		}
+
+		if s, err := nested.Resolve("body"); err != nil || s.(*parser.StringLiteral).Value != "x" {
+			t.Errorf("Expected the nested scalar, got %v (%v)", s, err)
+		}
+	})
+
+	t.Run("Marshaled unresolved", func(t *testing.T) {
+		// An unresolved member accepted under lenient options can still be marshaled
+		root, err := parser.Parse(`{"body": {"n": [1, /* two */ 2]}}`, parser.WithLazyKeys("body"), parser.WithComments())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		out, err := parser.MarshalIndent(root, "", "  ")
+		if expected := "{\n  \"body\": {\n    \"n\": [\n      1,\n      2\n    ]\n  }\n}"; err != nil || string(out) != expected {
+			t.Errorf("Expected %s, got %s (%v)", expected, out, err)
+		}
+
+		if _, ok := root.(*parser.Object).Pairs["body"].(*parser.RawMessage); !ok {
+			t.Error("Expected body to stay unresolved")
+		}
+	})
+
+	t.Run("Not deferred", func(t *testing.T) {
+		obj := mustParse(t, input).(*parser.Object)
+
+		if v, err := obj.Resolve("id"); err != nil || v != obj.Pairs["id"] {
+			t.Errorf("Expected the member as is, got %v (%v)", v, err)
+		}
+
+		if _, err := obj.Resolve("missing"); err == nil {
+			t.Error("Expected an error for a missing member")
+		}
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		root, err := parser.Parse(`{"body": {"a": 1, "a": 2}}`, parser.WithLazyKeys("body"), parser.WithNormalizedKeys())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		obj := root.(*parser.Object)
+
+		_, err = obj.Resolve("body")
+
+		var parseErr *parser.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("Expected a ParseError, got %v", err)
+		}
+
+		if _, ok := obj.Pairs["body"].(*parser.RawMessage); !ok {
+			t.Errorf("Expected the RawMessage to be kept, got %T", obj.Pairs["body"])
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		if _, err := parser.Parse(`{"body": [1,]}`, parser.WithLazyKeys("body")); err == nil {
+			t.Error("Expected a malformed deferred value to fail the parse")
+		}
+	})
+}
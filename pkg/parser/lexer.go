@@ -2,10 +2,26 @@ package parser
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
+// unterminatedString is the literal of the illegal token read for a string that the input ends
+// before closing.
+const unterminatedString = "Unterminated string"
+
+// tooManyEscapes is the literal of the illegal token read for a string holding more escape
+// sequences than maxStringEscapes.
+const tooManyEscapes = "Too many escape sequences"
+
+// controlCharacter is the literal of the illegal token read for a string holding a control
+// character that is not escaped, under StrictRFC8259.
+const controlCharacter = "Unescaped control character"
+
 // Lexer is responsible for converting JSON input into a sequence of tokens.
 // It maintains the current input string and tracks the positions of characters being read.
 type Lexer struct {
@@ -27,6 +43,48 @@ type Lexer struct {
 	buffer []byte
 	// Flag to indicate if the lexer is in streaming mode.
 	isStreaming bool
+	// Flag to indicate if // and /* */ comments are skipped like whitespace.
+	allowComments bool
+	// Flag to indicate if skipped comments are recorded on the following token.
+	retainComments bool
+	// The comments read since the last token.
+	comments []string
+	// Flag to indicate that a block comment was still open at the end of the input.
+	unterminatedComment bool
+	// Flag to indicate if barewords other than true, false and null are read as word tokens.
+	barewords bool
+	// Flag to indicate if numbers may be padded with leading zeros.
+	leadingZeros bool
+	// The error, other than io.EOF, that ended reading from the input reader.
+	readErr error
+	// The number of bytes dropped from the start of the input by discardRead.
+	offset int
+	// The maximum number of bytes of input that may be read for a document, or zero for no limit.
+	maxDocumentSize int
+	// The offset at which the current document starts, from which maxDocumentSize counts.
+	documentStart int
+	// The error recorded when the input outgrew maxDocumentSize.
+	sizeErr *ParseError
+	// The error recorded for the first sign that the input is not UTF-8. See EncodingError.
+	encodingErr *ParseError
+	// Flag to indicate if the input is kept and token offsets recorded, so that the source text
+	// of any span can be retrieved with text.
+	preserve bool
+	// The input dropped by discardRead when streaming with preserve set.
+	kept strings.Builder
+	// The offset of the token being read.
+	tokenStart int
+	// The size of the input in bytes, or -1 when it is not known in advance.
+	total int
+	// The buffer strings with escapes are decoded into, reused from one string to the next.
+	scratch []byte
+	// The maximum number of escape sequences a string may hold, or zero for no limit.
+	maxStringEscapes int
+	// The number of escape sequences read in the current string.
+	escapes int
+	// Flag to indicate if strings must not hold unescaped control characters or invalid UTF-8,
+	// and if NUL bytes are read as illegal tokens rather than as the end of the input.
+	strict bool
 }
 
 // NewLexer creates a new Lexer instance for the given input string.
@@ -41,58 +99,196 @@ func NewLexer(input interface{}) *Lexer {
 	case string:
 		l.input = v
 		l.isStreaming = false
+		l.total = len(v)
 	case []byte:
 		l.input = string(v)
 		l.isStreaming = false
+		l.total = len(v)
 	case io.Reader:
 		l.reader = bufio.NewReader(v)
 		l.isStreaming = true
-		l.readChunk()
+		l.total = readerSize(v)
 	default:
 		panic("invalid input type")
 	}
 
 	l.readChar()
+	l.checkEncoding()
 
 	return l
 }
 
-// readChunk reads the next chunk of data from the input reader.
-func (l *Lexer) readChunk() {
-	if !l.isStreaming || l.reader == nil {
-		return
+// readerSize returns the number of bytes left to read from r when r can tell, as strings.Reader,
+// bytes.Reader and regular files can, or -1.
+func readerSize(r io.Reader) int {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return v.Len()
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+
+		return int(info.Size() - offset)
+	default:
+		return -1
+	}
+}
+
+// consumed returns the number of bytes of input read so far.
+func (l *Lexer) consumed() int {
+	return l.offset + l.position
+}
+
+// newLexerAt creates a Lexer for the input string that starts reading at the byte offset
+// start, which must be the first byte of a character, counting lines and columns from there as
+// if the input before it had been read.
+func newLexerAt(input string, start int) *Lexer {
+	line, column := positionAt(input, start)
+
+	return newLexerFrom(input, start, line, column)
+}
+
+// newLexerFrom is like newLexerAt, with the line and column of the byte at start already known.
+func newLexerFrom(input string, start, line, column int) *Lexer {
+	l := &Lexer{
+		input:        input,
+		readPosition: start,
+		line:         line,
+		column:       column - 1, // readChar moves to the column of the first character
+		total:        len(input),
 	}
 
-	remaining := len(l.input) - l.position
-	if remaining > 0 {
-		copy(l.buffer, l.input[l.position:])
-		l.position += copy(l.buffer[remaining:], l.input[l.position:])
+	l.readChar()
 
-		return
+	return l
+}
+
+// readChunk appends the next chunk of data from the input reader to the input. It reports
+// whether any data was read.
+func (l *Lexer) readChunk() bool {
+	if !l.isStreaming || l.reader == nil {
+		return false
 	}
 
-	l.input = ""
+	// The input holds the token being read. When it outgrows the buffer, double the buffer so
+	// that appending stays linear in the length of long tokens.
+	if len(l.input) >= len(l.buffer) {
+		l.buffer = make([]byte, 2*len(l.buffer))
+	}
+
+	for {
+		n, err := l.reader.Read(l.buffer)
+		if n > 0 {
+			l.input += string(l.buffer[:n])
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				l.readErr = err
+			}
+
+			l.reader = nil
+
+			return n > 0
+		}
 
-	n, err := l.reader.Read(l.buffer[remaining:])
-	if n > 0 {
-		l.input += string(l.buffer[remaining : remaining+n])
+		if n > 0 {
+			return true
+		}
 	}
+}
 
-	if err != nil && err != io.EOF {
+// discardRead drops the input before the current character when streaming, so that only the
+// token being read is kept in memory. It must only be called at a token boundary.
+func (l *Lexer) discardRead() {
+	if !l.isStreaming || l.position == 0 {
 		return
 	}
 
+	if l.preserve {
+		l.kept.WriteString(l.input[:l.position])
+	}
+
+	l.input = l.input[l.position:]
+	l.offset += l.position
+	l.readPosition -= l.position
 	l.position = 0
-	l.readPosition = 0
+}
+
+// text returns the source text between the byte offsets start and end, which must lie before
+// the current character. It is only available when preserve is set.
+func (l *Lexer) text(start, end int) string {
+	if !l.isStreaming {
+		return l.input[start:end]
+	}
+
+	l.discardRead() // Move everything before the current character to kept
+
+	return l.kept.String()[start:end]
+}
+
+// source returns the input read so far. It is only complete when preserve is set or the input
+// is not streamed.
+func (l *Lexer) source() string {
+	if !l.isStreaming {
+		return l.input
+	}
+
+	return l.kept.String() + l.input
+}
+
+// fill reads from the input reader until a complete character is available at the read
+// position or the input is exhausted.
+func (l *Lexer) fill() {
+	for l.isStreaming && !utf8.FullRuneInString(l.input[l.readPosition:]) {
+		if !l.readChunk() {
+			return
+		}
+	}
 }
 
 // NextToken retrieves the next token from the input, skipping any whitespace.
 func (l *Lexer) NextToken() Token {
+	t := l.nextToken()
+
+	if l.preserve {
+		t.start, t.end = l.tokenStart, l.offset+l.position
+	}
+
+	if len(l.comments) > 0 {
+		t.Comments = l.comments
+		l.comments = nil
+	}
+
+	return t
+}
+
+// nextToken scans the next token without attaching the comments that precede it.
+func (l *Lexer) nextToken() Token {
 	l.skipWhitespace()
+	l.discardRead()
+
+	l.tokenStart = l.offset + l.position
 
 	currentLine := l.line
 	currentColumn := l.column
 
+	if l.unterminatedComment {
+		l.unterminatedComment = false
+		return Token{Type: TokenIllegal, Literal: "Unterminated comment", Line: currentLine, Column: currentColumn}
+	}
+
+	if l.barewords && l.startsBareword() {
+		return l.readBareword(currentLine, currentColumn)
+	}
+
 	var t Token
 
 	switch l.ch {
@@ -120,8 +316,15 @@ func (l *Lexer) NextToken() Token {
 		return l.readNull(currentLine, currentColumn)
 	case 0:
 		t = Token{Type: TokenEOF, Literal: "", Line: currentLine, Column: currentColumn}
+
+		// A NUL byte was read, rather than the end of the input
+		if l.strict && l.position < l.readPosition {
+			t.Type, t.Literal = TokenIllegal, "\x00"
+		}
 	default:
 		t = Token{Type: TokenIllegal, Literal: string(l.ch), Line: currentLine, Column: currentColumn}
+
+		l.checkCharacter()
 	}
 
 	l.readChar()
@@ -131,15 +334,13 @@ func (l *Lexer) NextToken() Token {
 
 // readChar advances the position in the input string and updates the current character.
 func (l *Lexer) readChar() {
+	l.fill()
+
 	if l.readPosition >= len(l.input) {
-		if l.isStreaming {
-			l.readChunk()
-		}
+		l.position = l.readPosition
+		l.ch = 0 // EOF
 
-		if l.readPosition >= len(l.input) {
-			l.ch = 0 // EOF
-			return
-		}
+		return
 	}
 
 	var size int
@@ -152,6 +353,11 @@ func (l *Lexer) readChar() {
 		l.ch = 0 // EOF
 	}
 
+	if l.maxDocumentSize > 0 && l.offset+l.position-l.documentStart >= l.maxDocumentSize {
+		l.truncate()
+		return
+	}
+
 	if l.ch == '\n' {
 		l.line++
 		l.column = 0
@@ -160,43 +366,242 @@ func (l *Lexer) readChar() {
 	}
 }
 
-// skipWhitespace skips over any whitespace characters.
+// truncate ends the input at the current character because the document outgrew
+// maxDocumentSize, and records the error. Reading stops, so memory use stays bounded by the limit.
+func (l *Lexer) truncate() {
+	l.sizeErr = &ParseError{
+		Line:    l.line,
+		Column:  l.column,
+		Message: fmt.Sprintf("document exceeds maximum size of %d bytes", l.maxDocumentSize),
+	}
+	l.input = l.input[:l.position]
+	l.readPosition = l.position
+	l.reader = nil
+	l.ch = 0
+}
+
+// skipWhitespace skips over any whitespace characters, and over comments when they are allowed.
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for {
+		for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+			l.readChar()
+		}
+
+		if !l.allowComments || l.ch != '/' || !l.skipComment() {
+			return
+		}
+	}
+}
+
+// skipComment skips a // line comment or a /* */ block comment starting at the current
+// character. It reports whether a comment was found.
+func (l *Lexer) skipComment() bool {
+	start := l.position
+
+	switch l.peekChar() {
+	case '/':
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+	case '*':
+		l.readChar()
+		l.readChar()
+
+		for !(l.ch == '*' && l.peekChar() == '/') {
+			if l.ch == 0 {
+				l.unterminatedComment = true
+				return false
+			}
+
+			l.readChar()
+		}
+
+		l.readChar()
 		l.readChar()
+	default:
+		return false
 	}
+
+	if l.retainComments {
+		l.comments = append(l.comments, strings.TrimRight(l.input[start:l.position], "\r"))
+	}
+
+	return true
 }
 
-// readString reads a string token.
-func (l *Lexer) readString(line, column int) Token {
-	var result []rune
+// peekChar returns the character after the current one without advancing the lexer.
+func (l *Lexer) peekChar() rune {
+	l.fill()
+
+	if l.readPosition >= len(l.input) {
+		return 0
+	}
+
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
 
+	return ch
+}
+
+// readString reads a string token. A string without escapes or invalid UTF-8 is sliced from the
+// input as is, and copied only when streaming, so that it does not keep the whole buffer alive.
+// Other strings are decoded into the scratch buffer, reused from one string to the next, and
+// copied out of it once complete.
+func (l *Lexer) readString(line, column int) Token {
 	l.readChar()
 
+	start := l.position
+	decoded := false
+	l.escapes = 0
+
 	for l.ch != '"' && l.ch != 0 {
-		if l.ch == '\\' {
+		switch {
+		case l.ch == '\\':
+			if !decoded {
+				l.scratch = append(l.scratch[:0], l.input[start:l.position]...)
+				decoded = true
+			}
+
+			if l.escapes++; l.maxStringEscapes > 0 && l.escapes > l.maxStringEscapes {
+				return Token{Type: TokenIllegal, Literal: tooManyEscapes, Line: line, Column: column}
+			}
+
 			l.readChar()
 
 			if l.ch == 0 {
-				return Token{Type: TokenIllegal, Literal: "Unterminated string", Line: line, Column: column}
+				return Token{Type: TokenIllegal, Literal: unterminatedString, Line: line, Column: column}
+			}
+
+			var ok bool
+			if l.scratch, ok = l.readEscape(l.scratch); !ok {
+				return Token{Type: TokenIllegal, Literal: "Invalid escape sequence", Line: line, Column: column}
+			}
+		case l.strict && l.ch < 0x20:
+			return Token{Type: TokenIllegal, Literal: controlCharacter, Line: line, Column: column}
+		case l.ch == utf8.RuneError && l.readPosition-l.position == 1:
+			if l.strict {
+				// The token is at the invalid byte, where checkCharacter records the error
+				l.checkCharacter()
+				return Token{Type: TokenIllegal, Literal: string(l.input[l.position]), Line: l.line, Column: l.column}
+			}
+
+			// Invalid bytes decode to U+FFFD
+			if !decoded {
+				l.scratch = append(l.scratch[:0], l.input[start:l.position]...)
+				decoded = true
 			}
 
-			result = append(result, '\\')
-			result = append(result, l.ch)
-		} else {
-			result = append(result, l.ch)
+			l.scratch = utf8.AppendRune(l.scratch, utf8.RuneError)
+		case decoded:
+			l.scratch = utf8.AppendRune(l.scratch, l.ch)
 		}
 
 		l.readChar()
 	}
 
 	if l.ch == 0 {
-		return Token{Type: TokenIllegal, Literal: "Unterminated string", Line: line, Column: column}
+		return Token{Type: TokenIllegal, Literal: unterminatedString, Line: line, Column: column}
+	}
+
+	var literal string
+
+	switch {
+	case decoded:
+		literal = string(l.scratch)
+	case l.isStreaming:
+		literal = strings.Clone(l.input[start:l.position])
+	default:
+		literal = l.input[start:l.position]
 	}
 
 	l.readChar()
 
-	return Token{Type: TokenString, Literal: string(result), Line: line, Column: column}
+	return Token{Type: TokenString, Literal: literal, Line: line, Column: column}
+}
+
+// escapes maps the characters that may follow a backslash in a string, other than u, to the
+// characters they stand for.
+var escapes = map[rune]rune{
+	'"':  '"',
+	'\\': '\\',
+	'/':  '/',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+}
+
+// readEscape appends the character of the escape sequence whose backslash was just read to
+// result, leaving the lexer on its last character. A \uXXXX escape of a high surrogate followed
+// by one of a low surrogate decodes to a single character, and unpaired surrogates decode to
+// U+FFFD, like in encoding/json. It reports false for an unknown escape or malformed hexadecimal
+// digits.
+func (l *Lexer) readEscape(result []byte) ([]byte, bool) {
+	if l.ch != 'u' {
+		r, ok := escapes[l.ch]
+		return utf8.AppendRune(result, r), ok
+	}
+
+	r, ok := l.readHex4()
+	if !ok {
+		return result, false
+	}
+
+	for {
+		if !utf16.IsSurrogate(r) {
+			return utf8.AppendRune(result, r), true
+		}
+
+		// A high surrogate needs the low surrogate of the next escape, which is left to readString
+		// when it would go over maxStringEscapes
+		if r >= 0xDC00 || l.peekChar() != '\\' || l.maxStringEscapes > 0 && l.escapes >= l.maxStringEscapes {
+			return utf8.AppendRune(result, utf8.RuneError), true
+		}
+
+		l.escapes++
+		l.readChar() // \
+		l.readChar()
+
+		if l.ch != 'u' {
+			return l.readEscape(utf8.AppendRune(result, utf8.RuneError))
+		}
+
+		low, ok := l.readHex4()
+		if !ok {
+			return result, false
+		}
+
+		if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+			return utf8.AppendRune(result, pair), true
+		}
+
+		// The second escape may start a pair of its own
+		result = utf8.AppendRune(result, utf8.RuneError)
+		r = low
+	}
+}
+
+// readHex4 reads the four hexadecimal digits following the u of a \uXXXX escape, leaving the
+// lexer on the last of them.
+func (l *Lexer) readHex4() (rune, bool) {
+	var r rune
+
+	for i := 0; i < 4; i++ {
+		l.readChar()
+
+		switch {
+		case l.ch >= '0' && l.ch <= '9':
+			r = r<<4 | (l.ch - '0')
+		case l.ch >= 'a' && l.ch <= 'f':
+			r = r<<4 | (l.ch - 'a' + 10)
+		case l.ch >= 'A' && l.ch <= 'F':
+			r = r<<4 | (l.ch - 'A' + 10)
+		default:
+			return 0, false
+		}
+	}
+
+	return r, true
 }
 
 // readNumber reads and validates a JSON number token.
@@ -222,6 +627,10 @@ func (l *Lexer) readNumber(line, column int) Token {
 	case l.ch == '0':
 		l.readChar()
 
+		for l.leadingZeros && isDigit(l.ch) {
+			l.readChar()
+		}
+
 		if isDigit(l.ch) {
 			return Token{
 				Type:    TokenIllegal,
@@ -324,6 +733,39 @@ func (l *Lexer) readNull(line, column int) Token {
 	return Token{Type: TokenIllegal, Literal: "Invalid token", Line: line, Column: column}
 }
 
+// startsBareword reports whether a bareword starts at the current character: a letter, or a sign
+// followed by a letter, as in +Infinity.
+func (l *Lexer) startsBareword() bool {
+	return isLetter(l.ch) || ((l.ch == '+' || l.ch == '-') && isLetter(l.peekChar()))
+}
+
+// readBareword reads a bareword made of an optional sign followed by letters, digits and
+// underscores. The standard literals keep their own token types.
+func (l *Lexer) readBareword(line, column int) Token {
+	start := l.position
+
+	if l.ch == '+' || l.ch == '-' {
+		l.readChar()
+	}
+
+	for isLetter(l.ch) || isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+
+	word := l.input[start:l.position]
+
+	switch word {
+	case "true":
+		return Token{Type: TokenTrue, Literal: word, Line: line, Column: column}
+	case "false":
+		return Token{Type: TokenFalse, Literal: word, Line: line, Column: column}
+	case "null":
+		return Token{Type: TokenNull, Literal: word, Line: line, Column: column}
+	default:
+		return Token{Type: TokenWord, Literal: word, Line: line, Column: column}
+	}
+}
+
 // readWord reads a word token (used for true, false, null).
 func (l *Lexer) readWord() string {
 	start := l.position
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LinesWriter writes values as JSON Lines (also known as NDJSON): one compact JSON text per line,
+// each terminated by '\n'. Marshaled text never contains a raw newline, because newlines inside
+// strings are always escaped, so every line holds exactly one self-contained record.
+//
+// Output is buffered; call Flush when done writing, or whenever records must reach the
+// underlying writer. A LinesWriter is not safe for concurrent use.
+type LinesWriter struct {
+	w *bufio.Writer
+}
+
+// NewLinesWriter returns a LinesWriter that writes to w.
+func NewLinesWriter(w io.Writer) *LinesWriter {
+	return &LinesWriter{w: bufio.NewWriter(w)}
+}
+
+// Write marshals v like Marshal and writes it as a single line. The record may stay in the buffer
+// until Flush is called. Once writing to the underlying writer has failed, every later Write and
+// Flush returns the same error.
+func (lw *LinesWriter) Write(v Value) error {
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := lw.w.Write(data); err != nil {
+		return err
+	}
+
+	return lw.w.WriteByte('\n')
+}
+
+// Flush writes any buffered records to the underlying writer.
+func (lw *LinesWriter) Flush() error {
+	return lw.w.Flush()
+}
+
+// TransformStream reads JSON Lines records from r, passes each one to fn, and writes the values
+// returned by fn to w with a LinesWriter, dropping the records for which fn returns false. Only one
+// record is held in memory at a time, so files of any size can be filtered or rewritten record by
+// record. Blank lines are skipped, and each record must be an object or an array, like any
+// document accepted by Parse.
+//
+// Errors parsing or writing a record are returned wrapped with the index of the record, counting
+// from zero, and stop the transformation once the records before it have been flushed to w.
+func TransformStream(r io.Reader, w io.Writer, fn func(Value) (Value, bool)) error {
+	br := bufio.NewReader(r)
+	lw := NewLinesWriter(w)
+
+	for index := 0; ; {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return errors.Join(fmt.Errorf("reading input: %w", err), lw.Flush())
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			if recordErr := transformRecord(lw, line, fn); recordErr != nil {
+				return errors.Join(fmt.Errorf("record %d: %w", index, recordErr), lw.Flush())
+			}
+
+			index++
+		}
+
+		if err != nil {
+			return lw.Flush()
+		}
+	}
+}
+
+// transformRecord parses a single record for TransformStream and writes what fn makes of it.
+func transformRecord(lw *LinesWriter, line []byte, fn func(Value) (Value, bool)) error {
+	v, err := ParseBytes(line)
+	if err != nil {
+		return err
+	}
+
+	v, keep := fn(v)
+	if !keep {
+		return nil
+	}
+
+	return lw.Write(v)
+}
@@ -0,0 +1,157 @@
+package parser_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestLinesWriterRoundTrip(t *testing.T) {
+	records := []string{
+		`{"event":"login","user":"ana"}`,
+		`[1,2,3]`,
+	}
+
+	var buf bytes.Buffer
+
+	lw := parser.NewLinesWriter(&buf)
+
+	for _, r := range records {
+		if err := lw.Write(mustParse(t, r)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	// A string holding a raw newline must still produce a single line
+	note := &parser.Array{Elements: []parser.Value{&parser.StringLiteral{Value: "multi\nline"}}}
+	if err := lw.Write(note); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	records = append(records, `["multi\nline"]`)
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected records to stay buffered until Flush, got %q", buf.String())
+	}
+
+	if err := lw.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+
+	var i int
+
+	for ; scanner.Scan(); i++ {
+		if i >= len(records) {
+			t.Fatalf("Unexpected extra line %q", scanner.Text())
+		}
+
+		if scanner.Text() != records[i] {
+			t.Errorf("Line %d: expected %s, got %s", i+1, records[i], scanner.Text())
+		}
+
+		if !parser.Equal(mustParse(t, scanner.Text()), mustParse(t, records[i])) {
+			t.Errorf("Line %d does not round-trip: %s", i+1, scanner.Text())
+		}
+	}
+
+	if i != len(records) {
+		t.Errorf("Expected %d lines, got %d", len(records), i)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestLinesWriterFlushError(t *testing.T) {
+	lw := parser.NewLinesWriter(failingWriter{})
+
+	if err := lw.Write(mustParse(t, `{"a":1}`)); err != nil {
+		t.Fatalf("Write failed before flushing: %v", err)
+	}
+
+	if err := lw.Flush(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the writer's error from Flush, got %v", err)
+	}
+
+	if err := lw.Write(mustParse(t, `{"a":2}`)); err == nil {
+		t.Error("Expected Write to keep failing after a write error")
+	}
+}
+
+func TestTransformStream(t *testing.T) {
+	const input = `{"level": "info", "msg": "started"}
+{"level": "debug", "msg": "tick"}
+
+{"level": "error", "msg": "failed", "code": 7}
+[1, 2]`
+
+	var out bytes.Buffer
+
+	// Drop debug records and tag the others
+	err := parser.TransformStream(strings.NewReader(input), &out, func(v parser.Value) (parser.Value, bool) {
+		obj, ok := v.(*parser.Object)
+		if !ok {
+			return v, true
+		}
+
+		if level, _ := obj.Pairs["level"].(*parser.StringLiteral); level != nil && level.Value == "debug" {
+			return nil, false
+		}
+
+		obj.Pairs["seen"] = &parser.Boolean{Value: true}
+
+		return obj, true
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"level":"info","msg":"started","seen":true}
+{"code":7,"level":"error","msg":"failed","seen":true}
+[1,2]
+`
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	t.Run("Passthrough", func(t *testing.T) {
+		var out bytes.Buffer
+
+		keep := func(v parser.Value) (parser.Value, bool) { return v, true }
+		input := "{\"id\": 1, \"tags\": [\"a\", \"b\"]}\r\n  {\"id\": 2, \"note\": \"x y\"}  \n[]"
+
+		if err := parser.TransformStream(strings.NewReader(input), &out, keep); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "{\"id\":1,\"tags\":[\"a\",\"b\"]}\n{\"id\":2,\"note\":\"x y\"}\n[]\n"
+		if out.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, out.String())
+		}
+	})
+
+	t.Run("Invalid record", func(t *testing.T) {
+		var out bytes.Buffer
+
+		keep := func(v parser.Value) (parser.Value, bool) { return v, true }
+		input := "{\"a\": 1}\n\n{\"b\": }\n{\"c\": 3}\n"
+
+		err := parser.TransformStream(strings.NewReader(input), &out, keep)
+
+		var parseErr *parser.ParseError
+		if err == nil || !strings.HasPrefix(err.Error(), "record 1: ") || !errors.As(err, &parseErr) {
+			t.Fatalf("Expected a parse error for record 1, got %v", err)
+		}
+
+		if out.String() != "{\"a\":1}\n" {
+			t.Errorf("Expected the records before the error to be written, got %q", out.String())
+		}
+	})
+}
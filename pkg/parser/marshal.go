@@ -0,0 +1,776 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Mode selects what the marshaler does with string values that are not valid UTF-8.
+//
+// The lexer always decodes invalid input bytes to U+FFFD, so trees produced by the parser never
+// hold invalid UTF-8. Such strings only come from trees built or modified in code, for instance
+// by encoding.Marshal from arbitrary Go strings.
+type InvalidUTF8Mode int
+
+const (
+	// InvalidUTF8Replace replaces each invalid byte with U+FFFD. It is the default.
+	InvalidUTF8Replace InvalidUTF8Mode = iota
+	// InvalidUTF8Keep writes invalid bytes unchanged, producing output that is not valid UTF-8.
+	InvalidUTF8Keep
+	// InvalidUTF8Error makes marshaling fail on the first invalid byte.
+	InvalidUTF8Error
+)
+
+// MarshalOptions gathers the settings that control how a tree is serialized back to JSON text.
+//
+// The zero value writes compact output: no indentation or spaces, members in sorted key order,
+// since objects do not record the order in which their keys were inserted, number literals
+// unchanged, HTML characters escaped like the standard library does, and invalid UTF-8 replaced.
+// Marshal and MarshalIndent are presets over MarshalWith.
+type MarshalOptions struct {
+	// NormalizeNumbers re-renders every number in the canonical form defined by RFC 8785, which
+	// is the shortest decimal that round-trips through an IEEE 754 double, switching to exponent
+	// form only for very large or very small magnitudes. For example 1e3 is written as 1000 and
+	// 1.50 as 1.5. When false, the original literal of each number is written unchanged.
+	NormalizeNumbers bool
+
+	// FloatPrecision is the number of digits written after the decimal point by numbers that are
+	// rendered from their Float rather than written as their literal, such as numbers built
+	// without a literal. Integers are always written whole, and rendered numbers that Float holds
+	// exactly as an integer too. As in strconv.FormatFloat, -1 writes the fewest digits that
+	// round-trip, in the form described under NormalizeNumbers, and so does the zero value, so
+	// that 1.0 is written as 1 rather than 1.000000. NormalizeNumbers takes precedence: under it,
+	// every number is written in its canonical form and FloatPrecision is ignored.
+	FloatPrecision int
+
+	// InvalidUTF8 controls how invalid UTF-8 in keys and string values is written. The zero
+	// value replaces it with U+FFFD.
+	InvalidUTF8 InvalidUTF8Mode
+
+	// KeyOrder, when set, reports whether key a must be written before key b, so that object
+	// members can be emitted in a custom order, such as "id" first. Keys it considers equivalent
+	// keep their sorted order. When nil, members are written in sorted key order.
+	KeyOrder func(a, b string) bool
+
+	// Indent, when not empty, puts every object member and array element on its own line,
+	// indented by one copy of Indent per nesting level.
+	Indent string
+
+	// Prefix, when not empty, also makes the output multi-line and begins every line after the
+	// first, before the indentation.
+	Prefix string
+
+	// ColonSpace writes a space after the colon that separates keys from values.
+	ColonSpace bool
+
+	// CommaSpace writes a space after the commas that separate members and elements in
+	// single-line output. Multi-line output always ends lines after commas instead.
+	CommaSpace bool
+
+	// NoEscapeHTML writes <, > and & in keys and string values as they are. By default they are
+	// escaped as \u003c, \u003e and \u0026, so the output can be embedded safely in HTML, along
+	// with U+2028 and U+2029 as under EscapeJSSeparators, as HTML output often ends up in an
+	// inline script, and as encoding/json does.
+	NoEscapeHTML bool
+
+	// EscapeJSSeparators writes U+2028 LINE SEPARATOR and U+2029 PARAGRAPH SEPARATOR in keys and
+	// string values as \u2028 and \u2029. JSON allows them unescaped, but JavaScript engines
+	// before ES2019 end a string literal at them, which breaks JSON embedded in a script element.
+	EscapeJSSeparators bool
+
+	// EscapeSlash writes / in keys and string values as \/, which JSON allows but does not
+	// require, so that a string holding "</script>" cannot end an HTML script element it is
+	// embedded in. The parser decodes \/ back to /, so such strings match lookups by /.
+	EscapeSlash bool
+
+	// ASCIIOnly escapes every non-ASCII character in keys and string values as \uXXXX, using a
+	// UTF-16 surrogate pair for characters outside the Basic Multilingual Plane, so the output is
+	// pure ASCII, like ensure_ascii in Python. When false, non-ASCII characters are written as
+	// UTF-8. Invalid bytes kept under InvalidUTF8Keep are still written unchanged.
+	ASCIIOnly bool
+
+	// Escaper, when set, takes over the escaping of keys and string values: each is written as
+	// the string Escaper returns for it, between the quotes, and HTML escaping,
+	// EscapeJSSeparators, EscapeSlash, ASCIIOnly and InvalidUTF8 no longer apply. The returned
+	// string must be valid JSON string content, with quotes, backslashes and control characters
+	// escaped; it is written unchecked. JSEscaper is an escaper for output embedded in JavaScript.
+	Escaper func(s string) string
+
+	// PreserveFormatting writes every subtree parsed under ParserOptions.PreserveFormatting and
+	// left unmodified as its original source text, byte for byte, including its whitespace and
+	// comments, so that editing a document only reformats the nodes that were changed. A scalar
+	// whose value is changed is written according to the other options. A container whose members
+	// or elements were replaced or modified keeps its source text, with only the text of those
+	// swapped for their serialization; one that gains or loses a member or element is written
+	// according to the other options instead, while its unmodified children keep their source
+	// text. Containers are written in full under Omit and AnnotatePaths. The other options do not
+	// apply to source text, which is written as it was accepted by the parser.
+	PreserveFormatting bool
+
+	// CheckCycles makes MarshalWith fail on a tree holding a container inside itself, which would
+	// otherwise be written forever, rather than loop. It walks the tree once more before writing
+	// it, so it is meant for debugging trees built by hand. Nodes shared by several paths are
+	// allowed; see CheckAcyclic to reject them too.
+	CheckCycles bool
+
+	// TrailingComma writes a comma after the last member of every object and the last element of
+	// every array in multi-line output, as hand-edited JSON5 and JSONC config files often do so
+	// that adding a line does not touch the one before it. The output is then not strict JSON, and
+	// Parse rejects it. Single-line output and empty containers are written without one.
+	TrailingComma bool
+
+	// TrailingNewline ends the output with a single newline, after the document and any trailing
+	// comments, as POSIX text files and most tools expect. The newline is not followed by Prefix.
+	TrailingNewline bool
+
+	// NumbersAsStrings writes every number as a JSON string holding the text it would otherwise
+	// be written as, so {"id": 9007199254740993} becomes {"id":"9007199254740993"}. This is the
+	// usual workaround for JavaScript consumers, which parse numbers as float64 and lose the
+	// precision of integers beyond 2^53. See NumberStringPaths to only convert some numbers.
+	NumbersAsStrings bool
+
+	// NumberStringPaths lists the dotted paths, such as "id" or "items.*.ownerId", of the numbers
+	// to write as strings like NumbersAsStrings does, leaving every other number as a number. A "*"
+	// segment matches any array index or object key, and indexes may also be written in
+	// brackets, as in "items[0].id". The paths are relative to the value being marshaled.
+	NumberStringPaths []string
+
+	// Omit, when set, is called with the JSON Pointer of each member and element, such as
+	// "/items/0", and the value found there; when it returns true the member or element is left
+	// out of the output, along with its comma, as though it were not in the tree. Omitted
+	// containers are not descended into, and the value being marshaled itself is always written.
+	// Subtrees written as their source text under PreserveFormatting are written unfiltered.
+	Omit func(path string, v Value) bool
+
+	// annotate writes a line comment holding the JSON Pointer of each member and element before
+	// it, for AnnotatePaths.
+	annotate bool
+	// preserved holds the values written as their source text under PreserveFormatting.
+	preserved map[Value]bool
+	// numberPaths holds the parsed NumberStringPaths.
+	numberPaths [][]string
+}
+
+// Marshal serializes the tree rooted at v as compact JSON with HTML escaping. Numbers keep their
+// original literal.
+func Marshal(v Value) ([]byte, error) {
+	return MarshalWith(v, MarshalOptions{})
+}
+
+// MarshalIndent serializes the tree rooted at v like Marshal, but with every member and element
+// on its own line, beginning with prefix and indented by one copy of indent per nesting level,
+// and with a space after each colon. MarshalTab and MarshalSpaces are presets over it.
+func MarshalIndent(v Value, prefix, indent string) ([]byte, error) {
+	return MarshalWith(v, MarshalOptions{Prefix: prefix, Indent: indent, ColonSpace: true})
+}
+
+// MarshalTab serializes the tree rooted at v indented with one tab per nesting level.
+func MarshalTab(v Value) ([]byte, error) {
+	return MarshalIndent(v, "", "\t")
+}
+
+// MarshalSpaces serializes the tree rooted at v indented with n spaces per nesting level. When
+// n is zero or less the output stays on a single line.
+func MarshalSpaces(v Value, n int) ([]byte, error) {
+	return MarshalIndent(v, "", strings.Repeat(" ", max(n, 0)))
+}
+
+// CheckSerializable reports whether Marshal can serialize the tree rooted at v faithfully, as a
+// preflight for trees built in code before they are sent. It returns an error naming the JSON
+// Pointer of the first problem found in the order Walk visits values, or nil when there is none.
+// The problems are a container holding itself, a nil value, an invalid number, a number without
+// a literal that is NaN or infinite, a key or string that is not valid UTF-8, which Marshal would
+// replace with U+FFFD by default, a RawMessage that does not hold a single JSON value, and a
+// value of another package that does not implement json.Marshaler or whose MarshalJSON fails.
+func CheckSerializable(v Value) error {
+	if err := checkTree(v, true); err != nil {
+		return err
+	}
+
+	return Walk(v, func(pointer string, v Value) error {
+		var problem string
+
+		switch val := v.(type) {
+		case nil:
+			problem = "nil value"
+		case *Object:
+			for _, k := range val.SortedKeys() {
+				if !utf8.ValidString(k) {
+					return fmt.Errorf("value at %q: invalid UTF-8 in key %q", pointer, k)
+				}
+			}
+		case *StringLiteral:
+			if !utf8.ValidString(val.Value) {
+				problem = fmt.Sprintf("invalid UTF-8 in string %q", val.Value)
+			}
+		case *NumberLiteral:
+			if !val.IsValidNumber() {
+				problem = fmt.Sprintf("invalid number: %q", val.Value)
+			} else if val.Value == "" && !val.IsInt && (math.IsNaN(val.Float) || math.IsInf(val.Float, 0)) {
+				problem = fmt.Sprintf("number %v cannot be represented in JSON", val.Float)
+			}
+		case *RawMessage:
+			if _, err := val.parse(); err != nil {
+				problem = "invalid raw JSON: " + err.Error()
+			}
+		case json.Marshaler:
+			if _, err := marshalerValue(val); err != nil {
+				problem = err.Error()
+			}
+		case *Array, *Boolean, *Null:
+		default:
+			problem = fmt.Sprintf("unknown value type: %T", v)
+		}
+
+		if problem != "" {
+			return fmt.Errorf("value at %q: %s", pointer, problem)
+		}
+
+		return nil
+	})
+}
+
+// encodeBuffer accumulates serialized JSON by appending to a byte slice, so that MarshalWith can
+// return the slice as is, without the copy that converting a strings.Builder would take.
+type encodeBuffer struct {
+	buf []byte
+	// keys holds the keys of the objects being written, outermost first, so that writing an
+	// object does not allocate a slice of its keys.
+	keys []string
+	// location holds the path of the value being written, only tracked for NumberStringPaths,
+	// Omit and AnnotatePaths.
+	location []string
+}
+
+// writeByte appends c to the buffer.
+func (b *encodeBuffer) writeByte(c byte) {
+	b.buf = append(b.buf, c)
+}
+
+// writeString appends s to the buffer.
+func (b *encodeBuffer) writeString(s string) {
+	b.buf = append(b.buf, s...)
+}
+
+// writeRune appends the UTF-8 encoding of r to the buffer.
+func (b *encodeBuffer) writeRune(r rune) {
+	b.buf = utf8.AppendRune(b.buf, r)
+}
+
+// MarshalWith serializes the tree rooted at v using the given options.
+func MarshalWith(v Value, opts MarshalOptions) ([]byte, error) {
+	if opts.CheckCycles {
+		if err := checkTree(v, true); err != nil {
+			return nil, err
+		}
+	}
+
+	b := encodeBuffer{buf: make([]byte, 0, 64)}
+
+	for _, path := range opts.NumberStringPaths {
+		opts.numberPaths = append(opts.numberPaths, parsePath(path))
+	}
+
+	if opts.PreserveFormatting {
+		opts.preserved = make(map[Value]bool)
+		markPreserved(v, opts.preserved)
+	}
+
+	if err := writeValue(&b, v, &opts, 0); err != nil {
+		return nil, err
+	}
+
+	if opts.TrailingNewline {
+		b.writeByte('\n')
+	}
+
+	return b.buf, nil
+}
+
+// writeValue writes the JSON text of v, found at the given nesting depth, to b.
+func writeValue(b *encodeBuffer, v Value, opts *MarshalOptions, depth int) error {
+	if opts.preserved[v] {
+		b.writeString(v.(interface{ info() *nodeInfo }).info().source.text)
+		return nil
+	}
+
+	if opts.PreserveFormatting {
+		if spliced, err := writeSpliced(b, v, opts, depth); spliced {
+			return err
+		}
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		b.writeByte('{')
+
+		// The keys are pushed on the shared stack of b, which nested objects grow and may move
+		start := len(b.keys)
+		b.keys = opts.appendKeys(b.keys, val)
+		n := 0
+
+		for _, k := range b.keys[start:] {
+			if opts.omitted(b, k, -1, val.Pairs[k]) {
+				continue
+			}
+
+			opts.writeSeparator(b, n, depth+1)
+			opts.writeAnnotation(b, k, -1, depth+1)
+			n++
+
+			if err := writeString(b, k, opts); err != nil {
+				return err
+			}
+
+			b.writeByte(':')
+
+			if opts.ColonSpace {
+				b.writeByte(' ')
+			}
+
+			if err := opts.writeChild(b, k, -1, val.Pairs[k], depth+1); err != nil {
+				return err
+			}
+		}
+
+		b.keys = b.keys[:start]
+
+		opts.writeClosing(b, n, depth)
+		b.writeByte('}')
+
+	case *Array:
+		b.writeByte('[')
+
+		n := 0
+
+		for i, elem := range val.Elements {
+			if opts.omitted(b, "", i, elem) {
+				continue
+			}
+
+			opts.writeSeparator(b, n, depth+1)
+			opts.writeAnnotation(b, "", i, depth+1)
+			n++
+
+			if err := opts.writeChild(b, "", i, elem, depth+1); err != nil {
+				return err
+			}
+		}
+
+		opts.writeClosing(b, n, depth)
+		b.writeByte(']')
+
+	case *StringLiteral:
+		return writeString(b, val.Value, opts)
+
+	case *NumberLiteral:
+		if !opts.NumbersAsStrings && !ignored(b.location, opts.numberPaths) {
+			return writeNumber(b, val, opts)
+		}
+
+		b.writeByte('"')
+
+		if err := writeNumber(b, val, opts); err != nil {
+			return err
+		}
+
+		b.writeByte('"')
+
+	case *Boolean:
+		b.writeString(strconv.FormatBool(val.Value))
+
+	case *Null:
+		b.writeString("null")
+
+	case *RawMessage:
+		parsed, err := val.parse()
+		if err != nil {
+			return fmt.Errorf("invalid raw JSON: %w", err)
+		}
+
+		return writeValue(b, parsed, opts, depth)
+
+	case json.Marshaler:
+		marshaled, err := marshalerValue(val)
+		if err != nil {
+			return err
+		}
+
+		return writeValue(b, marshaled, opts, depth)
+
+	default:
+		return fmt.Errorf("unknown value type: %T", v)
+	}
+
+	return nil
+}
+
+// marshalerValue parses the output of the MarshalJSON method of a value of another package, such
+// as one built by NumberFactory, so that it is written like the value it holds.
+func marshalerValue(m json.Marshaler) (Value, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %T: %w", m, err)
+	}
+
+	v, err := (&RawMessage{Bytes: data}).parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON from %T: %w", m, err)
+	}
+
+	return v, nil
+}
+
+// writeChild writes v, the member named key or, when index is not negative, the element at index
+// of the container being written, keeping track of its location when NumberStringPaths, Omit or
+// AnnotatePaths needs it.
+func (opts *MarshalOptions) writeChild(b *encodeBuffer, key string, index int, v Value, depth int) error {
+	if opts.numberPaths == nil && opts.Omit == nil && !opts.annotate {
+		return writeValue(b, v, opts, depth)
+	}
+
+	if index >= 0 {
+		key = strconv.Itoa(index)
+	}
+
+	b.location = append(b.location, key)
+	err := writeValue(b, v, opts, depth)
+	b.location = b.location[:len(b.location)-1]
+
+	return err
+}
+
+// omitted reports whether Omit leaves out v, the member named key or, when index is not negative,
+// the element at index of the container being written.
+func (opts *MarshalOptions) omitted(b *encodeBuffer, key string, index int, v Value) bool {
+	if opts.Omit == nil {
+		return false
+	}
+
+	return opts.Omit(b.pointer(key, index), v)
+}
+
+// writeAnnotation writes the line comment that precedes the member named key or, when index is
+// not negative, the element at index of the container being written under AnnotatePaths, and
+// starts the line of the member or element at the given depth.
+func (opts *MarshalOptions) writeAnnotation(b *encodeBuffer, key string, index int, depth int) {
+	if !opts.annotate {
+		return
+	}
+
+	pointer := b.pointer(key, index)
+	if strings.ContainsAny(pointer, "\n\r") {
+		pointer = strconv.Quote(pointer) // A raw line break would end the comment
+	}
+
+	b.writeString("// ")
+	b.writeString(pointer)
+	opts.writeNewline(b, depth)
+}
+
+// pointer returns the JSON Pointer of the member named key or, when index is not negative, the
+// element at index of the container being written.
+func (b *encodeBuffer) pointer(key string, index int) string {
+	if index >= 0 {
+		key = strconv.Itoa(index)
+	}
+
+	var path strings.Builder
+	for _, segment := range b.location {
+		path.WriteByte('/')
+		path.WriteString(escapePointerToken(segment))
+	}
+
+	path.WriteByte('/')
+	path.WriteString(escapePointerToken(key))
+
+	return path.String()
+}
+
+// multiline reports whether members and elements are written on their own lines.
+func (opts *MarshalOptions) multiline() bool {
+	return opts.Indent != "" || opts.Prefix != ""
+}
+
+// writeNewline starts a new line indented for the given depth.
+func (opts *MarshalOptions) writeNewline(b *encodeBuffer, depth int) {
+	b.writeByte('\n')
+	b.writeString(opts.Prefix)
+
+	for i := 0; i < depth; i++ {
+		b.writeString(opts.Indent)
+	}
+}
+
+// writeSeparator writes what precedes the member or element at index i of a container whose
+// children are at the given depth.
+func (opts *MarshalOptions) writeSeparator(b *encodeBuffer, i, depth int) {
+	if i > 0 {
+		b.writeByte(',')
+
+		if opts.CommaSpace && !opts.multiline() {
+			b.writeByte(' ')
+		}
+	}
+
+	if opts.multiline() {
+		opts.writeNewline(b, depth)
+	}
+}
+
+// writeClosing writes what precedes the closing token of a container with n children at the
+// given depth. Empty containers stay on one line.
+func (opts *MarshalOptions) writeClosing(b *encodeBuffer, n, depth int) {
+	if n > 0 && opts.multiline() {
+		if opts.TrailingComma {
+			b.writeByte(',')
+		}
+
+		opts.writeNewline(b, depth)
+	}
+}
+
+// appendKeys appends the keys of o to dst in the order they are written.
+func (opts *MarshalOptions) appendKeys(dst []string, o *Object) []string {
+	start := len(dst)
+	for k := range o.Pairs {
+		dst = append(dst, k)
+	}
+
+	keys := dst[start:]
+	sort.Strings(keys)
+
+	if opts.KeyOrder != nil {
+		sort.SliceStable(keys, func(i, j int) bool { return opts.KeyOrder(keys[i], keys[j]) })
+	}
+
+	return dst
+}
+
+// writeNumber writes a number either as its original literal or, when it has none or is padded with
+// leading zeros, rendered from its value with FloatPrecision. Under NormalizeNumbers, every number
+// is rendered in its canonical form.
+func writeNumber(b *encodeBuffer, n *NumberLiteral, opts *MarshalOptions) error {
+	s, err := formatNumber(n, opts)
+	if err != nil {
+		return err
+	}
+
+	b.writeString(s)
+
+	return nil
+}
+
+// formatNumber returns the text writeNumber writes for n.
+func formatNumber(n *NumberLiteral, opts *MarshalOptions) (string, error) {
+	if !n.IsValidNumber() {
+		return "", fmt.Errorf("invalid number: %q", n.Value)
+	}
+
+	padded := hasLeadingZeros(n.Value)
+
+	if n.Value != "" && !opts.NormalizeNumbers && !padded {
+		return n.Value, nil
+	}
+
+	if n.IsInt && (n.Value == "" || padded && !opts.NormalizeNumbers) {
+		return strconv.FormatInt(n.Int, 10), nil
+	}
+
+	precision := opts.FloatPrecision
+	if opts.NormalizeNumbers {
+		precision = -1
+	}
+
+	return formatFloat(n.Float, precision)
+}
+
+// hasLeadingZeros reports whether the number literal s is padded with leading zeros, as accepted
+// under ParserOptions.AllowLeadingZeros.
+func hasLeadingZeros(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9'
+}
+
+// formatFloat formats f with precision digits after the decimal point, or in the canonical
+// RFC 8785 form when precision is zero or less or f is an integer.
+func formatFloat(f float64, precision int) (string, error) {
+	if precision <= 0 || f == math.Trunc(f) {
+		return formatCanonicalNumber(f)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("number %v cannot be represented in JSON", f)
+	}
+
+	return strconv.FormatFloat(f, 'f', precision, 64), nil
+}
+
+// formatCanonicalNumber formats f using the ECMAScript Number serialization required by
+// RFC 8785: the shortest round-tripping digits, written in plain decimal notation when the
+// decimal exponent lies in [-6, 21) and in exponent notation otherwise.
+func formatCanonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("number %v cannot be represented in JSON", f)
+	}
+
+	if f == 0 {
+		return "0", nil // Also covers negative zero
+	}
+
+	var b strings.Builder
+
+	if f < 0 {
+		b.WriteByte('-')
+
+		f = -f
+	}
+
+	// Shortest digits in the form d.ddde±x
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+
+	e, err := strconv.Atoi(exp)
+	if err != nil {
+		return "", err
+	}
+
+	// The value is 0.digits × 10^n
+	n := e + 1
+	k := len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		b.WriteString(digits)
+		b.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		b.WriteString(digits[:n])
+		b.WriteByte('.')
+		b.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		b.WriteString("0.")
+		b.WriteString(strings.Repeat("0", -n))
+		b.WriteString(digits)
+	default:
+		b.WriteString(digits[:1])
+
+		if k > 1 {
+			b.WriteByte('.')
+			b.WriteString(digits[1:])
+		}
+
+		b.WriteByte('e')
+
+		if n-1 >= 0 {
+			b.WriteByte('+')
+		}
+
+		b.WriteString(strconv.Itoa(n - 1))
+	}
+
+	return b.String(), nil
+}
+
+// writeString writes s as a quoted JSON string, escaping quotes, backslashes and control
+// characters, with the short escapes \b, \f, \n, \r and \t where JSON defines one. It also
+// escapes HTML characters unless NoEscapeHTML is set, slashes under EscapeSlash, the JavaScript
+// line separators under EscapeJSSeparators and non-ASCII characters under ASCIIOnly. Invalid
+// UTF-8 is handled according to InvalidUTF8. An Escaper replaces all of these.
+func writeString(b *encodeBuffer, s string, opts *MarshalOptions) error {
+	const hex = "0123456789abcdef"
+
+	b.writeByte('"')
+
+	if opts.Escaper != nil {
+		b.writeString(opts.Escaper(s))
+		b.writeByte('"')
+
+		return nil
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\' || (c == '/' && opts.EscapeSlash):
+				b.writeByte('\\')
+				b.writeByte(c)
+			case c == '\b':
+				b.writeString(`\b`)
+			case c == '\f':
+				b.writeString(`\f`)
+			case c == '\n':
+				b.writeString(`\n`)
+			case c == '\r':
+				b.writeString(`\r`)
+			case c == '\t':
+				b.writeString(`\t`)
+			case c < 0x20 || (!opts.NoEscapeHTML && (c == '<' || c == '>' || c == '&')):
+				b.writeString(`\u00`)
+				b.writeByte(hex[c>>4])
+				b.writeByte(hex[c&0xf])
+			default:
+				b.writeByte(c)
+			}
+
+			i++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1 && opts.InvalidUTF8 == InvalidUTF8Keep:
+			b.writeByte(s[i])
+		case r == utf8.RuneError && size == 1 && opts.InvalidUTF8 == InvalidUTF8Error:
+			return fmt.Errorf("invalid UTF-8 in string at byte offset %d", i)
+		case opts.ASCIIOnly, (r == '\u2028' || r == '\u2029') && (opts.EscapeJSSeparators || !opts.NoEscapeHTML):
+			writeRuneEscape(b, r) // Invalid bytes decode to U+FFFD
+		default:
+			b.writeRune(r)
+		}
+
+		i += size
+	}
+
+	b.writeByte('"')
+
+	return nil
+}
+
+// JSEscaper is an Escaper for output embedded in JavaScript source, such as in an inline script
+// element of a server-rendered page. It escapes like the default escaper, HTML characters and the
+// JavaScript line separators included, and replaces invalid UTF-8 with U+FFFD.
+func JSEscaper(s string) string {
+	var b encodeBuffer
+
+	_ = writeString(&b, s, &MarshalOptions{})
+
+	return string(b.buf[1 : len(b.buf)-1])
+}
+
+// writeRuneEscape writes r as a \uXXXX escape, or as a surrogate pair of them for characters
+// outside the Basic Multilingual Plane.
+func writeRuneEscape(b *encodeBuffer, r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		writeRuneEscape(b, r1)
+		writeRuneEscape(b, r2)
+
+		return
+	}
+
+	const hex = "0123456789abcdef"
+
+	b.writeString(`\u`)
+	b.writeByte(hex[r>>12&0xf])
+	b.writeByte(hex[r>>8&0xf])
+	b.writeByte(hex[r>>4&0xf])
+	b.writeByte(hex[r&0xf])
+}
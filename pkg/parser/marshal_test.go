@@ -0,0 +1,729 @@
+package parser_test
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Object in sorted key order", `{"b": 1, "a": [true, false, null]}`, `{"a":[true,false,null],"b":1}`},
+		{"Original number literals", `[1e3, 1.50, -0, 1E-7]`, `[1e3,1.50,-0,1E-7]`},
+		{"Nested containers", `{"a": {"b": [[], {}]}}`, `{"a":{"b":[[],{}]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.Marshal(mustParse(t, tt.input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalEscapesStrings(t *testing.T) {
+	value := &parser.StringLiteral{Value: "quote \" slash \\ tab \t bell \x07 é \xff"}
+
+	data, err := parser.Marshal(value)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `"quote \" slash \\ tab \t bell \u0007 é ` + "\ufffd" + `"`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestMarshalControlCharacters(t *testing.T) {
+	short := map[byte]string{'\b': `\b`, '\f': `\f`, '\n': `\n`, '\r': `\r`, '\t': `\t`}
+
+	for c := byte(0); c < 0x20; c++ {
+		expected := fmt.Sprintf(`"a\u%04xb"`, c)
+		if escape, ok := short[c]; ok {
+			expected = `"a` + escape + `b"`
+		}
+
+		value, err := parser.Parse(`[` + expected + `]`)
+		if err != nil {
+			t.Fatalf("Parse failed for %s: %v", expected, err)
+		}
+
+		if got := value.(*parser.Array).Elements[0].(*parser.StringLiteral).Value; got != "a"+string(rune(c))+"b" {
+			t.Errorf("Expected %s to decode to U+%04X, got %q", expected, c, got)
+		}
+
+		data, err := parser.Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+
+		if string(data) != `[`+expected+`]` {
+			t.Errorf("Expected U+%04X to round-trip as [%s], got %s", c, expected, data)
+		}
+
+		if size := parser.SizeBytes(value); size != len(data) {
+			t.Errorf("Expected SizeBytes %d for U+%04X, got %d", len(data), c, size)
+		}
+
+		obj := parser.WrapInObject("a"+string(rune(c))+"b", value)
+		if got := obj.String(); got != `{`+expected+`:[`+expected+`]}` {
+			t.Errorf("Expected Object.String to escape U+%04X as %s, got %s", c, expected, got)
+		}
+
+		if got := fmt.Sprint(value); got != `[`+expected+`]` {
+			t.Errorf("Expected Array.String to escape U+%04X as %s, got %s", c, expected, got)
+		}
+	}
+
+	// A tree that cannot be marshaled falls back to the String of each member
+	nan := &parser.NumberLiteral{Float: math.NaN(), IsValid: true}
+	array := &parser.Array{Elements: []parser.Value{nan, &parser.Array{Elements: []parser.Value{parser.NewNull()}}}}
+
+	if got := fmt.Sprint(parser.WrapInObject("a", array)); got != `{"a": [NaN, [null]]}` {
+		t.Errorf("Expected the fallback to keep nested elements, got %s", got)
+	}
+}
+
+func TestMarshalNormalizeNumbers(t *testing.T) {
+	// Expected values follow the number serialization of RFC 8785
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1e3", "1000"},
+		{"1.50", "1.5"},
+		{"-0", "0"},
+		{"0.0", "0"},
+		{"123456789", "123456789"},
+		{"1E21", "1e+21"},
+		{"1e20", "100000000000000000000"},
+		{"0.000001", "0.000001"},
+		{"1e-7", "1e-7"},
+		{"-1.5e-10", "-1.5e-10"},
+		{"4.50", "4.5"},
+		{"2e-3", "0.002"},
+		{"9007199254740993", "9007199254740992"},
+		{"1.7976931348623157e308", "1.7976931348623157e+308"},
+		{"5e-324", "5e-324"},
+		{"333333333.33333329", "333333333.3333333"},
+	}
+
+	opts := parser.MarshalOptions{NormalizeNumbers: true}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value := mustParse(t, "["+tt.input+"]")
+
+			data, err := parser.MarshalWith(value, opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if expected := "[" + tt.expected + "]"; string(data) != expected {
+				t.Errorf("Expected %s, got %s", expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalFloatPrecision(t *testing.T) {
+	float := func(f float64) *parser.NumberLiteral { return &parser.NumberLiteral{Float: f, IsValid: true} }
+
+	tests := []struct {
+		name      string
+		value     parser.Value
+		precision int
+		expected  string
+	}{
+		{"Whole float", float(1), 0, "1"},
+		{"Whole float with precision", float(3), 2, "3"},
+		{"Long decimal", float(1.0 / 3), 0, "0.3333333333333333"},
+		{"Long decimal shortest", float(1.0 / 3), -1, "0.3333333333333333"},
+		{"Long decimal with precision", float(1.0 / 3), 4, "0.3333"},
+		{"Rounded", float(2.675), 2, "2.67"},
+		{"Padded", float(1.5), 3, "1.500"},
+		{"Large", float(1e21), 0, "1e+21"},
+		{"Constructed integer", &parser.NumberLiteral{Int: 42, Float: 42, IsInt: true, IsValid: true}, 2, "42"},
+		{"Literal kept", mustParse(t, "[1.23456]").(*parser.Array).Elements[0], 2, "1.23456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(tt.value, parser.MarshalOptions{FloatPrecision: tt.precision})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	t.Run("Normalized", func(t *testing.T) {
+		// NormalizeNumbers takes precedence, so the canonical form is written unrounded
+		opts := parser.MarshalOptions{NormalizeNumbers: true, FloatPrecision: 2}
+
+		value := mustParse(t, "[1.23456, 1e3]").(*parser.Array)
+		value.Elements = append(value.Elements, float(1.0/3))
+
+		data, err := parser.MarshalWith(value, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if expected := "[1.23456,1000,0.3333333333333333]"; string(data) != expected {
+			t.Errorf("Expected %s, got %s", expected, data)
+		}
+	})
+}
+
+func TestMarshalEscapeSlash(t *testing.T) {
+	value := mustParse(t, `{"a\/b": "<\/script>", "url": "http://x/y"}`)
+
+	tests := []struct {
+		name     string
+		opts     parser.MarshalOptions
+		expected string
+	}{
+		{"Default", parser.MarshalOptions{NoEscapeHTML: true}, `{"a/b":"</script>","url":"http://x/y"}`},
+		{"Escaped", parser.MarshalOptions{EscapeSlash: true, NoEscapeHTML: true}, `{"a\/b":"<\/script>","url":"http:\/\/x\/y"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			if !parser.Equal(mustParse(t, string(data)), value) {
+				t.Errorf("Expected %s to parse back to the same value", data)
+			}
+		})
+	}
+}
+
+func TestMarshalTrailingNewline(t *testing.T) {
+	value := mustParse(t, `{"a": [1]}`)
+
+	tests := []struct {
+		name     string
+		opts     parser.MarshalOptions
+		expected string
+	}{
+		{"Default", parser.MarshalOptions{}, `{"a":[1]}`},
+		{"Compact", parser.MarshalOptions{TrailingNewline: true}, "{\"a\":[1]}\n"},
+		{"Indented", parser.MarshalOptions{Indent: "  ", Prefix: "> ", TrailingNewline: true}, "{\n>   \"a\":[\n>     1\n>   ]\n> }\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalTrailingComma(t *testing.T) {
+	value := mustParse(t, `{"a": [1, {"b": true}], "c": {}, "d": []}`)
+
+	tests := []struct {
+		name     string
+		opts     parser.MarshalOptions
+		expected string
+	}{
+		{"Indented", parser.MarshalOptions{Indent: "  ", TrailingComma: true},
+			"{\n  \"a\":[\n    1,\n    {\n      \"b\":true,\n    },\n  ],\n  \"c\":{},\n  \"d\":[],\n}"},
+		{"Compact", parser.MarshalOptions{TrailingComma: true, CommaSpace: true}, `{"a":[1, {"b":true}], "c":{}, "d":[]}`},
+		{"Omitted last member", parser.MarshalOptions{Indent: " ", TrailingComma: true, Omit: func(path string, _ parser.Value) bool {
+			return path != "/c"
+		}}, "{\n \"c\":{},\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalInvalidUTF8(t *testing.T) {
+	value := &parser.Object{Pairs: map[string]parser.Value{
+		"k": &parser.StringLiteral{Value: "a\xffb"},
+	}}
+
+	tests := []struct {
+		name        string
+		mode        parser.InvalidUTF8Mode
+		expected    string
+		expectedErr string
+	}{
+		{"Replace by default", parser.InvalidUTF8Replace, "{\"k\":\"a\ufffdb\"}", ""},
+		{"Keep", parser.InvalidUTF8Keep, "{\"k\":\"a\xffb\"}", ""},
+		{"Error", parser.InvalidUTF8Error, "", "invalid UTF-8 in string at byte offset 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, parser.MarshalOptions{InvalidUTF8: tt.mode})
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalKeyOrder(t *testing.T) {
+	value := mustParse(t, `{"name": "a", "tags": {"z": 1, "id": 2, "b": 3}, "id": 1, "active": true}`)
+
+	// Put id first, keep the remaining keys sorted
+	idFirst := func(a, b string) bool { return a == "id" && b != "id" }
+
+	data, err := parser.MarshalWith(value, parser.MarshalOptions{KeyOrder: idFirst})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"id":1,"active":true,"name":"a","tags":{"id":2,"b":3,"z":1}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestMarshalIndentPresets(t *testing.T) {
+	value := mustParse(t, `{"b": [1, {}], "a": {"c": null}, "e": []}`)
+
+	tests := []struct {
+		name     string
+		marshal  func(parser.Value) ([]byte, error)
+		expected string
+	}{
+		{
+			"Tabs",
+			parser.MarshalTab,
+			"{\n\t\"a\": {\n\t\t\"c\": null\n\t},\n\t\"b\": [\n\t\t1,\n\t\t{}\n\t],\n\t\"e\": []\n}",
+		},
+		{
+			"Two spaces",
+			func(v parser.Value) ([]byte, error) { return parser.MarshalSpaces(v, 2) },
+			"{\n  \"a\": {\n    \"c\": null\n  },\n  \"b\": [\n    1,\n    {}\n  ],\n  \"e\": []\n}",
+		},
+		{
+			"Prefix",
+			func(v parser.Value) ([]byte, error) { return parser.MarshalIndent(v, "// ", " ") },
+			"{\n//  \"a\": {\n//   \"c\": null\n//  },\n//  \"b\": [\n//   1,\n//   {}\n//  ],\n//  \"e\": []\n// }",
+		},
+		{
+			"Single line with spaces",
+			func(v parser.Value) ([]byte, error) {
+				return parser.MarshalWith(v, parser.MarshalOptions{ColonSpace: true, CommaSpace: true})
+			},
+			`{"a": {"c": null}, "b": [1, {}], "e": []}`,
+		},
+		{
+			"Indented without colon space",
+			func(v parser.Value) ([]byte, error) {
+				return parser.MarshalWith(v, parser.MarshalOptions{Indent: " ", CommaSpace: true})
+			},
+			"{\n \"a\":{\n  \"c\":null\n },\n \"b\":[\n  1,\n  {}\n ],\n \"e\":[]\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.marshal(value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalEscapeHTML(t *testing.T) {
+	value := &parser.Object{Pairs: map[string]parser.Value{
+		"<b>": &parser.StringLiteral{Value: "Tom & Jerry <script>"},
+	}}
+
+	tests := []struct {
+		name     string
+		marshal  func(parser.Value) ([]byte, error)
+		expected string
+	}{
+		{"Marshal escapes HTML", parser.Marshal, `{"\u003cb\u003e":"Tom \u0026 Jerry \u003cscript\u003e"}`},
+		{
+			"Zero options escape HTML",
+			func(v parser.Value) ([]byte, error) { return parser.MarshalWith(v, parser.MarshalOptions{}) },
+			`{"\u003cb\u003e":"Tom \u0026 Jerry \u003cscript\u003e"}`,
+		},
+		{
+			"Opted out",
+			func(v parser.Value) ([]byte, error) {
+				return parser.MarshalWith(v, parser.MarshalOptions{NoEscapeHTML: true})
+			},
+			`{"<b>":"Tom & Jerry <script>"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.marshal(value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalEscapeJSSeparators(t *testing.T) {
+	value := &parser.Object{Pairs: map[string]parser.Value{
+		"a\u2028": &parser.StringLiteral{Value: "one\u2028two\u2029three"},
+	}}
+
+	tests := []struct {
+		name     string
+		opts     parser.MarshalOptions
+		expected string
+	}{
+		{"Option", parser.MarshalOptions{EscapeJSSeparators: true, NoEscapeHTML: true}, `{"a\u2028":"one\u2028two\u2029three"}`},
+		{"Implied by HTML escaping", parser.MarshalOptions{}, `{"a\u2028":"one\u2028two\u2029three"}`},
+		{"Not escaped without either", parser.MarshalOptions{NoEscapeHTML: true}, "{\"a\u2028\":\"one\u2028two\u2029three\"}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			if !parser.Equal(mustParse(t, string(data)), value) {
+				t.Errorf("Expected %s to parse back to the original", data)
+			}
+		})
+	}
+}
+
+func TestMarshalASCIIOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		ascii    string
+		expected string
+	}{
+		{"Accented", "café à Zürich", `"caf\u00e9 \u00e0 Z\u00fcrich"`, `"café à Zürich"`},
+		{"Emoji", "ok 👍", `"ok \ud83d\udc4d"`, `"ok 👍"`},
+		{"Mixed with escapes", "€\t\"", `"\u20ac\t\""`, `"€\t\""`},
+		{"Invalid UTF-8", "a\xffb", `"a\ufffdb"`, "\"a\ufffdb\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := &parser.StringLiteral{Value: tt.input}
+
+			ascii, err := parser.MarshalWith(value, parser.MarshalOptions{ASCIIOnly: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(ascii) != tt.ascii {
+				t.Errorf("Expected %s, got %s", tt.ascii, ascii)
+			}
+
+			data, err := parser.MarshalWith(value, parser.MarshalOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s without ASCIIOnly, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	key, err := parser.MarshalWith(&parser.Object{Pairs: map[string]parser.Value{"ключ": parser.NewNull()}}, parser.MarshalOptions{ASCIIOnly: true})
+	if err != nil || string(key) != `{"\u043a\u043b\u044e\u0447":null}` {
+		t.Errorf("Expected escaped key, got %s, %v", key, err)
+	}
+}
+
+func TestMarshalEscaper(t *testing.T) {
+	value := &parser.Object{Pairs: map[string]parser.Value{
+		"a\u2028b": &parser.StringLiteral{Value: "line\u2029<end>\n"},
+	}}
+
+	data, err := parser.MarshalWith(value, parser.MarshalOptions{Escaper: parser.JSEscaper})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := `{"a\u2028b":"line\u2029\u003cend\u003e\n"}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	if !parser.Equal(mustParse(t, string(data)), value) {
+		t.Errorf("Expected %s to parse back to the original", data)
+	}
+
+	upper := &parser.Object{Pairs: map[string]parser.Value{"<a>": &parser.StringLiteral{Value: "é"}}}
+
+	data, err = parser.MarshalWith(upper, parser.MarshalOptions{Escaper: strings.ToUpper, ASCIIOnly: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := `{"<A>":"É"}`; string(data) != expected {
+		t.Errorf("Expected the escaper to take over the other options, got %s", data)
+	}
+}
+
+func TestGoString(t *testing.T) {
+	value := mustParse(t, `{"user": {"name": "<Ada>", "roles": ["admin"]}, "age": 36, "ok": true, "x": null}`)
+	object := value.(*parser.Object)
+
+	tests := []struct {
+		name     string
+		value    parser.Value
+		expected string
+	}{
+		{"Object", value, `{"age":36,"ok":true,"user":{"name":"<Ada>","roles":["admin"]},"x":null}`},
+		{"Array", object.Pairs["user"].(*parser.Object).Pairs["roles"], `["admin"]`},
+		{"String", object.Pairs["user"].(*parser.Object).Pairs["name"], `"<Ada>"`},
+		{"Number", object.Pairs["age"], `36`},
+		{"Boolean", object.Pairs["ok"], `true`},
+		{"Null", object.Pairs["x"], `null`},
+		{"Invalid number", &parser.NumberLiteral{Value: "1.2.3"}, "0.000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprintf("%#v", tt.value); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if got := fmt.Errorf("bad input: %#v", value).Error(); !strings.HasPrefix(got, `bad input: {"age":36,`) {
+		t.Errorf("Expected the JSON in the error message, got %s", got)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		input string
+	}{
+		{"Records", recordsDataset(1000)},
+		{"Deep arrays", strings.Repeat("[", 1000) + strings.Repeat("]", 1000)},
+		{"Deep objects", strings.Repeat(`{"key":`, 1000) + `"leaf"` + strings.Repeat("}", 1000)},
+	}
+
+	for _, bm := range benchmarks {
+		value, err := parser.Parse(bm.input)
+		if err != nil {
+			b.Fatalf("Error parsing JSON: %v", err)
+		}
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.Marshal(value); err != nil {
+					b.Fatalf("Error marshaling JSON: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalNumbersAsStrings(t *testing.T) {
+	value := mustParse(t, `{"id": 9007199254740993, "count": 2, "items": [{"ownerId": 12, "n": 1.5}], "name": "x"}`)
+
+	tests := []struct {
+		name     string
+		opts     parser.MarshalOptions
+		expected string
+	}{
+		{"All numbers", parser.MarshalOptions{NumbersAsStrings: true},
+			`{"count":"2","id":"9007199254740993","items":[{"n":"1.5","ownerId":"12"}],"name":"x"}`},
+		{"Selected paths", parser.MarshalOptions{NumberStringPaths: []string{"id", "items.*.ownerId"}},
+			`{"count":2,"id":"9007199254740993","items":[{"n":1.5,"ownerId":"12"}],"name":"x"}`},
+		{"Indexed path", parser.MarshalOptions{NumberStringPaths: []string{"items[0].n"}},
+			`{"count":2,"id":9007199254740993,"items":[{"n":"1.5","ownerId":12}],"name":"x"}`},
+		{"Path to a string", parser.MarshalOptions{NumberStringPaths: []string{"name"}},
+			`{"count":2,"id":9007199254740993,"items":[{"n":1.5,"ownerId":12}],"name":"x"}`},
+		{"Normalized", parser.MarshalOptions{NumbersAsStrings: true, NormalizeNumbers: true},
+			`{"count":"2","id":"9007199254740992","items":[{"n":"1.5","ownerId":"12"}],"name":"x"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalOmit(t *testing.T) {
+	value := mustParse(t, `{"a": null, "b": [null, 1, null, {"c": null, "d/e": 2}, null], "secret": "x", "z": null}`)
+
+	isNull := func(_ string, v parser.Value) bool {
+		_, ok := v.(*parser.Null)
+		return ok
+	}
+
+	tests := []struct {
+		name     string
+		opts     parser.MarshalOptions
+		expected string
+	}{
+		{"Nulls", parser.MarshalOptions{Omit: isNull},
+			`{"b":[1,{"d/e":2}],"secret":"x"}`},
+		{"Paths", parser.MarshalOptions{Omit: func(path string, _ parser.Value) bool {
+			return path == "/secret" || path == "/b/0" || path == "/b/3/d~1e"
+		}},
+			`{"a":null,"b":[1,null,{"c":null},null],"z":null}`},
+		{"Every element", parser.MarshalOptions{Omit: func(path string, _ parser.Value) bool {
+			return strings.HasPrefix(path, "/b/")
+		}},
+			`{"a":null,"b":[],"secret":"x","z":null}`},
+		{"Indented", parser.MarshalOptions{Indent: "  ", ColonSpace: true, Omit: isNull},
+			"{\n  \"b\": [\n    1,\n    {\n      \"d/e\": 2\n    }\n  ],\n  \"secret\": \"x\"\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.MarshalWith(value, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+
+	// Omitted containers are not descended into, and the root is always written
+	var paths []string
+	_, err := parser.MarshalWith(value, parser.MarshalOptions{Omit: func(path string, _ parser.Value) bool {
+		paths = append(paths, path)
+		return path == "/b"
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := []string{"/a", "/b", "/secret", "/z"}; !slices.Equal(paths, expected) {
+		t.Errorf("Expected Omit to be called with %v, got %v", expected, paths)
+	}
+}
+
+func TestCheckSerializable(t *testing.T) {
+	cyclic := &parser.Array{}
+	cyclic.Elements = append(cyclic.Elements, cyclic)
+
+	tests := []struct {
+		name     string
+		value    parser.Value
+		expected string
+	}{
+		{"Valid", mustParse(t, `{"a": [1, "x", true, null, {}]}`), ""},
+		{"Invalid number", &parser.Object{Pairs: map[string]parser.Value{
+			"a": &parser.Array{Elements: []parser.Value{parser.NewNull(), &parser.NumberLiteral{Value: "1x"}}},
+		}}, `value at "/a/1": invalid number: "1x"`},
+		{"Infinite number", &parser.Array{Elements: []parser.Value{&parser.NumberLiteral{Float: math.Inf(1), IsValid: true}}},
+			`value at "/0": number +Inf cannot be represented in JSON`},
+		{"Invalid UTF-8 string", &parser.Object{Pairs: map[string]parser.Value{"k~": &parser.StringLiteral{Value: "a\xffb"}}},
+			`value at "/k~0": invalid UTF-8 in string "a\xffb"`},
+		{"Invalid UTF-8 key", &parser.Object{Pairs: map[string]parser.Value{"a\xff": parser.NewNull()}},
+			`value at "": invalid UTF-8 in key "a\xff"`},
+		{"Nil element", &parser.Array{Elements: []parser.Value{nil}}, `value at "/0": nil value`},
+		{"Invalid raw JSON", &parser.Array{Elements: []parser.Value{&parser.RawMessage{Bytes: []byte(`[1,]`)}}},
+			`value at "/0": invalid raw JSON: Line 1, Column 3: unexpected , before ]`},
+		{"Cycle", cyclic, `cycle: the value at "" contains itself at "/0"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parser.CheckSerializable(tt.value)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+
+				if _, err := parser.Marshal(tt.value); err != nil {
+					t.Errorf("Expected Marshal to succeed, got %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
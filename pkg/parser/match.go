@@ -0,0 +1,80 @@
+package parser
+
+// MatchAny is the pattern string that matches any value in Matches.
+const MatchAny = "*"
+
+// Matches reports whether doc matches pattern, a lightweight subset match meant for asserting on
+// API responses and routing documents by shape. The conventions are:
+//   - An object pattern matches an object holding every key of the pattern with a matching
+//     value. Keys of doc missing from the pattern are ignored.
+//   - An array pattern matches an array of the same length whose elements match element-wise.
+//   - The string MatchAny, "*", matches any value, null included, but not a missing key.
+//   - A type marker, a string made of a ValueType between angle brackets such as "<string>",
+//     "<number>", "<boolean>", "<null>", "<object>" or "<array>", matches any value of that type.
+//   - Any other scalar matches the same value as by Equal, so the pattern 1 matches 1.0.
+//
+// A literal "*" or "<string>" in doc is matched by the wildcard, so such strings cannot be
+// required exactly. A nil pattern only matches a nil doc.
+func Matches(doc, pattern Value) bool {
+	switch p := pattern.(type) {
+	case *Object:
+		d, ok := doc.(*Object)
+		if !ok {
+			return false
+		}
+
+		for k, v := range p.Pairs {
+			other, ok := d.Pairs[k]
+			if !ok || !Matches(other, v) {
+				return false
+			}
+		}
+
+		return true
+
+	case *Array:
+		d, ok := doc.(*Array)
+		if !ok || len(d.Elements) != len(p.Elements) {
+			return false
+		}
+
+		for i, v := range p.Elements {
+			if !Matches(d.Elements[i], v) {
+				return false
+			}
+		}
+
+		return true
+
+	case *StringLiteral:
+		if p.Value == MatchAny {
+			return doc != nil
+		}
+
+		if t, ok := typeMarker(p.Value); ok {
+			return typeOf(doc) == t
+		}
+
+		return Equal(doc, pattern)
+
+	case nil:
+		return doc == nil
+
+	default:
+		return Equal(doc, pattern)
+	}
+}
+
+// typeMarker returns the ValueType named by a type marker such as "<string>" in Matches.
+func typeMarker(s string) (ValueType, bool) {
+	if len(s) < 2 || s[0] != '<' || s[len(s)-1] != '>' {
+		return "", false
+	}
+
+	switch t := ValueType(s[1 : len(s)-1]); t {
+	case TypeObject, TypeArray, TypeString, TypeNumber, TypeBoolean, TypeNull:
+		return t, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,66 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestMatches(t *testing.T) {
+	const response = `{
+		"id": 42,
+		"status": "active",
+		"user": {"name": "Ada", "email": null},
+		"roles": ["admin", "dev"],
+		"meta": {"requestId": "f3a9"}
+	}`
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected bool
+	}{
+		{"Subset of keys", `{"status": "active"}`, true},
+		{"Nested subset", `{"user": {"name": "Ada"}}`, true},
+		{"Numbers by value", `{"id": 42.0}`, true},
+		{"Any value", `{"meta": "*", "user": {"email": "*"}}`, true},
+		{"Type markers", `{"id": "<number>", "roles": "<array>", "user": {"email": "<null>"}}`, true},
+		{"Array element-wise", `{"roles": ["<string>", "dev"]}`, true},
+		{"Empty object", `{}`, true},
+		{"Different value", `{"status": "inactive"}`, false},
+		{"Missing key", `{"deleted": "*"}`, false},
+		{"Wrong type", `{"id": "<string>"}`, false},
+		{"Array length", `{"roles": ["admin"]}`, false},
+		{"Array order", `{"roles": ["dev", "admin"]}`, false},
+		{"Object against array", `{"roles": {}}`, false},
+		{"Unknown marker is literal", `{"status": "<active>"}`, false},
+	}
+
+	doc := mustParse(t, response)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.Matches(doc, mustParse(t, tt.pattern)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Scalars", func(t *testing.T) {
+		if !parser.Matches(&parser.StringLiteral{Value: "<active>"}, &parser.StringLiteral{Value: "<active>"}) {
+			t.Error("Expected a string that is not a marker to match itself")
+		}
+
+		if !parser.Matches(&parser.Null{}, &parser.StringLiteral{Value: parser.MatchAny}) {
+			t.Error("Expected MatchAny to match null")
+		}
+
+		if parser.Matches(nil, &parser.StringLiteral{Value: parser.MatchAny}) {
+			t.Error("Expected MatchAny not to match a missing value")
+		}
+
+		if !parser.Matches(nil, nil) || parser.Matches(doc, nil) {
+			t.Error("Expected a nil pattern to match only a nil document")
+		}
+	})
+}
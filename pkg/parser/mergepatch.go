@@ -0,0 +1,124 @@
+package parser
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to target and returns the result. Members
+// of an object patch set to null remove the member from the target, other members are merged
+// recursively, and a patch that is not an object replaces the target wholesale. Neither argument
+// is modified: the result is a new tree that shares unchanged nodes with both of them. A nil
+// target is treated as missing.
+func ApplyMergePatch(target, patch Value) Value {
+	p, ok := patch.(*Object)
+	if !ok {
+		return patch
+	}
+
+	result := newObject()
+
+	if t, ok := target.(*Object); ok {
+		for k, v := range t.Pairs {
+			result.Pairs[k] = v
+		}
+	}
+
+	for k, v := range p.Pairs {
+		if _, ok := v.(*Null); ok {
+			delete(result.Pairs, k)
+			continue
+		}
+
+		result.Pairs[k] = ApplyMergePatch(result.Pairs[k], v)
+	}
+
+	return result
+}
+
+// DeepMergeAll merges docs into a single document, folding left to right so that each document
+// overrides the ones before it: DeepMergeAll(defaults, environment, overrides) gives overrides the
+// last word. Objects present in both documents are merged member by member, recursively, while
+// arrays and scalars, null included, replace the earlier value wholesale, as does any document or
+// member whose type differs from the earlier one. Unlike ApplyMergePatch, null does not remove a
+// member. Nil documents are skipped, and the result is nil when no document is given. The result
+// is a fresh tree that shares no nodes with docs, which are left unchanged. Objects record no key
+// order; use OrderedMap.Merge to keep the order of the documents merged.
+func DeepMergeAll(docs ...Value) Value {
+	var result Value
+
+	for _, doc := range docs {
+		if doc != nil {
+			result = deepMerge(result, doc)
+		}
+	}
+
+	return result
+}
+
+// deepMerge merges a copy of src into dst, which must be owned by the caller, and returns the
+// result.
+func deepMerge(dst, src Value) Value {
+	d, ok := dst.(*Object)
+	if !ok {
+		return clone(src)
+	}
+
+	s, ok := src.(*Object)
+	if !ok {
+		return clone(src)
+	}
+
+	for k, v := range s.Pairs {
+		d.Pairs[k] = deepMerge(d.Pairs[k], v)
+	}
+
+	return d
+}
+
+// MergePatchDiff returns the RFC 7386 JSON Merge Patch that turns from into to when applied with
+// ApplyMergePatch. Members removed in to are set to null, added or changed members are set to
+// their new value, recursing into objects present on both sides, and unchanged members are
+// omitted, so the patch is a minimal update payload. Arrays and scalars that differ are replaced
+// wholesale, as is the whole document when either side is not an object. The patch shares nodes
+// with to.
+//
+// Merge patches cannot express a member whose new value is null, since null means removal: such
+// members are removed from the result of applying the patch instead.
+func MergePatchDiff(from, to Value) Value {
+	f, ok := from.(*Object)
+	if !ok {
+		return to
+	}
+
+	t, ok := to.(*Object)
+	if !ok {
+		return to
+	}
+
+	patch := newObject()
+
+	for k := range f.Pairs {
+		if _, ok := t.Pairs[k]; !ok {
+			patch.Pairs[k] = NewNull()
+		}
+	}
+
+	for k, v := range t.Pairs {
+		old, ok := f.Pairs[k]
+
+		switch {
+		case !ok:
+			patch.Pairs[k] = v
+		case Equal(old, v):
+			// Unchanged members are omitted
+		default:
+			patch.Pairs[k] = MergePatchDiff(old, v)
+		}
+	}
+
+	return patch
+}
+
+// newObject creates an empty object node.
+func newObject() *Object {
+	return &Object{
+		Token: Token{Type: TokenBraceOpen, Literal: "{"},
+		Pairs: make(map[string]Value),
+	}
+}
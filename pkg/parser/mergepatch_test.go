@@ -0,0 +1,121 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// Test cases from RFC 7386, appendix A
+	tests := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" "+tt.patch, func(t *testing.T) {
+			target := mustParse(t, tt.target)
+			before, _ := parser.Marshal(target)
+
+			got := parser.ApplyMergePatch(target, mustParse(t, tt.patch))
+			if !parser.Equal(got, mustParse(t, tt.expected)) {
+				data, _ := parser.Marshal(got)
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			if after, _ := parser.Marshal(target); string(after) != string(before) {
+				t.Errorf("Expected target to be left unchanged, got %s", after)
+			}
+		})
+	}
+}
+
+func TestDeepMergeAll(t *testing.T) {
+	defaults := mustParse(t, `{"server": {"host": "localhost", "port": 80, "tls": {"enabled": false}}, "tags": ["a", "b"], "debug": true}`)
+	environment := mustParse(t, `{"server": {"host": "example.com", "tls": {"enabled": true, "cert": "a.pem"}}, "tags": ["c"]}`)
+	overrides := mustParse(t, `{"server": {"port": 8080, "tls": "off"}, "debug": null}`)
+
+	before := make([]string, 0, 3)
+	for _, doc := range []parser.Value{defaults, environment, overrides} {
+		data, _ := parser.Marshal(doc)
+		before = append(before, string(data))
+	}
+
+	got := parser.DeepMergeAll(defaults, environment, overrides)
+	expected := `{"debug":null,"server":{"host":"example.com","port":8080,"tls":"off"},"tags":["c"]}`
+	if !parser.Equal(got, mustParse(t, expected)) {
+		data, _ := parser.Marshal(got)
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	// Later documents win, so reversing the layers gives a different result
+	reversed := parser.DeepMergeAll(overrides, environment, defaults)
+	expected = `{"debug":true,"server":{"host":"localhost","port":80,"tls":{"enabled":false,"cert":"a.pem"}},"tags":["a","b"]}`
+	if !parser.Equal(reversed, mustParse(t, expected)) {
+		data, _ := parser.Marshal(reversed)
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	// The result is a fresh tree: modifying it leaves the layers unchanged
+	got.(*parser.Object).Pairs["tags"].(*parser.Array).Elements[0] = &parser.StringLiteral{Value: "x"}
+	reversed.(*parser.Object).Pairs["server"].(*parser.Object).Pairs["port"] = parser.NewNull()
+
+	for i, doc := range []parser.Value{defaults, environment, overrides} {
+		if data, _ := parser.Marshal(doc); string(data) != before[i] {
+			t.Errorf("Expected layer %d to be left unchanged, got %s", i, data)
+		}
+	}
+
+	if got := parser.DeepMergeAll(); got != nil {
+		t.Errorf("Expected nil for no documents, got %v", got)
+	}
+
+	if got := parser.DeepMergeAll(nil, mustParse(t, `[1]`), nil); !parser.Equal(got, mustParse(t, `[1]`)) {
+		t.Errorf("Expected nil documents to be skipped, got %v", got)
+	}
+}
+
+func TestMergePatchDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string
+	}{
+		{"Changed, added and removed", `{"a": 1, "b": 2, "c": 3}`, `{"a": 1, "b": 5, "d": 4}`, `{"b":5,"c":null,"d":4}`},
+		{"Nested objects", `{"user": {"name": "a", "age": 1}}`, `{"user": {"name": "a", "age": 2}}`, `{"user":{"age":2}}`},
+		{"Arrays replaced wholesale", `{"tags": [1, 2]}`, `{"tags": [1, 3]}`, `{"tags":[1,3]}`},
+		{"Object replaced by scalar", `{"a": {"b": 1}}`, `{"a": "x"}`, `{"a":"x"}`},
+		{"Unchanged", `{"a": [1, {"b": 2}]}`, `{"a": [1, {"b": 2}]}`, `{}`},
+		{"Non-object document", `[1]`, `[2]`, `[2]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := mustParse(t, tt.from), mustParse(t, tt.to)
+
+			patch := parser.MergePatchDiff(from, to)
+			if data, _ := parser.Marshal(patch); string(data) != tt.expected {
+				t.Errorf("Expected patch %s, got %s", tt.expected, data)
+			}
+
+			if got := parser.ApplyMergePatch(from, patch); !parser.Equal(got, to) {
+				data, _ := parser.Marshal(got)
+				t.Errorf("Expected applying the patch to yield %s, got %s", tt.to, data)
+			}
+		})
+	}
+}
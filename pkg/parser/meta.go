@@ -0,0 +1,21 @@
+package parser
+
+// SetMeta attaches m to the node v, replacing any data attached before, so that analyzers can
+// annotate a tree in place, for instance with validation results or source provenance, without
+// keeping a side map keyed by pointer. The data belongs to the node, not to its value: it is
+// ignored by Marshal, Equal and the other comparisons, and is not carried over by functions that
+// build new nodes, such as Normalize or Object.Filter. SetMeta does nothing when v is nil.
+func SetMeta(v Value, m any) {
+	if n, ok := v.(interface{ info() *nodeInfo }); ok {
+		n.info().meta = m
+	}
+}
+
+// GetMeta returns the data attached to v with SetMeta, or nil when there is none.
+func GetMeta(v Value) any {
+	if n, ok := v.(interface{ info() *nodeInfo }); ok {
+		return n.info().meta
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestMeta(t *testing.T) {
+	type provenance struct {
+		file string
+		line int
+	}
+
+	value := mustParse(t, `{"user": {"name": "Ada"}, "tags": ["a", "b"]}`)
+	object := value.(*parser.Object)
+	user := object.Pairs["user"]
+	tag := object.Pairs["tags"].(*parser.Array).Elements[1]
+
+	if got := parser.GetMeta(user); got != nil {
+		t.Fatalf("Expected no meta before SetMeta, got %v", got)
+	}
+
+	parser.SetMeta(user, provenance{"users.json", 3})
+	parser.SetMeta(tag, "checked")
+
+	if got, ok := parser.GetMeta(user).(provenance); !ok || got.file != "users.json" || got.line != 3 {
+		t.Errorf("Expected provenance{users.json 3}, got %v", parser.GetMeta(user))
+	}
+
+	if got := parser.GetMeta(tag); got != "checked" {
+		t.Errorf("Expected checked, got %v", got)
+	}
+
+	if got := parser.GetMeta(object); got != nil {
+		t.Errorf("Expected meta to stay on its node, got %v on the root", got)
+	}
+
+	parser.SetMeta(tag, nil)
+
+	if got := parser.GetMeta(tag); got != nil {
+		t.Errorf("Expected SetMeta(nil) to clear the meta, got %v", got)
+	}
+
+	t.Run("Ignored by Marshal and Equal", func(t *testing.T) {
+		const input = `{"a":[1,true,null]}`
+
+		annotated := mustParse(t, input)
+		parser.SetMeta(annotated, "root")
+		parser.SetMeta(annotated.(*parser.Object).Pairs["a"], 42)
+
+		if !parser.Equal(annotated, mustParse(t, input)) {
+			t.Error("Expected annotated tree to equal the plain tree")
+		}
+
+		out, err := parser.Marshal(annotated)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if string(out) != input {
+			t.Errorf("Expected %s, got %s", input, out)
+		}
+	})
+
+	t.Run("Nil value", func(t *testing.T) {
+		parser.SetMeta(nil, "ignored")
+
+		if got := parser.GetMeta(nil); got != nil {
+			t.Errorf("Expected nil, got %v", got)
+		}
+	})
+}
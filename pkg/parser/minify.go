@@ -0,0 +1,45 @@
+package parser
+
+import "strings"
+
+// MinifyJSONC returns the JSONC document in input, JSON with // line comments and /* */ block
+// comments, as compact JSON, such as to ship a commented config file as production JSON. The
+// comments and the whitespace between tokens are removed, while every token is copied from the
+// source unchanged: strings keep their escapes exactly as written, along with any text inside
+// them that looks like a comment, and numbers keep their literal. The document is first checked
+// like Parse with WithComments does, and a malformed one is reported as its ParseError, as is any
+// content after it other than whitespace and comments.
+func MinifyJSONC(input string) (string, error) {
+	p := NewParser(NewLexer(input), WithComments())
+	p.lexer.preserve = true // for the offset of the end of the document
+	if _, err := p.ParseJSON(); err != nil {
+		return "", err
+	}
+
+	if p.peekToken.Type != TokenEOF {
+		p.nextToken()
+		p.addError("unexpected token %s after the document", p.currentToken.Type)
+
+		return "", p.errors[0]
+	}
+
+	// The lexer reads a NUL byte as the end of the input
+	if p.peekToken.start < len(input) {
+		p.addErrorAt(p.peekToken, "unexpected NUL byte after the document")
+		return "", p.errors[0]
+	}
+
+	var b strings.Builder
+
+	b.Grow(len(input))
+
+	// Copy the source text of every token, read again by a lexer like the one of the parser
+	lexer := NewLexer(input)
+	lexer.allowComments, lexer.preserve = true, true
+
+	for t := lexer.NextToken(); t.Type != TokenEOF; t = lexer.NextToken() {
+		b.WriteString(input[t.start:t.end])
+	}
+
+	return b.String(), nil
+}
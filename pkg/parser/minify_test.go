@@ -0,0 +1,61 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestMinifyJSONC(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Line comments", "{\n  // the port\n  \"port\": 8080, // default\n  \"tls\": true\n}\n// end", `{"port":8080,"tls":true}`},
+		{"Block comments", "/* header */ [1, /* two */ 2,\n /* multi\n line */ 3.50e1]", `[1,2,3.50e1]`},
+		{"Comment-like strings", `{"url": "http://example.com", "glob": "/* not a comment */", "q": "a\"//b"}`,
+			`{"url":"http://example.com","glob":"/* not a comment */","q":"a\"//b"}`},
+		{"Escapes kept", `["é \/ \\", "tab	inside"]`, `["é \/ \\","tab	inside"]`},
+		{"Comments between tokens", `{"a"/**/:/**/{"b"//x` + "\n" + `:null}}`, `{"a":{"b":null}}`},
+		{"Plain JSON", ` { "a" : [ ] } `, `{"a":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.MinifyJSONC(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			want, _ := parser.Parse(tt.input, parser.WithComments())
+			if v, err := parser.Parse(got); err != nil || !parser.Equal(v, want) {
+				t.Errorf("Expected the output to parse as the same document, got %v", err)
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Unterminated comment", `{"a": 1 /* open`, "Line 1, Column 15: expected , or }, got ILLEGAL"},
+		{"Missing comma", `[1 /* x */ 2]`, "Line 1, Column 12: expected , between elements, got NUMBER"},
+		{"Content after the document", "{} // done\n[]", "Line 2, Column 1: unexpected token [ after the document"},
+		{"NUL byte before a quote", "[1]\x00\"", "Line 1, Column 4: unexpected NUL byte after the document"},
+		{"NUL byte before text", "[1]\x00garbage", "Line 1, Column 4: unexpected NUL byte after the document"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parser.MinifyJSONC(tt.input); err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
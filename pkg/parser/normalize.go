@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns a copy of the tree rooted at v with every number re-rendered in the canonical
+// RFC 8785 form, so that 1.50, 15e-1 and 1.5 all become 1.5, and with any comments dropped. It is
+// the tree counterpart of MarshalWith with NormalizeNumbers: two documents that differ only in key
+// order and number form normalize to trees that are Equal and marshal to identical bytes. Objects
+// hold no key order of their own, so their members are always written sorted. A number that has
+// no canonical form, such as one built in code with an invalid literal, is copied unchanged.
+func Normalize(v Value) Value {
+	return normalize(clone(v))
+}
+
+// normalize renders the numbers of the copied tree rooted at v canonically in place and drops its
+// comments, for Normalize, returning the value to store in place of v.
+func normalize(v Value) Value {
+	if n, ok := v.(interface{ info() *nodeInfo }); ok {
+		n.info().leadingComments, n.info().trailingComments = nil, nil
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		for k, child := range val.Pairs {
+			val.Pairs[k] = normalize(child)
+		}
+
+	case *Array:
+		for i, elem := range val.Elements {
+			val.Elements[i] = normalize(elem)
+		}
+
+	case *NumberLiteral:
+		if s, err := formatCanonicalNumber(val.Float); err == nil && val.IsValidNumber() {
+			return NewNumberLiteral(Token{Type: TokenNumber, Literal: s, Line: val.Token.Line, Column: val.Token.Column})
+		}
+	}
+
+	return v
+}
+
+// NormalizeUnicode returns a copy of the tree rooted at v with every string value converted to the
+// Unicode normalization form, such as norm.NFC or norm.NFD, so that text written with precomposed
+// characters, like "é", and with combining marks, like "e" followed by U+0301, compares Equal and
+// indexes the same way. Object keys are left as they are; use NormalizeUnicodeKeys to normalize
+// them too. Normalization uses golang.org/x/text/unicode/norm.
+func NormalizeUnicode(v Value, form norm.Form) Value {
+	result := clone(v)
+	_ = normalizeUnicode(result, "", form, false) // cannot fail without keys
+
+	return result
+}
+
+// NormalizeUnicodeKeys is like NormalizeUnicode but normalizes object keys as well. Keys that
+// collide once normalized, such as a precomposed and a decomposed spelling of the same word in one
+// object, are reported as an error naming both keys and the object, rather than one silently
+// overwriting the other, like NormalizeKeysNFC does while parsing. Duplicate keys of the source
+// text are never seen here, since the parser already kept only one of them, so documents that must
+// be checked for both should be parsed with NormalizeKeysNFC, which catches collisions under
+// NFC as the keys are read.
+func NormalizeUnicodeKeys(v Value, form norm.Form) (Value, error) {
+	result := clone(v)
+	if err := normalizeUnicode(result, "", form, true); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// normalizeUnicode converts the strings of the tree rooted at v, found at pointer, to form in
+// place, along with the keys when keys is set.
+func normalizeUnicode(v Value, pointer string, form norm.Form, keys bool) error {
+	switch val := v.(type) {
+	case *Object:
+		names := make([]string, 0, len(val.Pairs))
+		for k := range val.Pairs {
+			names = append(names, k)
+		}
+
+		sort.Strings(names) // so that a collision is reported the same way every time
+
+		pairs, originals := val.Pairs, map[string]string(nil)
+		if keys {
+			pairs, originals = make(map[string]Value, len(val.Pairs)), make(map[string]string, len(val.Pairs))
+		}
+
+		for _, k := range names {
+			child, key := val.Pairs[k], k
+			if keys {
+				key = form.String(k)
+				if original, ok := originals[key]; ok {
+					return fmt.Errorf("keys %q and %q of the object at %q collide once normalized", original, k, pointer)
+				}
+
+				originals[key] = k
+				pairs[key] = child
+			}
+
+			if err := normalizeUnicode(child, pointer+"/"+escapePointerToken(key), form, keys); err != nil {
+				return err
+			}
+		}
+
+		val.Pairs = pairs
+
+	case *Array:
+		for i, elem := range val.Elements {
+			if err := normalizeUnicode(elem, fmt.Sprintf("%s/%d", pointer, i), form, keys); err != nil {
+				return err
+			}
+		}
+
+	case *StringLiteral:
+		val.Value = form.String(val.Value)
+		val.Token.Literal = val.Value
+	}
+
+	return nil
+}
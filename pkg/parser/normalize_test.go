@@ -0,0 +1,104 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalize(t *testing.T) {
+	a := mustParse(t, `{"b": [1.50, 1e3, -0.0], "a": {"y": true, "x": null}, "c": "text"}`)
+	b := mustParse(t, `{"c": "text", "a": {"x": null, "y": true}, "b": [15e-1, 1000, 0]}`)
+
+	na, nb := parser.Normalize(a), parser.Normalize(b)
+
+	if !parser.Equal(na, nb) {
+		t.Fatal("Expected normalized trees to be equal")
+	}
+
+	got, err := parser.Marshal(na)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	other, err := parser.Marshal(nb)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	expected := `{"a":{"x":null,"y":true},"b":[1.5,1000,0],"c":"text"}`
+	if string(got) != expected || string(other) != expected {
+		t.Errorf("Expected both to marshal to %s, got %s and %s", expected, got, other)
+	}
+
+	if num := a.(*parser.Object).Pairs["b"].(*parser.Array).Elements[0].(*parser.NumberLiteral); num.Value != "1.50" {
+		t.Errorf("Expected the input to be untouched, got %s", num.Value)
+	}
+
+	if n := na.(*parser.Object).Pairs["b"].(*parser.Array).Elements[1].(*parser.NumberLiteral); !n.IsInt || n.Int != 1000 {
+		t.Errorf("Expected 1e3 to normalize to the integer 1000, got %#v", n)
+	}
+
+	commented, err := parser.Parse(`{"a": /* note */ "x"}`, parser.WithRetainedComments())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if comments := parser.LeadingComments(parser.Normalize(commented).(*parser.Object).Pairs["a"]); comments != nil {
+		t.Errorf("Expected comments to be dropped, got %q", comments)
+	}
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	precomposed := "{\"caf\u00e9\": [\"caf\u00e9\", {\"k\": \"r\u00e9sum\u00e9\"}], \"n\": 1}"
+	decomposed := "{\"cafe\u0301\": [\"cafe\u0301\", {\"k\": \"re\u0301sume\u0301\"}], \"n\": 1}"
+
+	a, b := mustParse(t, precomposed), mustParse(t, decomposed)
+	if parser.Equal(a, b) {
+		t.Fatal("Expected the precomposed and decomposed documents to differ")
+	}
+
+	tests := []struct {
+		name string
+		form norm.Form
+		from parser.Value
+		to   parser.Value
+	}{
+		{"NFC", norm.NFC, b, a},
+		{"NFD", norm.NFD, a, b},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.NormalizeUnicodeKeys(tt.from, tt.form)
+			if err != nil || !parser.Equal(got, tt.to) {
+				t.Errorf("Expected the keys and values to be normalized, got %v, %v", got, err)
+			}
+
+			// Without keys, only the values change
+			values := parser.NormalizeUnicode(tt.from, tt.form).(*parser.Object)
+			for k, v := range values.Pairs {
+				if k != "n" && !parser.Equal(v, tt.to.(*parser.Object).Pairs[tt.form.String(k)]) {
+					t.Errorf("Expected the values of %q to be normalized, got %v", k, v)
+				}
+
+				if _, ok := tt.from.(*parser.Object).Pairs[k]; !ok {
+					t.Errorf("Expected the key %q to be left as is", k)
+				}
+			}
+		})
+	}
+
+	if !strings.Contains(b.(*parser.Object).Pairs["cafe\u0301"].(*parser.Array).Elements[0].(*parser.StringLiteral).Value, "\u0301") {
+		t.Error("Expected the input to be untouched")
+	}
+
+	collide := mustParse(t, "{\"x\": {\"caf\u00e9\": 1, \"cafe\u0301\": 2}}")
+	expected := "keys \"cafe\u0301\" and \"caf\u00e9\" of the object at \"/x\" collide once normalized"
+
+	if _, err := parser.NormalizeUnicodeKeys(collide, norm.NFC); err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CheckNumbers returns a *NumberError for every number in the tree rooted at v that a system
+// limited to finite float64 and int64 values may not be able to hold, in the order Walk visits
+// them, or nil when there is none. This is a validation pass for data about to be handed to such
+// a system, whose errors name the JSON Pointer of each number so it can be rejected or sanitized.
+// It reports:
+//
+//   - numbers that are NaN or infinite, which only come from trees built in code or from
+//     Keywords such as NaN and Infinity, as "is not finite";
+//   - integers outside the range of int64, accepted with Overflowed set, as "overflows int64";
+//   - literals outside the range of float64, such as 1e400, which the parser rejects but trees
+//     built in code may hold, as "is out of the range of float64";
+//   - any other number that is not valid, as "is not a valid number".
+func CheckNumbers(v Value) []error {
+	var errs []error
+
+	_ = Walk(v, func(pointer string, v Value) error {
+		n, ok := v.(*NumberLiteral)
+		if !ok {
+			return nil
+		}
+
+		var message string
+
+		switch {
+		case !n.IsValidNumber():
+			message = "is not a valid number"
+			if _, err := strconv.ParseFloat(n.Value, 64); errors.Is(err, strconv.ErrRange) {
+				message = "is out of the range of float64"
+			}
+		case math.IsNaN(n.Float) || math.IsInf(n.Float, 0):
+			message = "is not finite"
+		case n.Overflowed:
+			message = "overflows int64"
+		default:
+			return nil
+		}
+
+		errs = append(errs, &NumberError{Path: pointer, Number: n, Message: message})
+
+		return nil
+	})
+
+	return errs
+}
+
+// CoerceNumbers returns a copy of the tree rooted at v in which every number is rewritten as an
+// integer when toInt is true, or as a float otherwise, for downstream schemas that only accept one
+// of the two, along with a *NumberError for every number whose value changed or could not be
+// converted, in the order Walk visits them, or nil when there is none. v itself is left untouched.
+//
+// Integers are made from other numbers by rounding to the nearest integer, with halves rounded
+// away from zero as by math.Round, so 2.5 becomes 3 and -2.5 becomes -3; the numbers that were
+// not already integral are reported as "was rounded to" the result. Numbers that are NaN, infinite
+// or outside the range of int64 once rounded are kept as they are and reported as "cannot be
+// rounded to an int64". Integer literals, including those outside the range of int64, are kept.
+//
+// Floats are made from integers by writing the nearest float64 with a fraction or exponent, so 3
+// becomes 3.0, and the integers it does not hold exactly, beyond 2^53, are reported as "lost
+// precision as a float". Numbers that are floats already keep their literal.
+//
+// In both directions, numbers that are not valid are kept and reported as "is not a valid number".
+func CoerceNumbers(v Value, toInt bool) (Value, []error) {
+	var (
+		errs    []error
+		current string
+	)
+
+	isNumber := func(pointer string, v Value) bool {
+		current = pointer
+		_, ok := v.(*NumberLiteral)
+
+		return ok
+	}
+
+	result := ReplaceWhere(v, isNumber, func(old Value) Value {
+		n := old.(*NumberLiteral)
+
+		coerced, message := coerceToFloat(n)
+		if toInt {
+			coerced, message = coerceToInt(n)
+		}
+
+		if message != "" {
+			errs = append(errs, &NumberError{Path: current, Number: n, Message: message})
+		}
+
+		return coerced
+	})
+
+	return result, errs
+}
+
+// coerceToInt returns n rewritten as an integer for CoerceNumbers, along with the message
+// reporting why the conversion was lossy, or an empty message when it was not.
+func coerceToInt(n *NumberLiteral) (*NumberLiteral, string) {
+	switch {
+	case !n.IsValidNumber():
+		return n, "is not a valid number"
+	case n.IsInt || n.Overflowed:
+		return n, ""
+	}
+
+	// Float64 values from -2^63 up to but excluding 2^63 convert to int64 exactly
+	rounded := math.Round(n.Float)
+	if math.IsNaN(rounded) || rounded < -0x1p63 || rounded >= 0x1p63 {
+		return n, "cannot be rounded to an int64"
+	}
+
+	coerced := NewNumberLiteral(Token{Type: TokenNumber, Literal: strconv.FormatInt(int64(rounded), 10)})
+	if rounded != n.Float {
+		return coerced, "was rounded to " + coerced.Value
+	}
+
+	return coerced, ""
+}
+
+// coerceToFloat returns n rewritten as a float for CoerceNumbers, along with the message
+// reporting why the conversion was lossy, or an empty message when it was not.
+func coerceToFloat(n *NumberLiteral) (*NumberLiteral, string) {
+	switch {
+	case !n.IsValidNumber():
+		return n, "is not a valid number"
+	case !n.IsInt && !n.Overflowed:
+		return n, ""
+	}
+
+	literal := strconv.FormatFloat(n.Float, 'g', -1, 64)
+	if !strings.ContainsAny(literal, ".e") {
+		literal += ".0"
+	}
+
+	coerced := NewNumberLiteral(Token{Type: TokenNumber, Literal: literal})
+
+	var exact bool
+
+	if n.IsInt {
+		exact = n.Float < 0x1p63 && int64(n.Float) == n.Int
+	} else if want, ok := new(big.Int).SetString(n.Value, 10); ok {
+		got, _ := new(big.Float).SetFloat64(n.Float).Int(nil)
+		exact = got.Cmp(want) == 0
+	}
+
+	if !exact {
+		return coerced, "lost precision as a float"
+	}
+
+	return coerced, ""
+}
@@ -0,0 +1,104 @@
+package parser_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestCheckNumbers(t *testing.T) {
+	keywords := map[string]parser.Value{
+		"NaN":       &parser.NumberLiteral{Float: math.NaN(), IsValid: true},
+		"-Infinity": &parser.NumberLiteral{Float: math.Inf(-1), IsValid: true},
+	}
+
+	value, err := parser.Parse(`{"ok": [1, 2.5, -0], "big": 9223372036854775808, "m": {"nan": NaN, "low": -Infinity}}`,
+		parser.WithKeywords(keywords))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	value.(*parser.Object).
+		Set("bad", &parser.NumberLiteral{Value: "1x"}).
+		Set("huge", parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: "1e400"}))
+
+	expected := []string{
+		`number 1x at "/bad" is not a valid number`,
+		`number 9223372036854775808 at "/big" overflows int64`,
+		`number 1e400 at "/huge" is out of the range of float64`,
+		`number -Inf at "/m/low" is not finite`,
+		`number NaN at "/m/nan" is not finite`,
+	}
+
+	errs := parser.CheckNumbers(value)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), errs)
+	}
+
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected error %q, got %q", expected[i], err)
+		}
+	}
+
+	var numErr *parser.NumberError
+	if !errors.As(errs[1], &numErr) || numErr.Path != "/big" || !numErr.Number.Overflowed {
+		t.Errorf("Expected a NumberError for /big, got %#v", errs[1])
+	}
+
+	if errs := parser.CheckNumbers(mustParse(t, `[1, 1e308, -9223372036854775808]`)); errs != nil {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+}
+
+func TestCoerceNumbers(t *testing.T) {
+	input := `{"a": [1, 2.5, -2.5, 3.0, 1e2], "big": 9007199254740993, "huge": 1e300, "over": 18446744073709551616}`
+
+	tests := []struct {
+		name     string
+		toInt    bool
+		expected string
+		errors   []string
+	}{
+		{"To int", true, `{"a":[1,3,-3,3,100],"big":9007199254740993,"huge":1e300,"over":18446744073709551616}`, []string{
+			`number 2.5 at "/a/1" was rounded to 3`,
+			`number -2.5 at "/a/2" was rounded to -3`,
+			`number 1e300 at "/huge" cannot be rounded to an int64`,
+		}},
+		{"To float", false, `{"a":[1.0,2.5,-2.5,3.0,1e2],"big":9.007199254740992e+15,"huge":1e300,"over":1.8446744073709552e+19}`, []string{
+			`number 9007199254740993 at "/big" lost precision as a float`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, input)
+
+			coerced, errs := parser.CoerceNumbers(v, tt.toInt)
+
+			if data, _ := parser.Marshal(coerced); string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("Expected %d errors, got %v", len(tt.errors), errs)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tt.errors[i] {
+					t.Errorf("Expected error %q, got %q", tt.errors[i], err)
+				}
+			}
+
+			if !parser.Equal(v, mustParse(t, input)) {
+				t.Error("Expected the input tree to be left untouched")
+			}
+		})
+	}
+
+	if _, errs := parser.CoerceNumbers(mustParse(t, `[1, 2.0, -0]`), true); errs != nil {
+		t.Errorf("Expected no errors for integral numbers, got %v", errs)
+	}
+}
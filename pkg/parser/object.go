@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortedKeys returns the keys of the object in lexicographic order. The returned slice is a
+// copy, so sorting it never affects the object itself. It returns an empty slice for an empty
+// or nil object.
+func (o *Object) SortedKeys() []string {
+	if o == nil {
+		return []string{}
+	}
+
+	keys := make([]string, 0, len(o.Pairs))
+	for k := range o.Pairs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Get returns the value stored under key and whether it was present. It is safe to call on a nil
+// object.
+func (o *Object) Get(key string) (Value, bool) {
+	if o == nil {
+		return nil, false
+	}
+
+	v, ok := o.Pairs[key]
+
+	return v, ok
+}
+
+// GetFold looks up key like Get, but falls back to matching keys under Unicode case folding, as
+// strings.EqualFold does, when there is no exact match. An exact match is always preferred. If
+// several keys match case-insensitively, the one that sorts first wins so the result is
+// deterministic.
+func (o *Object) GetFold(key string) (Value, bool) {
+	if v, ok := o.Get(key); ok || o == nil {
+		return v, ok
+	}
+
+	for _, k := range o.SortedKeys() {
+		if strings.EqualFold(k, key) {
+			return o.Pairs[k], true
+		}
+	}
+
+	return nil, false
+}
+
+// Filter returns a new object holding only the members of o for which keep returns true. keep is
+// called in sorted key order. The values are shared with o rather than copied, and o itself is
+// left untouched. Filter on a nil object returns an empty object. Objects record no key order, so
+// neither does the result; use OrderedMap.Filter to keep the order of the source document.
+func (o *Object) Filter(keep func(key string, v Value) bool) *Object {
+	result := newObject()
+	if o == nil {
+		return result
+	}
+
+	result.Token = o.Token
+
+	for _, k := range o.SortedKeys() {
+		if v := o.Pairs[k]; keep(k, v) {
+			result.Pairs[k] = v
+		}
+	}
+
+	return result
+}
+
+// Set stores v under key, replacing any previous value, and returns o so that calls can be
+// chained. A nil v is stored as null. Like storing into a nil map, Set panics when o is nil.
+func (o *Object) Set(key string, v Value) *Object {
+	if v == nil {
+		v = NewNull()
+	}
+
+	if o.Pairs == nil {
+		o.Pairs = make(map[string]Value)
+	}
+
+	o.Pairs[key] = v
+
+	return o
+}
+
+// WrapInObject returns a new object holding v as its only member, under key, giving {key: v}. A
+// nil v is stored as null, like Set does. v is shared rather than copied.
+func WrapInObject(key string, v Value) *Object {
+	return newObject().Set(key, v)
+}
+
+// Ensure returns the object found at the dotted path below o, creating empty objects for every
+// missing key along the way, so that nested documents can be built with calls such as
+// root.Ensure("a.b.c").Set("x", v). Every segment of the path is taken as an object key. The empty
+// path returns o itself. Ensure returns nil when o is nil or a value that is not an object is in
+// the way, so chaining Set on its result then panics; use EnsurePath to learn which one.
+func (o *Object) Ensure(path string) *Object {
+	leaf, err := o.EnsurePath(path)
+	if err != nil {
+		return nil
+	}
+
+	return leaf
+}
+
+// EnsurePath is like Ensure, but reports an error naming the offending key when a value along the
+// path is not an object, or when o is nil. Objects created before the conflict was found are kept.
+func (o *Object) EnsurePath(path string) (*Object, error) {
+	if o == nil {
+		return nil, fmt.Errorf("cannot ensure path %q: the object is nil", path)
+	}
+
+	current := o
+	segments := parsePath(path)
+
+	for i, key := range segments {
+		next, ok := current.Pairs[key]
+		if !ok {
+			child := newObject()
+			current.Set(key, child)
+			current = child
+
+			continue
+		}
+
+		child, ok := next.(*Object)
+		if !ok {
+			prefix := strings.Join(segments[:i+1], ".")
+			return nil, fmt.Errorf("cannot ensure path %q: %q holds %s, not an object", path, prefix, aTypeOf(next))
+		}
+
+		current = child
+	}
+
+	return current, nil
+}
+
+// Keys returns the keys of the object in their stored order, which is the order every operation
+// of this package iterates and writes them in. Objects do not record the order in which keys
+// were inserted, so the stored order is currently sorted order, the same as SortedKeys. The
+// returned slice is a copy. It returns an empty slice for an empty or nil object.
+func (o *Object) Keys() []string {
+	return o.SortedKeys()
+}
+
+// KeyDiff partitions the keys of o and other into those only in o, those only in other and those
+// in both, each in sorted order, such as to list the fields added and removed between two
+// versions of a config. It only compares the top-level keys, not the values stored under them;
+// see UnifiedDiff for a recursive comparison. A nil object counts as having no keys, and every
+// result is an empty slice rather than nil when it has no keys.
+func (o *Object) KeyDiff(other *Object) (onlyInSelf, onlyInOther, inBoth []string) {
+	onlyInSelf, onlyInOther, inBoth = []string{}, []string{}, []string{}
+
+	for _, k := range o.SortedKeys() {
+		if _, ok := other.Get(k); ok {
+			inBoth = append(inBoth, k)
+		} else {
+			onlyInSelf = append(onlyInSelf, k)
+		}
+	}
+
+	for _, k := range other.SortedKeys() {
+		if _, ok := o.Get(k); !ok {
+			onlyInOther = append(onlyInOther, k)
+		}
+	}
+
+	return onlyInSelf, onlyInOther, inBoth
+}
+
+// Values returns the values of the object in the order of Keys, so the two can be zipped. It
+// returns an empty slice for an empty or nil object.
+func (o *Object) Values() []Value {
+	keys := o.Keys()
+
+	values := make([]Value, len(keys))
+	for i, k := range keys {
+		values[i] = o.Pairs[k]
+	}
+
+	return values
+}
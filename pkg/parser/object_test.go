@@ -0,0 +1,245 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func parseObject(t *testing.T, input string) *parser.Object {
+	t.Helper()
+
+	value, err := parser.NewParser(parser.NewLexer(input)).ParseJSON()
+	if err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	obj, ok := value.(*parser.Object)
+	if !ok {
+		t.Fatalf("Expected *parser.Object, got %T", value)
+	}
+
+	return obj
+}
+
+func TestObjectSortedKeys(t *testing.T) {
+	obj := parseObject(t, `{"b": 1, "c": 2, "a": 3}`)
+
+	keys := obj.SortedKeys()
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Fatalf("Expected sorted keys, got %v", keys)
+	}
+
+	keys[0] = "z"
+	if _, ok := obj.Pairs["z"]; ok {
+		t.Fatal("Mutating the returned slice must not affect the object")
+	}
+
+	if keys := parseObject(t, `{}`).SortedKeys(); keys == nil || len(keys) != 0 {
+		t.Fatalf("Expected empty slice for empty object, got %#v", keys)
+	}
+
+	var nilObj *parser.Object
+	if keys := nilObj.SortedKeys(); keys == nil || len(keys) != 0 {
+		t.Fatalf("Expected empty slice for nil object, got %#v", keys)
+	}
+}
+
+func TestObjectGetFold(t *testing.T) {
+	obj := parseObject(t, `{"Content-Type": "json", "content-type": "exact", "ÉTÉ": "summer", "X-ID": 1}`)
+
+	tests := []struct {
+		key      string
+		expected string
+		found    bool
+	}{
+		{key: "content-type", expected: "exact", found: true},
+		{key: "CONTENT-TYPE", expected: "json", found: true},
+		{key: "été", expected: "summer", found: true},
+		{key: "missing", found: false},
+	}
+
+	for _, tt := range tests {
+		v, ok := obj.GetFold(tt.key)
+		if ok != tt.found {
+			t.Fatalf("GetFold(%q): expected found=%v, got %v", tt.key, tt.found, ok)
+		}
+
+		if ok && v.(*parser.StringLiteral).Value != tt.expected {
+			t.Errorf("GetFold(%q): expected %q, got %q", tt.key, tt.expected, v.String())
+		}
+	}
+
+	if _, ok := obj.Get("x-id"); ok {
+		t.Error("Get must match keys exactly")
+	}
+
+	var nilObj *parser.Object
+	if _, ok := nilObj.GetFold("a"); ok {
+		t.Error("Expected no match on nil object")
+	}
+}
+
+func TestObjectFilter(t *testing.T) {
+	obj := parseObject(t, `{"id": 1, "name": "x", "secret": "s", "nested": {"a": 1}}`)
+	allowed := map[string]bool{"id": true, "nested": true}
+
+	var visited []string
+
+	filtered := obj.Filter(func(key string, _ parser.Value) bool {
+		visited = append(visited, key)
+
+		return allowed[key]
+	})
+
+	if keys := filtered.SortedKeys(); !reflect.DeepEqual(keys, []string{"id", "nested"}) {
+		t.Fatalf("Expected allowlisted keys, got %v", keys)
+	}
+
+	if !reflect.DeepEqual(visited, []string{"id", "name", "nested", "secret"}) {
+		t.Errorf("Expected keep to be called in key order, got %v", visited)
+	}
+
+	if filtered.Pairs["nested"] != obj.Pairs["nested"] {
+		t.Error("Expected values to be shared with the original")
+	}
+
+	if len(obj.Pairs) != 4 {
+		t.Errorf("Expected the original to keep its 4 members, got %d", len(obj.Pairs))
+	}
+
+	var nilObj *parser.Object
+	if got := nilObj.Filter(func(string, parser.Value) bool { return true }); got == nil || len(got.Pairs) != 0 {
+		t.Errorf("Expected an empty object for nil, got %#v", got)
+	}
+}
+
+func TestObjectEnsure(t *testing.T) {
+	root := parseObject(t, `{"a": {"keep": true}, "s": "text"}`)
+
+	root.Ensure("a.b.c").Set("x", &parser.StringLiteral{Value: "v"}).Set("y", nil)
+	root.Ensure("a.b").Set("z", parser.NewNull())
+
+	got, err := parser.Marshal(root)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	expected := `{"a":{"b":{"c":{"x":"v","y":null},"z":null},"keep":true},"s":"text"}`
+	if string(got) != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	if leaf := root.Ensure(""); leaf != root {
+		t.Error("Expected the empty path to return the object itself")
+	}
+
+	if leaf := root.Ensure("s.t"); leaf != nil {
+		t.Errorf("Expected nil when a string is in the way, got %v", leaf)
+	}
+
+	_, err = root.EnsurePath("a.keep.deeper")
+	if err == nil || err.Error() != `cannot ensure path "a.keep.deeper": "a.keep" holds a boolean, not an object` {
+		t.Errorf("Expected a path error, got %v", err)
+	}
+
+	var nilObj *parser.Object
+	if _, err := nilObj.EnsurePath("a"); err == nil || err.Error() != `cannot ensure path "a": the object is nil` {
+		t.Errorf("Expected an error for a nil object, got %v", err)
+	}
+
+	if leaf := nilObj.Ensure(""); leaf != nil {
+		t.Errorf("Expected nil for a nil object, got %v", leaf)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Set to panic on a nil object")
+		}
+	}()
+
+	nilObj.Set("a", nil)
+}
+
+func TestObjectKeys(t *testing.T) {
+	obj := parseObject(t, `{"b": 1, "c": 2, "a": 3}`)
+
+	if keys := obj.Keys(); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Fatalf("Expected keys in stored order, got %v", keys)
+	}
+
+	if keys := parseObject(t, `{}`).Keys(); keys == nil || len(keys) != 0 {
+		t.Fatalf("Expected empty slice for empty object, got %#v", keys)
+	}
+
+	var nilObj *parser.Object
+	if keys := nilObj.Keys(); keys == nil || len(keys) != 0 {
+		t.Fatalf("Expected empty slice for nil object, got %#v", keys)
+	}
+}
+
+func TestObjectValues(t *testing.T) {
+	obj := parseObject(t, `{"b": 2, "c": 3, "a": 1}`)
+
+	values := obj.Values()
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, got %d", len(values))
+	}
+
+	for i, k := range obj.Keys() {
+		if values[i] != obj.Pairs[k] {
+			t.Errorf("Expected value %d to be the value of %q, got %v", i, k, values[i])
+		}
+	}
+
+	if values := parseObject(t, `{}`).Values(); values == nil || len(values) != 0 {
+		t.Fatalf("Expected empty slice for empty object, got %#v", values)
+	}
+
+	var nilObj *parser.Object
+	if values := nilObj.Values(); values == nil || len(values) != 0 {
+		t.Fatalf("Expected empty slice for nil object, got %#v", values)
+	}
+}
+
+func TestObjectKeyDiff(t *testing.T) {
+	v1 := mustParse(t, `{"host": "a", "port": 80, "tls": true, "debug": false}`).(*parser.Object)
+	v2 := mustParse(t, `{"port": "80", "host": "b", "timeout": 5, "name": "x"}`).(*parser.Object)
+
+	tests := []struct {
+		name                      string
+		self, other               *parser.Object
+		onlySelf, onlyOther, both []string
+	}{
+		{"Changed keys", v1, v2, []string{"debug", "tls"}, []string{"name", "timeout"}, []string{"host", "port"}},
+		{"Reversed", v2, v1, []string{"name", "timeout"}, []string{"debug", "tls"}, []string{"host", "port"}},
+		{"Same object", v1, v1, []string{}, []string{}, []string{"debug", "host", "port", "tls"}},
+		{"Nil receiver", nil, v1, []string{}, []string{"debug", "host", "port", "tls"}, []string{}},
+		{"Nil other", v1, nil, []string{"debug", "host", "port", "tls"}, []string{}, []string{}},
+		{"Both nil", nil, nil, []string{}, []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onlySelf, onlyOther, both := tt.self.KeyDiff(tt.other)
+
+			if !reflect.DeepEqual(onlySelf, tt.onlySelf) || !reflect.DeepEqual(onlyOther, tt.onlyOther) || !reflect.DeepEqual(both, tt.both) {
+				t.Errorf("Expected %v, %v, %v, got %v, %v, %v", tt.onlySelf, tt.onlyOther, tt.both, onlySelf, onlyOther, both)
+			}
+		})
+	}
+}
+
+func TestWrapInObject(t *testing.T) {
+	value := mustParse(t, `[1, 2]`)
+
+	obj := parser.WrapInObject("items", value)
+	if got, ok := obj.Get("items"); !ok || got != value || len(obj.Pairs) != 1 {
+		t.Errorf("Expected the value as the only member, got %v", obj)
+	}
+
+	if data, _ := parser.Marshal(parser.WrapInObject("x", nil)); string(data) != `{"x":null}` {
+		t.Errorf("Expected {\"x\":null} for nil, got %s", data)
+	}
+}
@@ -0,0 +1,472 @@
+package parser
+
+import "time"
+
+// DefaultMaxNumberLength is the default limit on the number of characters in a single numeric
+// literal. It is generous enough for any float64 written out in full decimal form.
+const DefaultMaxNumberLength = 1024
+
+// ParserOptions holds the configuration of a Parser. Every option is off in the zero value, and
+// the defaults returned by DefaultParserOptions only add resource limits, so a Parser accepts
+// strict JSON unless configured otherwise.
+type ParserOptions struct {
+	// MaxNumberLength caps the length of a numeric literal. Longer literals are reported as a
+	// ParseError instead of being handed to strconv. A value of zero or less disables the limit.
+	MaxNumberLength int
+
+	// EmptyAsNull makes ParseJSON return a *Null for empty or whitespace-only input instead of a
+	// ParseError. This is convenient for optional request bodies.
+	EmptyAsNull bool
+
+	// AllowComments makes the parser accept // line comments and /* */ block comments (JSONC)
+	// wherever whitespace is allowed.
+	AllowComments bool
+
+	// RetainComments attaches the comments skipped under AllowComments to the AST instead of
+	// discarding them. See LeadingComments for the attachment rules.
+	RetainComments bool
+
+	// ArrayHint is the initial capacity reserved for the elements of the first array opened in
+	// the document, which is typically the large outer array of a dataset. Reserving the expected
+	// size up front avoids repeated reallocation while appending. It does not limit the length of
+	// the array. A value of zero or less disables the hint.
+	ArrayHint int
+
+	// NormalizeKeysNFC normalizes object keys to Unicode Normalization Form C before they are
+	// stored, so keys that differ only in composition, such as a precomposed "é" and "e" followed
+	// by a combining acute accent, become the same key. Keys that collide after normalization,
+	// exact duplicates included, are reported as a ParseError instead of silently overwriting each
+	// other, which closes a spoofing vector in security-sensitive documents. Normalization uses
+	// golang.org/x/text/unicode/norm.
+	NormalizeKeysNFC bool
+
+	// KeyInterner, when set, is used to intern every object key, so that documents repeating the
+	// same keys many times, such as rows of records, keep a single copy of each key string. The
+	// same Interner can be reused across parses.
+	KeyInterner Interner
+
+	// NumberFactory, when set, is called with the literal of every number instead of building a
+	// NumberLiteral, so that callers can plug in arbitrary-precision decimal types. The factory is
+	// responsible for validating the literal: the lexer only guarantees it is made of the
+	// characters a JSON number may contain. A returned error is reported as a ParseError at the
+	// position of the number. Types outside this package satisfy Value by embedding one of its
+	// node types, typically NumberLiteral, and are marshaled through their MarshalJSON method,
+	// whose output is written like the JSON value it holds; without one, Marshal reports an error.
+	// MaxNumberLength is checked before the factory is called.
+	NumberFactory func(literal string) (Value, error)
+
+	// MaxKeysPerObject caps the number of members in a single object. Parsing fails fast with a
+	// ParseError at the position of the object's opening brace as soon as an object goes over the
+	// limit, which protects against hash-flooding attacks that send objects with millions of
+	// keys. Duplicate keys count each time they appear. A value of zero or less disables the limit.
+	MaxKeysPerObject int
+
+	// MaxArrayLength caps the number of elements in a single array, to bound the memory an
+	// adversarially long array can make the parser allocate. Parsing fails fast with a ParseError
+	// at the position of the array's opening bracket as soon as an array goes over the limit. The
+	// top-level array read by StreamArray, whose elements are not kept, is not limited, but the
+	// arrays nested in its elements are. A value of zero or less disables the limit.
+	MaxArrayLength int
+
+	// Keywords maps additional bareword literals to the values they stand for, to accept
+	// quasi-JSON dialects such as Python's None, True and False, JavaScript's undefined, or
+	// +Infinity. A bareword is a run of letters, digits and underscores starting with a letter,
+	// optionally preceded by a sign. The standard true, false and null are always recognized and
+	// cannot be redefined, and barewords missing from the table are still reported as errors.
+	// Scalar values are copied for each occurrence, and hold the comments retained before it;
+	// containers are inserted as is and shared, so those comments are dropped.
+	Keywords map[string]Value
+
+	// MaxDepth caps the nesting depth of objects and arrays, counting the outermost container as
+	// depth 1. Deeper documents are reported as a ParseError at the container that goes over the
+	// limit. A value of zero or less disables the limit.
+	MaxDepth int
+
+	// StrictNumbers reports integer literals outside the range of int64 as a ParseError instead
+	// of accepting them as a float64 with Overflowed set, for callers that cannot tolerate a
+	// silent loss of precision.
+	StrictNumbers bool
+
+	// DuplicateKeysToArray collects the values of a repeated key into an array, for APIs that
+	// repeat a key to mean "append to a list", so {"x": 1, "x": 2} parses as {"x": [1, 2]}. A key
+	// that appears once keeps its value as is. On its second occurrence the value is promoted to
+	// an array holding both values in document order, and later occurrences are appended to it.
+	// Only repetition promotes: a first value that is already an array is nested, so
+	// {"x": [1], "x": 2} parses as {"x": [[1], 2]}. Without this option the last value wins.
+	DuplicateKeysToArray bool
+
+	// MaxDocumentSize caps the number of bytes of input the document may span, counting any
+	// whitespace and comments before it. The lexer stops reading as soon as the limit is passed,
+	// so a malformed stream, such as one missing a closing bracket, cannot make the parser buffer
+	// input indefinitely. Going over the limit is reported as a ParseError naming it. In a stream
+	// read by calling ParseJSON repeatedly, each document is counted from the end of the object or
+	// array before it. A value of zero or less disables the limit.
+	MaxDocumentSize int
+
+	// InternValues also interns string values, for datasets whose values repeat heavily, such as
+	// statuses or enums, so that equal values share a single backing allocation. Values share
+	// the table of KeyInterner; when KeyInterner is nil, an unbounded table is created for each
+	// parse. Use an LRUInterner as KeyInterner to bound the table instead.
+	InternValues bool
+
+	// PreserveFormatting records the source text of every node, whitespace and comments included,
+	// so that MarshalOptions.PreserveFormatting can write the subtrees left unmodified exactly as
+	// they were read, and only reformat the nodes that were changed. This is meant for formatters
+	// and editing tools that must make minimal edits to a document.
+	//
+	// The mode is heavier than normal parsing: the whole input is kept in memory for as long as
+	// the tree is, even when parsing from a reader, and every node holds a small record with a
+	// copy of the members or elements it was parsed with, which roughly doubles the memory used
+	// by the tree. Objects parsed with ParseFields and NormalizeKeysNFC, whose members differ from
+	// their source, and values built by NumberFactory are not recorded and are always reformatted.
+	PreserveFormatting bool
+
+	// Timeout caps the time a single parse may take, as a blunt guard against inputs crafted to be
+	// slow to parse, for callers that do not manage a deadline of their own. Going over it ends
+	// parsing with a ParseError wrapping ErrTimeout. The clock is checked once every
+	// timeoutCheckInterval tokens rather than continuously, so a parse can overrun the timeout by
+	// the time it takes to read that many tokens, or a single very long one, and a read from an
+	// io.Reader that blocks is not interrupted. A value of zero or less disables the limit.
+	Timeout time.Duration
+
+	// OnValue, when set, is called with the JSON Pointer and the value of every value as soon as
+	// it is completed, members and elements in document order and containers after their
+	// contents, so that values can be validated or counted without a second pass over the tree.
+	// A container is passed complete, the root included. Returning an error aborts parsing with
+	// a ParseError at the position of the value that wraps the error. Setting OnValue costs a
+	// call and the formatting of a pointer for every value, which can double the time spent
+	// parsing documents made of many small values.
+	OnValue func(path string, v Value) error
+
+	// StringValidator, when set, is called with the JSON Pointer and the decoded value of every
+	// string value, so that strings can be checked while parsing, such as emails against a
+	// pattern or text for terminal control sequences, rather than in a second pass over the tree.
+	// The value is the one stored in the tree, with its escapes decoded and after
+	// TrimStringValues; object keys are not passed. Returning an error aborts parsing with a
+	// ParseError at the position of the string that wraps the error. Like OnValue, it costs a
+	// call and the formatting of a pointer for every string, on top of whatever the check itself
+	// costs, such as running a regular expression. Strings inside values kept as a RawMessage
+	// under RawPaths or LazyKeys, or left out under ShouldDescend, are not passed.
+	StringValidator func(path, value string) error
+
+	// Arena, when set, is used to allocate the objects, arrays and scalars of the tree, which are
+	// then only valid until the Reset of the arena. See Arena.
+	Arena *Arena
+
+	// RawPaths lists the JSON Pointers, such as /payload or /items/0, of values that are kept as
+	// a RawMessage holding their source text instead of being built into the tree. They are
+	// still checked to be well-formed, but their contents take part in no other option: keys are
+	// neither interned nor counted, and OnValue only sees the RawMessage. The root cannot be raw.
+	// Keeping source text makes a streaming parse retain its input, like PreserveFormatting.
+	RawPaths []string
+
+	// LazyKeys lists object keys whose values are deferred: the parser only checks them to be
+	// well-formed and stores their source text as a RawMessage, which Object.Resolve parses on
+	// first access. A key is deferred in every object of the document, at any depth. This saves
+	// building heavy fields that are usually ignored. An unresolved member is parsed with the same
+	// options when it is marshaled, like any RawMessage made by the parser, and left unresolved in
+	// the tree. Resolution uses the options of the parse except RawPaths, LazyKeys,
+	// OnValue, StringValidator, ShouldDescend, Arena and PreserveFormatting, so it can still
+	// fail, for instance on keys that collide under NormalizeKeysNFC or on an object over
+	// MaxKeysPerObject, or when the bytes of the RawMessage were replaced by malformed JSON.
+	// MaxDepth counts from the deferred value.
+	LazyKeys []string
+
+	// ShouldDescend, when set, is called with the JSON Pointer of every object and array below
+	// the root as the parser is about to enter it, along with its opening token, so that subtrees
+	// can be left out of the tree depending on where they are, more flexibly than with a fixed
+	// list of keys. When it returns false, the parser only checks the subtree to be well-formed,
+	// without building it, and stores a placeholder *Null in its place, which cannot be told apart
+	// from a null written in the document; use RawPaths to keep the source text of a subtree
+	// instead. Subtrees kept as a RawMessage under RawPaths or LazyKeys are not passed to it, and
+	// OnValue sees the placeholder rather than the subtree.
+	ShouldDescend func(path string, openToken Token) bool
+
+	// OnProgress, when set, is called with the number of bytes of input consumed so far and the
+	// total size of the input, for showing the progress of parsing huge files. It is called about
+	// every 64 KiB of input and once more when parsing ends, so its cost is negligible. The
+	// total is the length of a string or []byte input, and for an io.Reader the number of bytes
+	// left to read when the reader can tell, as strings.Reader, bytes.Reader, bytes.Buffer and
+	// regular files can. It is -1 when the size is not known, such as for a pipe, a network
+	// connection or gzip-compressed input.
+	OnProgress func(bytesConsumed, totalBytes int)
+
+	// TrimStringValues removes the leading and trailing whitespace, as defined by
+	// strings.TrimSpace, from every decoded string value, so that a config value written as
+	// " info " reads as "info". This is a non-standard leniency for files edited by hand: JSON
+	// considers the spaces part of the string, so the option changes the meaning of documents
+	// and is off by default. Object keys are never trimmed, and whitespace written as escapes,
+	// such as \n, is trimmed like any other once decoded. Under PreserveFormatting, a string left
+	// unmodified is still written with its source text, spaces included.
+	TrimStringValues bool
+
+	// AllowLeadingZeros accepts numbers padded with leading zeros, such as 007 or -00.5, which
+	// some legacy systems emit but JSON forbids. Such a number keeps its padded literal in Value
+	// and is read as the number it denotes in Int and Float, so 007 holds 7. Since padded literals
+	// are not valid JSON, the marshaler writes them rendered from their value, as 7, except under
+	// MarshalOptions.PreserveFormatting, which writes unmodified numbers with their source text.
+	AllowLeadingZeros bool
+
+	// MaxStringEscapes caps the number of escape sequences, such as \n or \u0041, in a single
+	// string or key, to bound the decoding work an adversarial document full of escapes can make
+	// the parser do, independently of its length. Strings are decoded in linear time, so the
+	// limit is off by default; parsing fails fast with a ParseError at the position of the string
+	// as soon as a string goes over it. Both halves of a surrogate pair count. A value of zero or
+	// less disables the limit.
+	MaxStringEscapes int
+
+	// DisallowEmptyKeys reports the empty string used as an object key, as in {"": 1}, as a
+	// ParseError at the position of the key. JSON allows such keys, and by default they are kept
+	// like any other: Get("") retrieves them, as do the JSON Pointer "/" and the dotted paths
+	// described under GetOr. Callers whose own path handling cannot represent them can use this
+	// option to reject them up front.
+	DisallowEmptyKeys bool
+
+	// StrictRFC8259 makes the parser accept exactly the JSON texts defined by RFC 8259, for
+	// conformance testing, with a single switch. On top of the checks always made, which reject
+	// invalid numbers such as 01, 1. or +1, literals other than true, false and null, and a byte
+	// order mark anywhere in the input, it:
+	//
+	//   - accepts any value as the document, such as 42 or "text", rather than only an object or
+	//     an array, as RFC 8259 allows;
+	//   - reports any content after the document other than whitespace, such as a second
+	//     document, so that ParseJSON reads a single document and cannot be called again;
+	//   - reports a control character, U+0000 to U+001F, written unescaped in a string or key;
+	//   - reports invalid UTF-8 in a string or key as an EncodingError, instead of decoding it to
+	//     U+FFFD;
+	//   - reports a NUL byte outside a string, instead of taking it for the end of the input.
+	//
+	// Duplicate keys are still accepted, the last value winning, since RFC 8259 only says that
+	// names SHOULD be unique. The options that relax JSON, such as AllowComments, Keywords,
+	// AllowLeadingZeros or EmptyAsNull, are not turned off and should be left unset.
+	//
+	// The limits of this implementation, which RFC 8259 permits in its section 9, still apply: a
+	// number too large for a float64, such as 1.5e+9999 or 0.4e00669999999999, is rejected, as is
+	// one longer than MaxNumberLength, while one too small, such as 1e-9999, is read as zero.
+	// These are among the cases the JSONTestSuite leaves to each implementation.
+	StrictRFC8259 bool
+}
+
+// DefaultParserOptions returns the options NewParser starts from: strict JSON, with
+// MaxNumberLength set to DefaultMaxNumberLength.
+func DefaultParserOptions() ParserOptions {
+	return ParserOptions{MaxNumberLength: DefaultMaxNumberLength}
+}
+
+// Option configures a Parser. Options are applied in order by NewParser and Parse.
+type Option func(*ParserOptions)
+
+// WithMaxNumberLength sets MaxNumberLength. A value of zero or less disables the limit.
+func WithMaxNumberLength(n int) Option {
+	return func(o *ParserOptions) {
+		o.MaxNumberLength = n
+	}
+}
+
+// WithEmptyAsNull makes an empty document parse as null. See EmptyAsNull.
+func WithEmptyAsNull() Option {
+	return func(o *ParserOptions) {
+		o.EmptyAsNull = true
+	}
+}
+
+// WithComments accepts // and /* */ comments. See AllowComments.
+func WithComments() Option {
+	return func(o *ParserOptions) {
+		o.AllowComments = true
+	}
+}
+
+// WithRetainedComments accepts comments and attaches them to the AST. See RetainComments.
+func WithRetainedComments() Option {
+	return func(o *ParserOptions) {
+		o.AllowComments = true
+		o.RetainComments = true
+	}
+}
+
+// WithArrayHint sets ArrayHint, the capacity reserved for the first array of the document.
+func WithArrayHint(n int) Option {
+	return func(o *ParserOptions) {
+		o.ArrayHint = n
+	}
+}
+
+// WithNormalizedKeys normalizes keys to NFC and rejects collisions. See NormalizeKeysNFC.
+func WithNormalizedKeys() Option {
+	return func(o *ParserOptions) {
+		o.NormalizeKeysNFC = true
+	}
+}
+
+// WithKeyInterner interns object keys with interner. See KeyInterner.
+func WithKeyInterner(interner Interner) Option {
+	return func(o *ParserOptions) {
+		o.KeyInterner = interner
+	}
+}
+
+// WithNumberFactory builds numbers with factory. See NumberFactory.
+func WithNumberFactory(factory func(literal string) (Value, error)) Option {
+	return func(o *ParserOptions) {
+		o.NumberFactory = factory
+	}
+}
+
+// WithMaxKeysPerObject sets MaxKeysPerObject. A value of zero or less disables the limit.
+func WithMaxKeysPerObject(n int) Option {
+	return func(o *ParserOptions) {
+		o.MaxKeysPerObject = n
+	}
+}
+
+// WithMaxArrayLength sets MaxArrayLength. A value of zero or less disables the limit.
+func WithMaxArrayLength(n int) Option {
+	return func(o *ParserOptions) {
+		o.MaxArrayLength = n
+	}
+}
+
+// WithMaxStringEscapes sets MaxStringEscapes. A value of zero or less disables the limit.
+func WithMaxStringEscapes(n int) Option {
+	return func(o *ParserOptions) {
+		o.MaxStringEscapes = n
+	}
+}
+
+// WithoutEmptyKeys rejects empty object keys. See DisallowEmptyKeys.
+func WithoutEmptyKeys() Option {
+	return func(o *ParserOptions) {
+		o.DisallowEmptyKeys = true
+	}
+}
+
+// WithStrictRFC8259 makes the parser accept exactly the JSON texts of RFC 8259. See
+// StrictRFC8259.
+func WithStrictRFC8259() Option {
+	return func(o *ParserOptions) {
+		o.StrictRFC8259 = true
+	}
+}
+
+// WithKeywords accepts the barewords in keywords. See Keywords.
+func WithKeywords(keywords map[string]Value) Option {
+	return func(o *ParserOptions) {
+		o.Keywords = keywords
+	}
+}
+
+// WithMaxDepth sets MaxDepth. A value of zero or less disables the limit.
+func WithMaxDepth(n int) Option {
+	return func(o *ParserOptions) {
+		o.MaxDepth = n
+	}
+}
+
+// WithStrictNumbers rejects integers that overflow int64. See StrictNumbers.
+func WithStrictNumbers() Option {
+	return func(o *ParserOptions) {
+		o.StrictNumbers = true
+	}
+}
+
+// WithDuplicateKeysToArray collects the values of repeated keys into arrays. See
+// DuplicateKeysToArray.
+func WithDuplicateKeysToArray() Option {
+	return func(o *ParserOptions) {
+		o.DuplicateKeysToArray = true
+	}
+}
+
+// WithMaxDocumentSize sets MaxDocumentSize. A value of zero or less disables the limit.
+func WithMaxDocumentSize(n int) Option {
+	return func(o *ParserOptions) {
+		o.MaxDocumentSize = n
+	}
+}
+
+// WithInternValues interns string values as well as keys. See InternValues.
+func WithInternValues() Option {
+	return func(o *ParserOptions) {
+		o.InternValues = true
+	}
+}
+
+// WithPreserveFormatting records the source text of every node. See PreserveFormatting.
+func WithPreserveFormatting() Option {
+	return func(o *ParserOptions) {
+		o.PreserveFormatting = true
+	}
+}
+
+// WithTimeout sets Timeout. A value of zero or less disables the limit.
+func WithTimeout(d time.Duration) Option {
+	return func(o *ParserOptions) {
+		o.Timeout = d
+	}
+}
+
+// WithOnValue calls fn for every value completed during parsing. See OnValue.
+func WithOnValue(fn func(path string, v Value) error) Option {
+	return func(o *ParserOptions) {
+		o.OnValue = fn
+	}
+}
+
+// WithStringValidator calls fn for every string value decoded during parsing. See
+// StringValidator.
+func WithStringValidator(fn func(path, value string) error) Option {
+	return func(o *ParserOptions) {
+		o.StringValidator = fn
+	}
+}
+
+// WithArena allocates the nodes of the tree from arena. See Arena.
+func WithArena(arena *Arena) Option {
+	return func(o *ParserOptions) {
+		o.Arena = arena
+	}
+}
+
+// WithRawPaths keeps the values at the given JSON Pointers as RawMessage nodes. See RawPaths.
+func WithRawPaths(pointers ...string) Option {
+	return func(o *ParserOptions) {
+		o.RawPaths = pointers
+	}
+}
+
+// WithLazyKeys defers the values of the given object keys until Object.Resolve. See LazyKeys.
+func WithLazyKeys(keys ...string) Option {
+	return func(o *ParserOptions) {
+		o.LazyKeys = keys
+	}
+}
+
+// WithShouldDescend leaves out of the tree the subtrees for which fn returns false. See
+// ShouldDescend.
+func WithShouldDescend(fn func(path string, openToken Token) bool) Option {
+	return func(o *ParserOptions) {
+		o.ShouldDescend = fn
+	}
+}
+
+// WithOnProgress reports the progress of parsing to fn. See OnProgress.
+func WithOnProgress(fn func(bytesConsumed, totalBytes int)) Option {
+	return func(o *ParserOptions) {
+		o.OnProgress = fn
+	}
+}
+
+// WithLeadingZeros accepts numbers padded with leading zeros. See AllowLeadingZeros.
+func WithLeadingZeros() Option {
+	return func(o *ParserOptions) {
+		o.AllowLeadingZeros = true
+	}
+}
+
+// WithTrimmedStrings trims the whitespace around string values. See TrimStringValues.
+func WithTrimmedStrings() Option {
+	return func(o *ParserOptions) {
+		o.TrimStringValues = true
+	}
+}
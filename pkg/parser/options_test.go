@@ -0,0 +1,404 @@
+package parser_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestParserOptions(t *testing.T) {
+	p := parser.NewParser(parser.NewLexer(`[]`))
+	if !reflect.DeepEqual(p.ParserOptions, parser.DefaultParserOptions()) {
+		t.Errorf("Expected default options, got %+v", p.ParserOptions)
+	}
+
+	interner := parser.NewMapInterner()
+
+	p = parser.NewParser(parser.NewLexer(`[]`),
+		parser.WithMaxNumberLength(10),
+		parser.WithEmptyAsNull(),
+		parser.WithRetainedComments(),
+		parser.WithArrayHint(5),
+		parser.WithNormalizedKeys(),
+		parser.WithKeyInterner(interner),
+		parser.WithMaxKeysPerObject(3),
+		parser.WithMaxDepth(4),
+	)
+
+	expected := parser.ParserOptions{
+		MaxNumberLength:  10,
+		EmptyAsNull:      true,
+		AllowComments:    true,
+		RetainComments:   true,
+		ArrayHint:        5,
+		NormalizeKeysNFC: true,
+		KeyInterner:      interner,
+		MaxKeysPerObject: 3,
+		MaxDepth:         4,
+	}
+
+	if !reflect.DeepEqual(p.ParserOptions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, p.ParserOptions)
+	}
+
+	// Options given to Parse are applied too
+	if _, err := parser.Parse(`{"a": /* note */ 1}`, parser.WithComments()); err != nil {
+		t.Errorf("Expected WithComments to allow comments, got %v", err)
+	}
+
+	if _, err := parser.Parse(`{"a": /* note */ 1}`); err == nil {
+		t.Error("Expected strict JSON by default")
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxDepth int
+		expected string
+	}{
+		{"Within the limit", `{"a": [[1]]}`, 3, ""},
+		{"Over the limit", `{"a": [[[1]]]}`, 3, "Line 1, Column 9: maximum nesting depth of 3 exceeded"},
+		{"Siblings do not add up", `[[1], [2], {"a": [3]}]`, 3, ""},
+		{"Disabled", strings.Repeat("[", 500) + strings.Repeat("]", 500), 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input, parser.WithMaxDepth(tt.maxDepth))
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// Subtrees checked without being built count toward the limit too
+	skipped := []parser.Option{
+		parser.WithMaxDepth(3),
+		parser.WithShouldDescend(func(string, parser.Token) bool { return false }),
+	}
+
+	for _, opts := range [][]parser.Option{skipped, {parser.WithMaxDepth(3), parser.WithRawPaths("/a")}} {
+		expected := "Line 1, Column 9: maximum nesting depth of 3 exceeded"
+		if _, err := parser.Parse(`{"a": [[[1]]]}`, opts...); err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestMaxDepthReached(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxDepth int
+		expected int
+	}{
+		{"Flat", `{"a": 1, "b": "x"}`, 0, 1},
+		{"Nested", `{"a": [1, {"b": [[]]}]}`, 0, 5},
+		{"Deepest branch", `[[1], [[2]], {"a": 3}]`, 0, 3},
+		{"Stopped at the limit", `[[[[1]]]]`, 2, 2},
+		{"Deep", strings.Repeat("[", 500) + strings.Repeat("]", 500), 0, 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(tt.input), parser.WithMaxDepth(tt.maxDepth))
+			_, _ = p.ParseJSON()
+
+			if got := p.MaxDepthReached(); got != tt.expected {
+				t.Errorf("Expected a depth of %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	large := "[" + strings.Repeat("1, ", 10000) + "1]"
+
+	tests := []struct {
+		name     string
+		input    string
+		timeout  time.Duration
+		expected bool
+	}{
+		{"Over the timeout", large, time.Nanosecond, true},
+		{"Within the timeout", large, time.Minute, false},
+		{"Checked periodically", `{"a": [1, 2, 3]}`, time.Nanosecond, false},
+		{"Disabled", large, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input, parser.WithTimeout(tt.timeout))
+			if !tt.expected {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, parser.ErrTimeout) {
+				t.Fatalf("Expected ErrTimeout, got %v", err)
+			}
+
+			if !strings.HasSuffix(err.Error(), "parsing exceeded timeout of 1ns") {
+				t.Errorf("Expected the timeout in the message, got %q", err)
+			}
+		})
+	}
+}
+
+func TestOnValue(t *testing.T) {
+	t.Run("Parse order", func(t *testing.T) {
+		var visited []string
+
+		_, err := parser.Parse(`{"a": [1, {"b": true}], "c": null}`, parser.WithOnValue(func(path string, v parser.Value) error {
+			visited = append(visited, path+"="+v.TokenLiteral())
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{"/a/0=1", "/a/1/b=true", "/a/1={", "/a=[", "/c=null", "={"}
+		if !reflect.DeepEqual(visited, expected) {
+			t.Errorf("Expected %v, got %v", expected, visited)
+		}
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		errTooLarge := errors.New("number above 100")
+
+		_, err := parser.Parse("{\"items\": [5,\n 500, 7]}", parser.WithOnValue(func(_ string, v parser.Value) error {
+			if n, ok := v.(*parser.NumberLiteral); ok && n.Float > 100 {
+				return errTooLarge
+			}
+
+			return nil
+		}))
+
+		if !errors.Is(err, errTooLarge) {
+			t.Fatalf("Expected the callback error, got %v", err)
+		}
+
+		var parseErr *parser.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Error() != "Line 2, Column 2: number above 100" || parseErr.Path != "/items/1" {
+			t.Errorf("Expected the error at 2:2 and /items/1, got %v at %q", err, parseErr.Path)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		count := 0
+
+		stream, err := parser.StreamArray(strings.NewReader(`[1, [2, 3], {"a": 4}]`), parser.WithOnValue(func(string, parser.Value) error {
+			count++
+			return nil
+		}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		elements := 0
+		for range stream.All() {
+			elements++
+		}
+
+		if err := stream.Err(); err != nil || elements != 3 || count != 6 {
+			t.Errorf("Expected 3 elements and 6 values, got %d and %d (%v)", elements, count, err)
+		}
+	})
+}
+
+func TestStringValidator(t *testing.T) {
+	email := regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+	errNotEmail := errors.New("not an email address")
+
+	validator := func(path, value string) error {
+		if strings.HasSuffix(path, "/email") && !email.MatchString(value) {
+			return errNotEmail
+		}
+
+		return nil
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		var visited []string
+
+		_, err := parser.Parse(`{"users": [{"email": "ana@example.com", "name": "Ana"}], "email": "b\u0040c"}`,
+			parser.WithStringValidator(func(path, value string) error {
+				visited = append(visited, path+"="+value)
+				return validator(path, value)
+			}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Keys are not passed, and the value is decoded
+		expected := []string{"/users/0/email=ana@example.com", "/users/0/name=Ana", "/email=b@c"}
+		if !reflect.DeepEqual(visited, expected) {
+			t.Errorf("Expected %v, got %v", expected, visited)
+		}
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		_, err := parser.Parse("{\"users\": [\n  {\"email\": \"ana\"}]}", parser.WithStringValidator(validator))
+		if !errors.Is(err, errNotEmail) {
+			t.Fatalf("Expected the validator error, got %v", err)
+		}
+
+		var parseErr *parser.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Error() != "Line 2, Column 13: not an email address" || parseErr.Path != "/users/0/email" {
+			t.Errorf("Expected the error at 2:13 and /users/0/email, got %v at %q", err, parseErr.Path)
+		}
+	})
+
+	t.Run("Trimmed", func(t *testing.T) {
+		_, err := parser.Parse(`{"email": " ana@example.com "}`, parser.WithStringValidator(validator), parser.WithTrimmedStrings())
+		if err != nil {
+			t.Errorf("Expected the trimmed value to be validated, got %v", err)
+		}
+	})
+
+	t.Run("Skipped", func(t *testing.T) {
+		var visited []string
+
+		_, err := parser.Parse(`{"users": [{"email": "ana"}], "email": "b@c"}`,
+			parser.WithShouldDescend(func(path string, _ parser.Token) bool { return path != "/users" }),
+			parser.WithStringValidator(func(path, value string) error {
+				visited = append(visited, path)
+				return validator(path, value)
+			}))
+		if err != nil {
+			t.Fatalf("Expected strings left out under ShouldDescend not to be validated, got %v", err)
+		}
+
+		if expected := []string{"/email"}; !reflect.DeepEqual(visited, expected) {
+			t.Errorf("Expected %v, got %v", expected, visited)
+		}
+	})
+}
+
+func TestOnProgress(t *testing.T) {
+	input := "[" + strings.Repeat(`"abcdefghij", `, 20000) + "1]"
+
+	tests := []struct {
+		name  string
+		parse func(opt parser.Option) error
+		total int
+	}{
+		{"String", func(opt parser.Option) error {
+			_, err := parser.Parse(input, opt)
+			return err
+		}, len(input)},
+		{"Sized reader", func(opt parser.Option) error {
+			_, err := parser.ParseReader(strings.NewReader(input), opt)
+			return err
+		}, len(input)},
+		{"Unknown size", func(opt parser.Option) error {
+			_, err := parser.ParseReader(struct{ io.Reader }{strings.NewReader(input)}, opt)
+			return err
+		}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][2]int
+
+			err := tt.parse(parser.WithOnProgress(func(consumed, total int) {
+				calls = append(calls, [2]int{consumed, total})
+			}))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Throttled to about one call every 64 KiB, plus the final one
+			if len(calls) < 2 || len(calls) > len(input)/(64<<10)+2 {
+				t.Fatalf("Expected a throttled number of calls, got %d", len(calls))
+			}
+
+			for i, call := range calls {
+				if call[1] != tt.total {
+					t.Errorf("Expected a total of %d, got %d", tt.total, call[1])
+				}
+
+				if i > 0 && call[0] < calls[i-1][0] {
+					t.Errorf("Expected progress to never go back, got %v", calls)
+				}
+			}
+
+			if last := calls[len(calls)-1]; last[0] != len(input) {
+				t.Errorf("Expected the final call to report the whole input, got %d", last[0])
+			}
+		})
+	}
+}
+
+func TestShouldDescend(t *testing.T) {
+	type call struct {
+		path  string
+		token parser.TokenType
+	}
+
+	var calls []call
+
+	input := `{"keep": {"a": [1]}, "skip": {"big": [1, 2, {"x": null}]}, "list": [{"id": 1}, [true]]}`
+
+	v, err := parser.Parse(input, parser.WithShouldDescend(func(path string, openToken parser.Token) bool {
+		calls = append(calls, call{path, openToken.Type})
+		return path != "/skip" && path != "/list/1"
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, _ := parser.Marshal(v)
+	if expected := `{"keep":{"a":[1]},"list":[{"id":1},null],"skip":null}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	// The root is not passed, nor is anything inside a skipped subtree
+	expected := []call{
+		{"/keep", parser.TokenBraceOpen},
+		{"/keep/a", parser.TokenBracketOpen},
+		{"/skip", parser.TokenBraceOpen},
+		{"/list", parser.TokenBracketOpen},
+		{"/list/0", parser.TokenBraceOpen},
+		{"/list/1", parser.TokenBracketOpen},
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+
+	skipAll := parser.WithShouldDescend(func(string, parser.Token) bool { return false })
+
+	_, err = parser.Parse(`{"a": {"b": [1, 2}}`, skipAll)
+	if err == nil || err.Error() != "Line 1, Column 18: expected , or ], got }" {
+		t.Errorf("Expected a skipped subtree to be checked, got %v", err)
+	}
+
+	var seen []string
+
+	_, err = parser.Parse(`{"a": {"b": 1}, "c": 2}`, skipAll, parser.WithOnValue(func(path string, v parser.Value) error {
+		seen = append(seen, path+"="+v.TokenLiteral())
+		return nil
+	}))
+	if err != nil || !reflect.DeepEqual(seen, []string{"/a=null", "/c=2", "={"}) {
+		t.Errorf("Expected OnValue to see the placeholder, got %v, %v", seen, err)
+	}
+}
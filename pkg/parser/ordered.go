@@ -0,0 +1,214 @@
+package parser
+
+// OrderedMap is a JSON object decoded by ParseOrderedMap: its members in document order, holding
+// plain Go values rather than AST nodes. It is lighter than an Object, which records the tokens,
+// comments and source positions of its members but not their order, and is meant for callers
+// that need the order of keys but none of the rest, such as to rewrite a config with its keys
+// where the user put them. Use Parse for the full AST instead.
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewOrderedMap returns an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: make(map[string]any)}
+}
+
+// Get returns the value stored under key and whether it was present. It is safe to call on a nil
+// map, which has no keys.
+func (m *OrderedMap) Get(key string) (any, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	v, ok := m.values[key]
+
+	return v, ok
+}
+
+// Set stores v under key and returns m, so that calls can be chained. A new key is added after
+// the existing ones, while an existing key keeps its position and has its value replaced.
+func (m *OrderedMap) Set(key string, v any) *OrderedMap {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = v
+
+	return m
+}
+
+// Keys returns the keys of m in order. The returned slice is a copy. It returns an empty slice
+// for an empty or nil map.
+func (m *OrderedMap) Keys() []string {
+	if m == nil {
+		return []string{}
+	}
+
+	return append([]string{}, m.keys...)
+}
+
+// Len returns the number of members of m, or zero for a nil map.
+func (m *OrderedMap) Len() int {
+	if m == nil {
+		return 0
+	}
+
+	return len(m.keys)
+}
+
+// Filter returns a new map holding only the members of m for which keep returns true, in the
+// order of m. keep is called in that order too. The values are shared with m rather than copied,
+// and m itself is left untouched. Filter on a nil map returns an empty map.
+func (m *OrderedMap) Filter(keep func(key string, v any) bool) *OrderedMap {
+	return m.Transform(func(key string, v any) (any, bool) {
+		return v, keep(key, v)
+	})
+}
+
+// Transform returns a new map holding, for each member of m in order, the value fn returns for
+// it under the same key, leaving out the members for which fn reports false. fn is called in the
+// order of m. m itself is left untouched. Transform on a nil map returns an empty map.
+func (m *OrderedMap) Transform(fn func(key string, v any) (any, bool)) *OrderedMap {
+	result := NewOrderedMap()
+	if m == nil {
+		return result
+	}
+
+	for _, k := range m.keys {
+		if v, ok := fn(k, m.values[k]); ok {
+			result.Set(k, v)
+		}
+	}
+
+	return result
+}
+
+// Merge returns a new map combining m with other, like DeepMergeAll does for objects: a key of
+// other that m already has keeps its position in m and takes the value of other, and the keys
+// only in other are added after those of m, in the order of other. Maps held under the same key
+// by both are merged the same way, recursively, while any other value of other replaces the one
+// of m wholesale. The values that are not merged are shared with m and other, which are left
+// untouched. A nil map is taken as empty.
+func (m *OrderedMap) Merge(other *OrderedMap) *OrderedMap {
+	result := m.Filter(func(string, any) bool { return true })
+	if other == nil {
+		return result
+	}
+
+	for _, k := range other.keys {
+		v := other.values[k]
+
+		if nested, ok := v.(*OrderedMap); ok {
+			if existing, ok := result.values[k].(*OrderedMap); ok {
+				v = existing.Merge(nested)
+			}
+		}
+
+		result.Set(k, v)
+	}
+
+	return result
+}
+
+// ParseOrderedMap parses the JSON object in input, configured by opts, into an OrderedMap, with
+// nested objects decoded as *OrderedMap, arrays as []any, strings as string, numbers as
+// json.Number, which keeps their literal so that no precision is lost, booleans as bool and null
+// as nil. A value built by Keywords or NumberFactory that is not a plain string, number, boolean
+// or null is stored as the Value it returned. A key repeated in an object keeps its first
+// position and its last value, as the last value wins in an Object. The document must be an
+// object. It is read by the same parser as Parse, so errors are the same ParseErrors and the
+// options about the syntax and the limits apply: comments, Keywords, AllowLeadingZeros, the
+// Max options and DisallowEmptyKeys. The options that shape the AST, such as
+// DuplicateKeysToArray, NormalizeKeysNFC or RawPaths, do not.
+func ParseOrderedMap(input string, opts ...Option) (*OrderedMap, error) {
+	p := NewParser(NewLexer(input), opts...)
+	p.DuplicateKeysToArray, p.NormalizeKeysNFC, p.PreserveFormatting = false, false, false
+	p.RawPaths, p.LazyKeys = nil, nil
+	p.OnValue, p.Arena, p.ShouldDescend = nil, nil, nil
+	p.keyOrder = make(map[*Object][]string)
+	p.start()
+
+	if !p.reserveDocument() {
+		return nil, p.errors[0]
+	}
+
+	defer p.releaseDocument()
+
+	if p.currentToken.Type != TokenBraceOpen {
+		if p.currentToken.Type == TokenEOF {
+			p.addError("unexpected end of input: empty document")
+		} else {
+			p.addError("expected {, got %s", p.currentToken.Type)
+		}
+
+		return nil, p.errors[0]
+	}
+
+	// The object is parsed like any other, then converted with the order recorded of its keys
+	value := p.parseContainer()
+	if p.failed() {
+		return nil, p.errors[0]
+	}
+
+	return orderedObject(value.(*Object), p.keyOrder), nil
+}
+
+// orderedObject converts the object root to an OrderedMap, taking the order of the keys of each
+// object from order. Nested containers are converted from an explicit stack rather than by
+// recursion, like they are parsed.
+func orderedObject(root *Object, order map[*Object][]string) *OrderedMap {
+	type pending struct {
+		object   *Object
+		m        *OrderedMap
+		array    *Array
+		elements []any
+	}
+
+	m := NewOrderedMap()
+	stack := []pending{{object: root, m: m}}
+
+	// convert returns v as a plain Go value, pushing the contents of a container to convert later
+	convert := func(v Value) any {
+		switch val := v.(type) {
+		case *Object:
+			nested := NewOrderedMap()
+			stack = append(stack, pending{object: val, m: nested})
+
+			return nested
+		case *Array:
+			elements := make([]any, len(val.Elements))
+			stack = append(stack, pending{array: val, elements: elements})
+
+			return elements
+		case *StringLiteral:
+			return val.Value
+		case *NumberLiteral:
+			return val.JSONNumber()
+		case *Boolean:
+			return val.Value
+		case *Null, nil:
+			return nil
+		default:
+			return val
+		}
+	}
+
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if next.object != nil {
+			for _, key := range order[next.object] {
+				next.m.Set(key, convert(next.object.Pairs[key]))
+			}
+		} else {
+			for i, elem := range next.array.Elements {
+				next.elements[i] = convert(elem)
+			}
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,212 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestParseOrderedMap(t *testing.T) {
+	m, err := parser.ParseOrderedMap(`{
+		"zeta": 1,
+		"alpha": {"y": true, "x": null},
+		"mid": ["s", 9007199254740993, {"b": 1, "a": 2}, []],
+		"zeta": 2
+	}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(m.Keys(), expected) {
+		t.Errorf("Expected keys %v, got %v", expected, m.Keys())
+	}
+
+	if v, _ := m.Get("zeta"); v != json.Number("2") {
+		t.Errorf("Expected a repeated key to keep its last value, got %#v", v)
+	}
+
+	alpha, _ := m.Get("alpha")
+	if keys := alpha.(*parser.OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"y", "x"}) {
+		t.Errorf("Expected nested keys in document order, got %v", keys)
+	}
+
+	if x, ok := alpha.(*parser.OrderedMap).Get("x"); !ok || x != nil {
+		t.Errorf("Expected null to decode as nil, got %#v, %v", x, ok)
+	}
+
+	mid, _ := m.Get("mid")
+
+	elements := mid.([]any)
+	if len(elements) != 4 || elements[0] != "s" || elements[1] != json.Number("9007199254740993") {
+		t.Fatalf("Unexpected array %#v", elements)
+	}
+
+	if keys := elements[2].(*parser.OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+		t.Errorf("Expected keys of objects in arrays in document order, got %v", keys)
+	}
+
+	if empty, ok := elements[3].([]any); !ok || len(empty) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", elements[3])
+	}
+
+	m.Set("alpha", "replaced").Set("new", 1)
+	if expected := []string{"zeta", "alpha", "mid", "new"}; !reflect.DeepEqual(m.Keys(), expected) {
+		t.Errorf("Expected Set to keep existing positions and append new keys, got %v", m.Keys())
+	}
+
+	var nilMap *parser.OrderedMap
+	if _, ok := nilMap.Get("a"); ok || nilMap.Len() != 0 || len(nilMap.Keys()) != 0 {
+		t.Error("Expected a nil map to have no keys")
+	}
+}
+
+func TestOrderedMapOperations(t *testing.T) {
+	parse := func(input string) *parser.OrderedMap {
+		t.Helper()
+
+		m, err := parser.ParseOrderedMap(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return m
+	}
+
+	base := parse(`{"zeta": 1, "alpha": {"y": 1, "x": 2}, "mid": 3}`)
+
+	var visited []string
+
+	filtered := base.Filter(func(key string, _ any) bool {
+		visited = append(visited, key)
+		return key != "alpha"
+	})
+
+	if expected := []string{"zeta", "mid"}; !reflect.DeepEqual(filtered.Keys(), expected) {
+		t.Errorf("Expected Filter to keep the order, got %v", filtered.Keys())
+	}
+
+	if expected := []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Expected keep to be called in order, got %v", visited)
+	}
+
+	transformed := base.Transform(func(key string, v any) (any, bool) {
+		return strings.ToUpper(key), key != "zeta"
+	})
+
+	if expected := []string{"alpha", "mid"}; !reflect.DeepEqual(transformed.Keys(), expected) {
+		t.Errorf("Expected Transform to keep the order, got %v", transformed.Keys())
+	}
+
+	if v, _ := transformed.Get("mid"); v != "MID" {
+		t.Errorf("Expected the transformed value, got %#v", v)
+	}
+
+	merged := base.Merge(parse(`{"new": 1, "alpha": {"w": 0, "x": 9}, "zeta": 2, "last": 3}`))
+
+	if expected := []string{"zeta", "alpha", "mid", "new", "last"}; !reflect.DeepEqual(merged.Keys(), expected) {
+		t.Errorf("Expected existing keys to keep their position and new ones appended, got %v", merged.Keys())
+	}
+
+	alpha, _ := merged.Get("alpha")
+	if expected := []string{"y", "x", "w"}; !reflect.DeepEqual(alpha.(*parser.OrderedMap).Keys(), expected) {
+		t.Errorf("Expected nested maps merged in order, got %v", alpha.(*parser.OrderedMap).Keys())
+	}
+
+	if x, _ := alpha.(*parser.OrderedMap).Get("x"); x != json.Number("9") {
+		t.Errorf("Expected the later value to win, got %#v", x)
+	}
+
+	if expected := []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(base.Keys(), expected) || base.Len() != 3 {
+		t.Errorf("Expected the original to be untouched, got %v", base.Keys())
+	}
+
+	if y, _ := base.Get("alpha"); y.(*parser.OrderedMap).Len() != 2 {
+		t.Error("Expected the nested map of the original to be untouched")
+	}
+
+	var nilMap *parser.OrderedMap
+	if got := nilMap.Merge(base); !reflect.DeepEqual(got.Keys(), base.Keys()) {
+		t.Errorf("Expected merging into a nil map to give the other map, got %v", got.Keys())
+	}
+
+	if got := base.Merge(nil); !reflect.DeepEqual(got.Keys(), base.Keys()) {
+		t.Errorf("Expected merging a nil map to give a copy, got %v", got.Keys())
+	}
+}
+
+func TestParseOrderedMapErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []parser.Option
+		expected string
+	}{
+		{"Empty", ``, nil, "Line 1, Column 0: unexpected end of input: empty document"},
+		{"Trailing comma", `{"a": [1, 2,]}`, nil, "Line 1, Column 12: unexpected , before ]"},
+		{"Missing colon", `{"a" 1}`, nil, `Line 1, Column 6: expected : after key "a", got NUMBER`},
+		{"Comments need the option", `{"a": 1 /* c */}`, nil, ""},
+		{"Depth", `{"a": {"b": {}}}`, []parser.Option{parser.WithMaxDepth(2)}, "Line 1, Column 13: maximum nesting depth of 2 exceeded"},
+		{"Keys", `{"a": {"b": 1, "c": 2}}`, []parser.Option{parser.WithMaxKeysPerObject(1)}, "Line 1, Column 7: object exceeds maximum of 1 keys"},
+		{"Elements", `{"a": [1, 2]}`, []parser.Option{parser.WithMaxArrayLength(1)}, "Line 1, Column 7: array exceeds maximum of 1 elements"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.ParseOrderedMap(tt.input, tt.opts...)
+
+			// The errors are those Parse reports for the same document
+			_, expected := parser.Parse(tt.input, tt.opts...)
+			if err == nil || expected == nil || err.Error() != expected.Error() {
+				t.Errorf("Expected error %v, got %v", expected, err)
+			}
+
+			if tt.expected != "" && (err == nil || err.Error() != tt.expected) {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if _, err := parser.ParseOrderedMap(`[1]`); err == nil || err.Error() != "Line 1, Column 1: expected {, got [" {
+		t.Errorf("Expected an array document to be rejected, got %v", err)
+	}
+
+	m, err := parser.ParseOrderedMap(`{"a": 1 /* c */}`, parser.WithComments())
+	if err != nil || m.Len() != 1 {
+		t.Errorf("Expected comments to be accepted with the option, got %v, %v", m, err)
+	}
+}
+
+func TestParseOrderedMapDeepNesting(t *testing.T) {
+	// A recursive parser needs far more than this for so many levels
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	const depth = 100000
+
+	input := strings.Repeat(`{"a": [`, depth) + strings.Repeat("]}", depth)
+
+	m, err := parser.ParseOrderedMap(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	levels := 0
+
+	for m != nil {
+		levels++
+
+		v, _ := m.Get("a")
+		m = nil
+
+		if elements := v.([]any); len(elements) > 0 {
+			m = elements[0].(*parser.OrderedMap)
+		}
+	}
+
+	if levels != depth {
+		t.Errorf("Expected %d levels, got %d", depth, levels)
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"unsafe"
+)
+
+// Parse parses a complete JSON document from input, configured by opts.
+func Parse(input string, opts ...Option) (Value, error) {
+	return NewParser(NewLexer(input), opts...).ParseJSON()
+}
+
+// ParsePartial parses input like Parse but, when the document is malformed or truncated, also
+// returns the part of the tree built before the first error, together with that error. This is
+// useful to preview documents that are still being written, such as a file being tailed.
+//
+// The result is explicitly lossy and not guaranteed to be well-formed: a container that was cut
+// short holds only what was parsed before the error, nested containers included, while a scalar
+// that failed to parse is omitted. The returned value is nil if nothing could be parsed.
+func ParsePartial(input string, opts ...Option) (Value, *ParseError) {
+	p := NewParser(NewLexer(input), opts...)
+
+	value := p.parseDocument()
+	if p.failed() {
+		return value, p.errors[0]
+	}
+
+	return value, nil
+}
+
+// ParseEmbedded parses one JSON value embedded in the text s, such as the object in the log line
+// `user=ana data={"a": 1} ok`, and returns it with the offset in s of the byte just after it, so
+// that a text can be walked value by value by passing that offset back in. Starting at offset, it
+// skips the text up to the first character that can start a JSON value: an opening brace or
+// bracket, a quote, or, at the start of a word, a digit, a minus sign, true, false or null, so the
+// 42 in "user42" is not taken for a number. The value is then parsed like a document would be,
+// so braces and brackets inside its strings are never taken as the end of it, and the text after
+// it is left unread.
+//
+// A number or literal that turns out to be malformed, such as the "-" of " - " or the start of
+// "nullable", is skipped like the rest of the text. A malformed object, array or string is
+// reported as a *ParseError, with the offset just after its first character so that the walk can
+// carry on. Going over the memory budget is reported with the offset of the value, so that it
+// can be parsed again. When no value starts at or after offset, ParseEmbedded returns io.EOF and
+// len(s).
+func ParseEmbedded(s string, offset int, opts ...Option) (Value, int, error) {
+	at, line, column := 0, 1, 1
+
+	for start := embeddedStart(s, offset); start >= 0; start = embeddedStart(s, start+1) {
+		// Counted on from the previous candidate rather than from the start of s
+		line, column = advancePosition(s, at, start, line, column)
+		at = start
+
+		lexer := newLexerFrom(s, start, line, column)
+		lexer.preserve = true // for the end offset of the value
+
+		p := NewParser(lexer, opts...)
+		p.start()
+
+		p.budgetFrom = start // only the input from the value on is charged
+		if !p.reserveDocument() {
+			return nil, start, p.errors[0]
+		}
+
+		value := p.parseAny()
+		p.releaseDocument()
+
+		if !p.failed() {
+			return value, p.currentToken.end, nil
+		}
+
+		switch s[start] {
+		case '{', '[', '"':
+			return nil, start + 1, p.errors[0]
+		}
+	}
+
+	return nil, len(s), io.EOF
+}
+
+// embeddedStart returns the offset of the first character at or after offset that can start a
+// JSON value for ParseEmbedded, or -1.
+func embeddedStart(s string, offset int) int {
+	for i := max(offset, 0); i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '{' || c == '[' || c == '"':
+			return i
+		case i > 0 && isWordByte(s[i-1]):
+			continue
+		case c == '-' || c >= '0' && c <= '9':
+			return i
+		case strings.HasPrefix(s[i:], "true") || strings.HasPrefix(s[i:], "false") || strings.HasPrefix(s[i:], "null"):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isWordByte reports whether c is an ASCII letter, digit or underscore.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// ParseBytes parses a complete JSON document from b without first copying it into a string.
+//
+// The lexer reads the slice in place, so number literals and the string values without escapes
+// in the returned tree reference the memory of b, while strings with escapes are decoded into
+// fresh copies. The caller must not modify b while the returned tree is in use.
+func ParseBytes(b []byte, opts ...Option) (Value, error) {
+	return NewParser(newBytesLexer(b), opts...).ParseJSON()
+}
+
+// newBytesLexer creates a lexer that reads b in place instead of copying it into a string.
+func newBytesLexer(b []byte) *Lexer {
+	if len(b) == 0 {
+		return NewLexer("")
+	}
+
+	return NewLexer(unsafe.String(unsafe.SliceData(b), len(b)))
+}
+
+// gzipMagic is the header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ParseReader parses a complete JSON document read from r, decompressing it first when it is
+// gzip-compressed. Detection looks for the gzip magic header, which can never start a JSON
+// document, so uncompressed input is always read unchanged. Errors reading or decompressing the
+// input are returned instead of a ParseError for the truncated document they cause.
+func ParseReader(r io.Reader, opts ...Option) (Value, error) {
+	total := readerSize(r)
+	br := bufio.NewReader(r)
+
+	var input io.Reader = br
+
+	if magic, _ := br.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip header: %w", err)
+		}
+		defer zr.Close()
+
+		input = zr
+		total = -1
+	}
+
+	lexer := NewLexer(input)
+	lexer.total = total
+
+	value, err := NewParser(lexer, opts...).ParseJSON()
+	if lexer.readErr != nil {
+		return nil, fmt.Errorf("reading input: %w", lexer.readErr)
+	}
+
+	return value, err
+}
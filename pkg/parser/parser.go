@@ -3,11 +3,21 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Parser holds the state while parsing JSON input. It maintains the current token and the next token,
 // along with a list of any errors encountered during parsing.
+//
+// The parser configuration is held in the embedded ParserOptions, whose fields can be set
+// directly on the Parser or through Option values passed to NewParser.
 type Parser struct {
+	ParserOptions
+
 	// lexer provides tokens from the input string.
 	lexer *Lexer
 	// currentToken is the current token being examined.
@@ -15,24 +25,141 @@ type Parser struct {
 	// peekToken is the next token in the stream.
 	peekToken Token
 	// errors is a collection of parsing errors.
-	errors []string
+	errors []*ParseError
+	// fields restricts which keys of the next object are kept. See ParseFields.
+	fields map[string]struct{}
+	// keyOrder records the keys of every object in the order they first appear, when set. See
+	// ParseOrderedMap.
+	keyOrder map[*Object][]string
+	// comments are the retained comments not yet attached to a node.
+	comments []string
+	// started reports whether the first tokens have been read from the lexer.
+	started bool
+	// parsed reports whether ParseJSON has returned a document, whose last token is current.
+	parsed bool
+	// hinted reports whether ArrayHint has already been applied.
+	hinted bool
+	// depth is the number of containers currently open.
+	depth int
+	// maxDepthReached is the greatest depth reached so far. See MaxDepthReached.
+	maxDepthReached int
+	// path locates the value being parsed, from the root down. See ParseError.Path.
+	path []pathElement
+	// valueInterner interns string values under InternValues.
+	valueInterner Interner
+	// containers are the objects and arrays currently open, innermost last.
+	containers []container
+	// skipped are the opening tokens of the containers being checked without being built,
+	// innermost last.
+	skipped []Token
+	// deadline is the time by which parsing must end under Timeout.
+	deadline time.Time
+	// tokens counts the tokens read under Timeout, to check the clock periodically.
+	tokens int
+	// halted reports whether parsing was stopped early, by Timeout or the memory budget.
+	halted bool
+	// budgetReserved is the number of bytes reserved from the memory budget. See SetMemoryBudget.
+	budgetReserved int64
+	// budgetNext is the number of bytes consumed at which more of the memory budget is reserved,
+	// for input of unknown size, or zero when it is not reserved as the input is read.
+	budgetNext int
+	// budgetFrom is the number of bytes consumed when the previous document gave its part of the
+	// memory budget back.
+	budgetFrom int
+	// rejected reports whether the memory budget turned down the last document before any of it
+	// was read, so that ParseJSON may try it again.
+	rejected bool
+	// root is the tree returned by the last successful ParseJSON under PreserveFormatting, for
+	// ReparseRange.
+	root Value
+	// source is the input root was parsed from.
+	source string
+	// rawPaths holds RawPaths as a set.
+	rawPaths map[string]bool
+	// lazyKeys holds LazyKeys as a set.
+	lazyKeys map[string]bool
+	// nextProgress is the number of bytes consumed at which OnProgress is called next.
+	nextProgress int
+}
+
+// timeoutCheckInterval is the number of tokens read between two checks of the clock under
+// Timeout. Reading the clock costs far more than reading a token, so it is only done periodically.
+const timeoutCheckInterval = 1024
+
+// progressInterval is the number of bytes consumed between two calls of OnProgress.
+const progressInterval = 64 << 10
+
+// pathElement is a step of the path to the value being parsed: an object key or, when index is
+// not negative, an array index.
+type pathElement struct {
+	key   string
+	index int
 }
 
-// NewParser creates a new Parser instance for the given lexer.
+// NewParser creates a new Parser instance for the given lexer, starting from
+// DefaultParserOptions and applying opts in order.
 //
-// The function initializes the Parser by reading two tokens
-// to set up the currentToken and peekToken fields.
-func NewParser(lexer *Lexer) *Parser {
+// No tokens are read until parsing starts, so the exported fields
+// can be configured after the Parser is created.
+func NewParser(lexer *Lexer, opts ...Option) *Parser {
 	p := &Parser{
-		lexer:  lexer,
-		errors: []string{},
+		ParserOptions: DefaultParserOptions(),
+		lexer:         lexer,
+		errors:        []*ParseError{},
+	}
+
+	for _, opt := range opts {
+		opt(&p.ParserOptions)
+	}
+
+	return p
+}
+
+// start applies the parser configuration to the lexer and reads two tokens
+// to set up the currentToken and peekToken fields. It only runs once.
+func (p *Parser) start() {
+	if p.started {
+		return
+	}
+
+	p.started = true
+	p.lexer.allowComments = p.AllowComments
+	p.lexer.retainComments = p.AllowComments && p.RetainComments
+	p.lexer.barewords = len(p.Keywords) > 0
+	p.lexer.leadingZeros = p.AllowLeadingZeros
+	p.lexer.maxDocumentSize = p.MaxDocumentSize
+	p.lexer.maxStringEscapes = p.MaxStringEscapes
+	p.lexer.strict = p.StrictRFC8259
+	p.lexer.preserve = p.lexer.preserve || p.PreserveFormatting || len(p.RawPaths) > 0 || len(p.LazyKeys) > 0
+
+	if len(p.RawPaths) > 0 {
+		p.rawPaths = make(map[string]bool, len(p.RawPaths))
+		for _, pointer := range p.RawPaths {
+			p.rawPaths[pointer] = true
+		}
+	}
+
+	if len(p.LazyKeys) > 0 {
+		p.lazyKeys = make(map[string]bool, len(p.LazyKeys))
+		for _, key := range p.LazyKeys {
+			p.lazyKeys[key] = true
+		}
+	}
+
+	if p.Timeout > 0 {
+		p.deadline = time.Now().Add(p.Timeout)
+	}
+
+	if p.InternValues {
+		p.valueInterner = p.KeyInterner
+		if p.valueInterner == nil {
+			p.valueInterner = NewMapInterner()
+		}
 	}
 
 	// Read two tokens to initialize currentToken and peekToken
 	p.nextToken()
 	p.nextToken()
-
-	return p
 }
 
 // nextToken advances to the next token in the token stream.
@@ -40,156 +167,671 @@ func NewParser(lexer *Lexer) *Parser {
 // and then gets a new value for peekToken from the lexer.
 func (p *Parser) nextToken() {
 	p.currentToken = p.peekToken
+
+	if p.halted {
+		// End the input so that every loop of the parser stops
+		p.peekToken = Token{Type: TokenEOF, Line: p.currentToken.Line, Column: p.currentToken.Column}
+		return
+	}
+
 	p.peekToken = p.lexer.NextToken()
+	p.comments = append(p.comments, p.currentToken.Comments...)
+
+	if p.Timeout > 0 {
+		p.checkDeadline()
+	}
+
+	if p.budgetNext > 0 {
+		p.reserveRead()
+	}
+
+	if p.OnProgress != nil {
+		if consumed := p.lexer.consumed(); consumed >= p.nextProgress {
+			p.nextProgress = consumed + progressInterval
+			p.OnProgress(consumed, p.lexer.total)
+		}
+	}
+}
+
+// checkDeadline records a timeout error once parsing has gone past the deadline, checking the
+// clock every timeoutCheckInterval tokens.
+func (p *Parser) checkDeadline() {
+	p.tokens++
+	if p.tokens%timeoutCheckInterval != 0 || time.Now().Before(p.deadline) {
+		return
+	}
+
+	p.halted = true
+	p.errors = append(p.errors, &ParseError{
+		Line:    p.peekToken.Line,
+		Column:  p.peekToken.Column,
+		Message: fmt.Sprintf("parsing exceeded timeout of %s", p.Timeout),
+		Path:    p.pointer(),
+		err:     ErrTimeout,
+	})
+}
+
+// takeComments returns the retained comments not yet attached to a node and clears them.
+func (p *Parser) takeComments() []string {
+	if len(p.comments) == 0 {
+		return nil
+	}
+
+	comments := p.comments
+	p.comments = nil
+
+	return comments
 }
 
 // ParseJSON is the entry point for parsing JSON content. It returns the parsed
-// Value and an error if the parsing fails.
+// Value and an error if the parsing fails. The error is always a *ParseError.
 // The function expects the JSON input to start with either a '{' or a '['.
+//
+// Once a document has been parsed, calling ParseJSON again parses the document that follows it
+// in the input, so a stream of concatenated documents can be read one at a time. At the end of
+// the input it reports an empty document.
 func (p *Parser) ParseJSON() (Value, error) {
-	var value Value
+	if p.parsed {
+		p.nextToken()
+		p.parsed = false // the next document starts at the current token
+	}
 
-	switch p.currentToken.Type {
-	case TokenBraceOpen:
-		value = p.parseObject()
-	case TokenBracketOpen:
-		value = p.parseArray()
-	default:
-		return nil, fmt.Errorf("expected { or [, got %s at line %d, column %d",
-			p.currentToken.Type, p.currentToken.Line, p.currentToken.Column)
+	value := p.parseDocument()
+
+	if p.OnProgress != nil {
+		p.OnProgress(p.lexer.consumed(), p.lexer.total)
 	}
 
 	// Check for parsing errors
-	if len(p.errors) > 0 {
-		return nil, fmt.Errorf("%s", p.errors[0]) // Return the first error
+	if p.failed() {
+		err := p.errors[0] // Return the first error
+		if p.rejected {
+			p.errors, p.rejected = nil, false // the document can be tried again
+		}
+
+		return nil, err
+	}
+
+	if p.PreserveFormatting {
+		p.root, p.source = value, p.lexer.source()
 	}
 
+	p.parsed = true
+
 	return value, nil
 }
 
-// parseObject parses a JSON object: { "key": value, ... }.
-// It returns an Object value containing the key-value pairs.
-func (p *Parser) parseObject() Value {
-	object := &Object{
-		Token: p.currentToken,
-		Pairs: make(map[string]Value),
-	}
+// parseDocument parses a complete document. On failure it returns whatever part of the tree
+// was built before the first error, which may be nil.
+func (p *Parser) parseDocument() Value {
+	p.start()
 
-	// Handle empty object case: {}
-	if p.peekToken.Type == TokenBraceClose {
-		p.nextToken()
-		return object
+	if !p.reserveDocument() {
+		p.rejected = true
+		return nil
 	}
 
-	p.nextToken() // move past {
+	defer p.releaseDocument()
 
-	// Parse first key-value pair
-	key, value := p.parseKeyValuePair()
-	if key == "" && value == nil {
+	if p.halted {
 		return nil
 	}
 
-	object.Pairs[key] = value
+	var value Value
 
-	// Parse additional key-value pairs
-	for p.peekToken.Type == TokenComma {
-		p.nextToken() // move past comma
+	switch p.currentToken.Type {
+	case TokenBraceOpen, TokenBracketOpen:
+		value = p.parseContainer()
+	case TokenEOF:
+		if p.EmptyAsNull {
+			return NewNull()
+		}
 
-		// Check for trailing comma
-		if p.peekToken.Type == TokenBraceClose {
-			p.addError("unexpected token ,")
+		p.addError("unexpected end of input: empty document")
+
+		return nil
+	default:
+		switch {
+		case !p.StrictRFC8259:
+			p.addError("expected { or [, got %s", p.currentToken.Type)
+			return nil
+		case p.currentToken.Type == TokenIllegal && p.currentToken.Literal == unterminatedString:
+			p.addError("unterminated string: the input ends before its closing quote")
+			return nil
+		case !startsValue(p.currentToken.Type):
+			p.addError("expected a value, got %s", p.currentToken.Type)
 			return nil
 		}
 
-		p.nextToken() // move to next key
-
-		key, value := p.parseKeyValuePair()
-		if key == "" && value == nil {
+		// RFC 8259 allows any value as the document
+		if value = p.checkString(p.parseValue()); value == nil {
 			return nil
 		}
+	}
 
-		object.Pairs[key] = value
+	if p.StrictRFC8259 && !p.failed() && p.peekToken.Type != TokenEOF {
+		p.addErrorAt(p.peekToken, "unexpected token %s after the document", p.peekToken.Type)
+	}
+
+	// Comments after the root value trail the document, after any before its closing token. A
+	// scalar root made by NumberFactory may not record comments
+	n, ok := value.(interface{ info() *nodeInfo })
+	if comments := p.peekToken.Comments; ok && len(comments) > 0 && !p.failed() {
+		n.info().trailingComments = append(n.info().trailingComments, comments...)
 	}
 
-	// Handle EOF before closing brace
-	if p.peekToken.Type == TokenEOF {
-		p.addError("expected }, got EOF")
+	return value
+}
+
+// parseAny parses the value of any kind starting at the current token, leaving the parser
+// positioned on its last token.
+func (p *Parser) parseAny() Value {
+	if p.currentToken.Type == TokenBraceOpen || p.currentToken.Type == TokenBracketOpen {
+		return p.parseContainer()
+	}
+
+	return p.checkString(p.parseValue())
+}
+
+// checkString passes v, the scalar just parsed at the current path, to StringValidator when it is
+// a string, and returns it, or nil when the validator rejects it. Values checked without being
+// built, such as under ShouldDescend, are never passed.
+func (p *Parser) checkString(v Value) Value {
+	str, ok := v.(*StringLiteral)
+	if !ok || p.StringValidator == nil {
+		return v
+	}
+
+	path := p.pointer()
+	if err := p.StringValidator(path, str.Value); err != nil {
+		p.addCallbackError(p.currentToken, path, err)
 		return nil
 	}
 
-	// Ensure we have a closing }
-	if p.peekToken.Type != TokenBraceClose {
-		p.addError("expected }, got %s", p.peekToken.Type)
+	return v
+}
+
+// container is an object or array whose members are still being parsed. Open containers are
+// kept on an explicit stack rather than on the call stack, so that the depth of a document is
+// bounded by MaxDepth and available memory, never by the size of the goroutine stack.
+type container struct {
+	// object is the object being parsed, or nil for an array.
+	object *Object
+	// array is the array being parsed, or nil for an object.
+	array *Array
+	// fields restricts which keys of the object are kept. See ParseFields.
+	fields map[string]struct{}
+	// promoted records the keys collected into an array under DuplicateKeysToArray.
+	promoted map[string]bool
+	// members is the number of members or elements started so far.
+	members int
+	// nested reports whether the container has its location pushed on the path.
+	nested bool
+}
+
+// value returns the object or array being parsed.
+func (c *container) value() Value {
+	if c.object != nil {
+		return c.object
+	}
+
+	return c.array
+}
+
+// parseContainer parses the object or array starting at the current token, along with all the
+// containers nested in it. It returns nil when the container goes over MaxDepth. On failure the
+// tree holds the members and elements parsed before the error.
+func (p *Parser) parseContainer() Value {
+	base := len(p.containers)
+	if !p.open(false) {
 		return nil
 	}
 
-	p.nextToken() // move past }
+	root := p.containers[base].value()
+
+	for len(p.containers) > base && !p.failed() {
+		top := &p.containers[len(p.containers)-1]
+
+		var closed bool
+		if top.object != nil {
+			closed = p.stepObject(top)
+		} else {
+			closed = p.stepArray(top)
+		}
+
+		if closed {
+			// The next document of a stream starts after the root, before the token after it is read
+			if len(p.containers) == 1 {
+				p.lexer.documentStart = p.lexer.consumed()
+			}
+
+			p.nextToken() // move past } or ]
+			p.close()
+		}
+	}
+
+	// Containers left open by an error
+	for len(p.containers) > base {
+		p.close()
+	}
+
+	return root
+}
+
+// open pushes the object or array starting at the current token on the stack of containers. It
+// reports false when the container goes over MaxDepth. nested reports whether the location of
+// the container was pushed on the path.
+func (p *Parser) open(nested bool) bool {
+	if !p.enter() {
+		return false
+	}
+
+	c := container{nested: nested}
+
+	switch {
+	case p.currentToken.Type == TokenBraceOpen && p.Arena != nil:
+		c.object = p.Arena.object(p.currentToken)
+	case p.currentToken.Type == TokenBraceOpen:
+		c.object = &Object{
+			Token: p.currentToken,
+			Pairs: make(map[string]Value),
+		}
+	case p.Arena != nil:
+		c.array = p.Arena.array(p.currentToken)
+	default:
+		c.array = &Array{
+			Token:    p.currentToken,
+			Elements: []Value{},
+		}
+	}
+
+	if c.object != nil {
+		c.object.leadingComments = p.takeComments()
+
+		// Only the outermost object is filtered
+		c.fields = p.fields
+		p.fields = nil
+
+		if p.DuplicateKeysToArray {
+			c.promoted = make(map[string]bool)
+		}
+	} else {
+		if p.ArrayHint > 0 && !p.hinted {
+			p.hinted = true
+
+			if cap(c.array.Elements) < p.ArrayHint {
+				c.array.Elements = make([]Value, 0, p.ArrayHint)
+			}
+		}
+
+		c.array.leadingComments = p.takeComments()
+	}
+
+	p.containers = append(p.containers, c)
+
+	return true
+}
+
+// close pops the innermost container after its closing token, or after an error, attaching the
+// comments read before the closing token and popping its location from the path.
+func (p *Parser) close() {
+	c := &p.containers[len(p.containers)-1]
+
+	if !p.failed() {
+		c.value().(interface{ info() *nodeInfo }).info().trailingComments = p.takeComments()
+
+		if p.PreserveFormatting && c.fields == nil && !p.NormalizeKeysNFC {
+			p.recordSource(c.value())
+		}
+
+		if p.OnValue != nil {
+			if c.object != nil {
+				p.report(c.object, c.object.Token)
+			} else {
+				p.report(c.array, c.array.Token)
+			}
+		}
+	}
+
+	if c.nested {
+		p.path = p.path[:len(p.path)-1]
+	}
+
+	// Drop the references so the stack does not keep finished trees alive
+	*c = container{}
+	p.containers = p.containers[:len(p.containers)-1]
+
+	p.leave()
+}
+
+// stepObject parses the next member of the object of c, pushing the container opened by its
+// value, if any. It reports whether the object is complete, leaving the parser before the
+// closing brace.
+func (p *Parser) stepObject(c *container) bool {
+	if c.members == 0 {
+		// Handle empty object case: {}
+		if p.peekToken.Type == TokenBraceClose {
+			return true
+		}
+	} else {
+		if p.peekToken.Type != TokenComma {
+			if p.endOfInput(p.peekToken) {
+				return false
+			}
+
+			// Ensure we have a closing }
+			if p.peekToken.Type != TokenBraceClose {
+				p.expectedComma(p.peekToken, true)
+				return false
+			}
+
+			return true
+		}
+
+		p.nextToken() // move past comma
+
+		// Check for trailing comma
+		if p.peekToken.Type == TokenBraceClose {
+			p.addError("unexpected , before }")
+			return false
+		}
+
+		if p.MaxKeysPerObject > 0 && c.members >= p.MaxKeysPerObject {
+			p.addErrorAt(c.object.Token, "object exceeds maximum of %d keys", p.MaxKeysPerObject)
+			return false
+		}
+	}
+
+	p.nextToken() // move to the key
+	c.members++
+
+	p.parseMember(c)
 
-	return object
+	return false
 }
 
-// parseKeyValuePair parses a key-value pair in a JSON object.
-// It returns the key as a string and the value as a Value.
-func (p *Parser) parseKeyValuePair() (string, Value) {
+// parseMember parses a key-value pair and stores it in the object of c. When c.fields is
+// non-nil, pairs whose key is not listed are validated and discarded without building their
+// value. Under NormalizeKeysNFC the key is normalized and checked for duplicates first.
+func (p *Parser) parseMember(c *container) {
+	if p.NormalizeKeysNFC && p.currentToken.Type == TokenString {
+		key := norm.NFC.String(p.currentToken.Literal)
+		if _, ok := c.object.Pairs[key]; ok {
+			p.addError("duplicate key %q", key)
+			return
+		}
+
+		p.currentToken.Literal = key
+	}
+
+	if p.emptyKey() {
+		return
+	}
+
+	if c.fields != nil && p.currentToken.Type == TokenString {
+		if _, ok := c.fields[p.currentToken.Literal]; !ok {
+			p.skipKeyValuePair()
+			return
+		}
+	}
+
 	// Key must be a string
 	if p.currentToken.Type != TokenString {
-		p.addError("expected string key")
-		return "", nil
+		if !p.endOfInput(p.currentToken) {
+			p.expectedKey()
+		}
+
+		return
 	}
 
 	key := p.currentToken.Literal
+	if p.KeyInterner != nil {
+		key = p.KeyInterner.Intern(key)
+	}
 
 	// Must have a colon after key
 	if p.peekToken.Type != TokenColon {
-		p.addError("expected :, got %s", p.peekToken.Type)
-		return "", nil
+		if !p.endOfInput(p.peekToken) {
+			p.addErrorAt(p.peekToken, "expected : after key %q, got %s", p.currentToken.Literal, p.peekToken.Type)
+		}
+
+		return
 	}
 
 	p.nextToken() // move past key
 	p.nextToken() // move past colon
 
-	value := p.parseValue()
+	object, promoted := c.object, c.promoted // c may move when a container is pushed
 
-	return key, value
-}
+	p.path = append(p.path, pathElement{key: key, index: -1})
+	if !p.valueAfterColon() {
+		p.path = p.path[:len(p.path)-1]
+		return
+	}
+
+	if value := p.parseNested(); value != nil {
+		if _, ok := object.Pairs[key]; !ok && p.keyOrder != nil {
+			p.keyOrder[object] = append(p.keyOrder[object], key)
+		}
 
-// parseArray parses a JSON array: [ value, value, ... ].
-// It returns an Array value containing the elements.
-func (p *Parser) parseArray() Value {
-	array := &Array{
-		Token:    p.currentToken,
-		Elements: []Value{},
+		storeMember(object, key, value, promoted)
 	}
+}
 
-	// Handle empty array case: []
-	if p.peekToken.Type == TokenBracketClose {
-		p.nextToken()
-		return array
+// emptyKey records the error for the current token when it is an empty key rejected under
+// DisallowEmptyKeys, and reports whether it was.
+func (p *Parser) emptyKey() bool {
+	if !p.DisallowEmptyKeys || p.currentToken.Type != TokenString || p.currentToken.Literal != "" {
+		return false
 	}
 
-	p.nextToken() // move past [
+	p.addError("empty key is not allowed")
 
-	// Parse first value
-	value := p.parseValue()
-	array.Elements = append(array.Elements, value)
+	return true
+}
+
+// stepArray parses the next element of the array of c, pushing the container opened by it, if
+// any. It reports whether the array is complete, leaving the parser before the closing bracket.
+func (p *Parser) stepArray(c *container) bool {
+	if c.members == 0 {
+		// Handle empty array case: []
+		if p.peekToken.Type == TokenBracketClose {
+			return true
+		}
+	} else {
+		if p.peekToken.Type != TokenComma {
+			// Ensure we have a closing ]
+			if p.peekToken.Type != TokenBracketClose {
+				if !p.endOfInput(p.peekToken) {
+					p.expectedComma(p.peekToken, false)
+				}
+
+				return false
+			}
+
+			return true
+		}
 
-	// Parse additional values
-	for p.peekToken.Type == TokenComma {
 		p.nextToken() // move past comma
-		p.nextToken() // move to next value
-		value = p.parseValue()
+
+		// Check for trailing comma
+		if p.peekToken.Type == TokenBracketClose {
+			p.addError("unexpected , before ]")
+			return false
+		}
+
+		if p.MaxArrayLength > 0 && c.members >= p.MaxArrayLength {
+			p.addErrorAt(c.array.Token, "array exceeds maximum of %d elements", p.MaxArrayLength)
+			return false
+		}
+	}
+
+	p.nextToken() // move to the element
+	c.members++
+
+	array := c.array // c may move when a container is pushed
+
+	p.path = append(p.path, pathElement{index: len(array.Elements)})
+	if value := p.parseNested(); value != nil {
+		array.Elements = append(array.Elements, value)
+	}
+
+	return false
+}
+
+// report calls OnValue for the value v that was just completed, starting at token, and records
+// the error it returns as a ParseError at the position of token.
+func (p *Parser) report(v Value, token Token) {
+	path := p.pointer()
+
+	if err := p.OnValue(path, v); err != nil {
+		p.addCallbackError(token, path, err)
+	}
+}
+
+// addCallbackError records the error returned by a callback such as OnValue for the value at path,
+// as a ParseError at the position of token that wraps it.
+func (p *Parser) addCallbackError(token Token, path string, err error) {
+	p.errors = append(p.errors, &ParseError{
+		Line:    token.Line,
+		Column:  token.Column,
+		Message: err.Error(),
+		Path:    path,
+		err:     err,
+	})
+}
+
+// parseNested parses the value at the current token, whose location was just pushed on the
+// path. A scalar is parsed right away and its location popped. A container is pushed on the
+// stack of containers, keeping its location until it is closed, and returned empty.
+func (p *Parser) parseNested() Value {
+	lazy := p.lazyKeys != nil && p.path[len(p.path)-1].index < 0 && p.lazyKeys[p.path[len(p.path)-1].key]
+
+	if lazy || p.rawPaths != nil && p.rawPaths[p.pointer()] {
+		value := p.parseRaw(lazy)
+		if value != nil && p.OnValue != nil {
+			p.report(value, value.Token)
+		}
+
+		p.path = p.path[:len(p.path)-1]
+
+		return value
+	}
+
+	if p.currentToken.Type != TokenBraceOpen && p.currentToken.Type != TokenBracketOpen {
+		value := p.checkString(p.parseValue())
+		if value != nil && p.OnValue != nil {
+			p.report(value, p.currentToken)
+		}
+
+		p.path = p.path[:len(p.path)-1]
+
+		return value
+	}
+
+	if p.ShouldDescend != nil && !p.ShouldDescend(p.pointer(), p.currentToken) {
+		value := p.skipSubtree()
+		if value != nil && p.OnValue != nil {
+			p.report(value, value.Token)
+		}
+
+		p.path = p.path[:len(p.path)-1]
+
+		return value
+	}
+
+	if !p.open(true) {
+		p.path = p.path[:len(p.path)-1]
+		return nil
+	}
+
+	return p.containers[len(p.containers)-1].value()
+}
+
+// skipSubtree checks the container starting at the current token without building it, and
+// returns the *Null stored in its place under ShouldDescend, or nil when it is malformed. Like
+// parseValue, it leaves the parser positioned on the last token of the container.
+func (p *Parser) skipSubtree() *Null {
+	first := p.currentToken
+	comments := p.takeComments()
+
+	if !p.skipValue() {
+		return nil
+	}
+
+	null := p.newNull()
+	null.Token = Token{Type: TokenNull, Literal: "null", Line: first.Line, Column: first.Column}
+	null.leadingComments = comments
+
+	return null
+}
+
+// storeMember stores value under key in object. When promoted is non-nil, a repeated key collects
+// its values into an array instead of replacing the previous one, see DuplicateKeysToArray.
+func storeMember(object *Object, key string, value Value, promoted map[string]bool) {
+	existing, ok := object.Pairs[key]
+	if !ok || promoted == nil {
+		object.Pairs[key] = value
+		return
+	}
+
+	if promoted[key] {
+		array := existing.(*Array)
 		array.Elements = append(array.Elements, value)
+
+		return
 	}
 
-	// Ensure we have a closing ]
-	if p.peekToken.Type != TokenBracketClose {
-		p.addError("expected ], got %s", p.peekToken.Type)
+	promoted[key] = true
+
+	array := newArray(nil)
+	array.Elements = append(array.Elements, existing, value)
+	object.Pairs[key] = array
+}
+
+// parseRaw checks the value starting at the current token without building it, and returns
+// its source text as a RawMessage, or nil when it is malformed. The RawMessage keeps the options
+// of the parser for Marshal, and for Object.Resolve when it is lazy. Like parseValue, it leaves
+// the parser positioned on the last token of the value.
+func (p *Parser) parseRaw(lazy bool) *RawMessage {
+	first := p.currentToken
+	comments := p.takeComments()
+
+	if !p.skipValue() {
 		return nil
 	}
 
-	p.nextToken() // move past ]
+	raw := &RawMessage{Token: first, Bytes: []byte(p.lexer.text(first.start, p.currentToken.end))}
+	raw.leadingComments = comments
+
+	raw.options = p.deferredOptions()
+	if lazy {
+		raw.deferred = raw.options
+	}
+
+	if p.PreserveFormatting {
+		p.recordSource(raw)
+	}
 
-	return array
+	return raw
+}
+
+// enter records that a container is opened, reporting false when it goes over MaxDepth.
+func (p *Parser) enter() bool {
+	if p.MaxDepth > 0 && p.depth >= p.MaxDepth {
+		p.addError("maximum nesting depth of %d exceeded", p.MaxDepth)
+		return false
+	}
+
+	p.depth++
+	p.maxDepthReached = max(p.maxDepthReached, p.depth)
+
+	return true
+}
+
+// leave records that a container is closed.
+func (p *Parser) leave() {
+	p.depth--
 }
 
 // parseValue parses any JSON value. It returns the parsed value.
@@ -197,40 +839,283 @@ func (p *Parser) parseArray() Value {
 func (p *Parser) parseValue() Value {
 	switch p.currentToken.Type {
 	case TokenString:
-		return &StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
+		str := p.newString()
+		str.Token, str.Value = p.currentToken, p.currentToken.Literal
+
+		if p.TrimStringValues {
+			str.Value = strings.TrimSpace(str.Value)
+			str.Token.Literal = str.Value
+		}
+
+		if p.valueInterner != nil {
+			str.Value = p.valueInterner.Intern(str.Value)
+			str.Token.Literal = str.Value
+		}
+
+		str.leadingComments = p.takeComments()
+
+		if p.PreserveFormatting {
+			p.recordSource(str)
+		}
+
+		return str
 
 	case TokenNumber:
-		num := NewNumberLiteral(p.currentToken)
+		if p.MaxNumberLength > 0 && len(p.currentToken.Literal) > p.MaxNumberLength {
+			p.addError("number literal exceeds maximum length of %d", p.MaxNumberLength)
+			return nil
+		}
+
+		if p.NumberFactory != nil {
+			return p.customNumber()
+		}
+
+		num := initNumberLiteral(p.newNumber(), p.currentToken)
 		if !num.IsValidNumber() {
 			p.addError("invalid number format: %s", p.currentToken.Literal)
 			return nil
 		}
 
+		if num.Overflowed && p.StrictNumbers {
+			p.addError("integer %s overflows int64", p.currentToken.Literal)
+			return nil
+		}
+
+		num.leadingComments = p.takeComments()
+
+		if p.PreserveFormatting {
+			p.recordSource(num)
+		}
+
 		return num
 
-	case TokenTrue:
-		return &Boolean{Token: p.currentToken, Value: true}
+	case TokenTrue, TokenFalse:
+		b := p.newBoolean()
+		b.Token, b.Value = p.currentToken, p.currentToken.Type == TokenTrue
+		b.leadingComments = p.takeComments()
 
-	case TokenFalse:
-		return &Boolean{Token: p.currentToken, Value: false}
+		if p.PreserveFormatting {
+			p.recordSource(b)
+		}
+
+		return b
 
 	case TokenNull:
-		return &Null{Token: p.currentToken}
+		null := p.newNull()
+		null.Token = p.currentToken
+		null.leadingComments = p.takeComments()
+
+		if p.PreserveFormatting {
+			p.recordSource(null)
+		}
+
+		return null
 
-	case TokenBraceOpen:
-		return p.parseObject()
+	case TokenBraceOpen, TokenBracketOpen:
+		return p.parseContainer()
 
-	case TokenBracketOpen:
-		return p.parseArray()
+	case TokenWord:
+		return p.parseKeyword()
 
 	case TokenIllegal:
-		p.addError("expected string key")
+		if !p.endOfInput(p.currentToken) {
+			p.addError("expected string key")
+		}
+
+		return nil
+
+	case TokenComma:
+		p.addError("unexpected , where a value was expected")
+		return nil
+
+	default:
+		if !p.endOfInput(p.currentToken) {
+			p.addError("unexpected token %s", p.currentToken.Type)
+		}
+
 		return nil
+	}
+}
+
+// endOfInput records the error for input that ends at token, an EOF token or a string missing its
+// closing quote, while containers are still open, naming the closing token of the innermost one
+// and where it was opened, as in "expected ], got EOF: the array opened at line 1, column 7 is not
+// closed". Containers being skipped count as open. It reports false, recording nothing, for any
+// other token or outside of containers.
+func (p *Parser) endOfInput(token Token) bool {
+	var open Token
+
+	switch {
+	case len(p.skipped) > 0:
+		open = p.skipped[len(p.skipped)-1]
+	case len(p.containers) > 0:
+		if c := &p.containers[len(p.containers)-1]; c.object != nil {
+			open = c.object.Token
+		} else {
+			open = c.array.Token
+		}
+	default:
+		return false
+	}
+
+	switch {
+	case token.Type == TokenEOF:
+		if open.Type == TokenBraceOpen {
+			p.addErrorAt(token, "expected }, got EOF: the object opened at line %d, column %d is not closed",
+				open.Line, open.Column)
+		} else {
+			p.addErrorAt(token, "expected ], got EOF: the array opened at line %d, column %d is not closed",
+				open.Line, open.Column)
+		}
+
+		return true
+
+	case token.Type == TokenIllegal && token.Literal == unterminatedString:
+		p.addErrorAt(token, "unterminated string: the input ends before its closing quote")
+		return true
+
+	default:
+		return false
+	}
+}
+
+// expectedComma records the error for token, found after a member of an object or an element of
+// an array where a comma or the closing token was expected. A token that can start another member
+// or element is reported as a missing comma, the most likely mistake in hand-edited JSON.
+func (p *Parser) expectedComma(token Token, object bool) {
+	switch {
+	case object && token.Type == TokenString:
+		p.addErrorAt(token, "expected , between members, got %s", token.Type)
+	case object:
+		p.addErrorAt(token, "expected , or }, got %s", token.Type)
+	case startsValue(token.Type):
+		p.addErrorAt(token, "expected , between elements, got %s", token.Type)
+	default:
+		p.addErrorAt(token, "expected , or ], got %s", token.Type)
+	}
+}
+
+// expectedKey records the error for the current token, found where the key of a member was
+// expected.
+func (p *Parser) expectedKey() {
+	if p.currentToken.Type == TokenComma {
+		p.addError("unexpected , where a key was expected")
+		return
+	}
+
+	p.addError("expected string key")
+}
+
+// valueAfterColon reports whether the current token, which follows the colon of a member, may
+// start its value, recording an error when it is a closing token, a comma or a colon. Any other
+// token is left for parseValue to report.
+func (p *Parser) valueAfterColon() bool {
+	switch p.currentToken.Type {
+	case TokenBraceClose, TokenBracketClose, TokenComma, TokenColon:
+		p.addError("expected a value after :, got %s", p.currentToken.Type)
+		return false
+	default:
+		return true
+	}
+}
 
+// startsValue reports whether a token of type t starts a value.
+func startsValue(t TokenType) bool {
+	switch t {
+	case TokenString, TokenNumber, TokenTrue, TokenFalse, TokenNull, TokenBraceOpen, TokenBracketOpen, TokenWord:
+		return true
 	default:
-		p.addError("unexpected token %s", p.currentToken.Type)
+		return false
+	}
+}
+
+// newString returns a zero StringLiteral, taken from the Arena when there is one. newNumber,
+// newBoolean and newNull do the same for the other scalars.
+func (p *Parser) newString() *StringLiteral {
+	if p.Arena != nil {
+		return p.Arena.strings.alloc()
+	}
+
+	return &StringLiteral{}
+}
+
+func (p *Parser) newNumber() *NumberLiteral {
+	if p.Arena != nil {
+		return p.Arena.numbers.alloc()
+	}
+
+	return &NumberLiteral{}
+}
+
+func (p *Parser) newBoolean() *Boolean {
+	if p.Arena != nil {
+		return p.Arena.booleans.alloc()
+	}
+
+	return &Boolean{}
+}
+
+func (p *Parser) newNull() *Null {
+	if p.Arena != nil {
+		return p.Arena.nulls.alloc()
+	}
+
+	return &Null{}
+}
+
+// parseKeyword looks up the current bareword in the Keywords table.
+func (p *Parser) parseKeyword() Value {
+	value, ok := p.Keywords[p.currentToken.Literal]
+	if !ok || value == nil {
+		p.addError("unknown keyword %s", p.currentToken.Literal)
 		return nil
 	}
+
+	// Give each occurrence its own node
+	shared := value
+
+	switch val := value.(type) {
+	case *StringLiteral:
+		copied := *val
+		value = &copied
+	case *NumberLiteral:
+		copied := *val
+		value = &copied
+	case *Boolean:
+		copied := *val
+		value = &copied
+	case *Null:
+		copied := *val
+		value = &copied
+	}
+
+	// A shared container cannot hold the comments of one occurrence, but they must not pass on to
+	// the next value either
+	comments := p.takeComments()
+	if value != shared {
+		value.(interface{ info() *nodeInfo }).info().leadingComments = comments
+	}
+
+	return value
+}
+
+// customNumber builds the current number token with NumberFactory.
+func (p *Parser) customNumber() Value {
+	value, err := p.NumberFactory(p.currentToken.Literal)
+	if err != nil {
+		p.addError("invalid number %s: %v", p.currentToken.Literal, err)
+		return nil
+	}
+
+	if value == nil {
+		p.addError("number factory returned no value for %s", p.currentToken.Literal)
+		return nil
+	}
+
+	// Comments cannot be attached to values of other packages
+	p.takeComments()
+
+	return value
 }
 
 // addError adds a formatted error message to the parser's error list.
@@ -238,13 +1123,84 @@ func (p *Parser) parseValue() Value {
 // The function records the error message along with the line and column numbers
 // where the error occurred.
 func (p *Parser) addError(format string, a ...interface{}) {
-	msg := fmt.Sprintf(format, a...)
-	formattedMsg := fmt.Sprintf("Line %d, Column %d: %s",
-		p.currentToken.Line, p.currentToken.Column, msg)
-	p.errors = append(p.errors, formattedMsg)
+	p.addErrorAt(p.currentToken, format, a...)
+}
+
+// addErrorAt adds a formatted error message at the position of the given token.
+func (p *Parser) addErrorAt(token Token, format string, a ...interface{}) {
+	// Once the input was cut short at MaxDocumentSize, the limit is what went wrong
+	if err := p.lexer.sizeErr; err != nil {
+		err.Path = p.pointer()
+		p.errors = append(p.errors, err)
+
+		return
+	}
+
+	if err := p.lexer.encodingErrorAt(token); err != nil {
+		err.Path = p.pointer()
+		p.errors = append(p.errors, err)
+
+		return
+	}
+
+	// Whatever was expected instead, a string over MaxStringEscapes is what went wrong
+	if token.Type == TokenIllegal && token.Literal == tooManyEscapes {
+		format, a = "string exceeds maximum of %d escape sequences", []interface{}{p.MaxStringEscapes}
+	}
+
+	if token.Type == TokenIllegal && token.Literal == controlCharacter {
+		format, a = "string holds an unescaped control character", nil
+	}
+
+	p.errors = append(p.errors, &ParseError{
+		Line:    token.Line,
+		Column:  token.Column,
+		Message: fmt.Sprintf(format, a...),
+		Path:    p.pointer(),
+	})
+}
+
+// pointer returns the JSON Pointer of the value being parsed.
+func (p *Parser) pointer() string {
+	var b strings.Builder
+
+	for _, elem := range p.path {
+		b.WriteByte('/')
+
+		if elem.index >= 0 {
+			b.WriteString(strconv.Itoa(elem.index))
+		} else {
+			b.WriteString(escapePointerToken(elem.key))
+		}
+	}
+
+	return b.String()
+}
+
+// failed reports whether the parser has encountered an error.
+func (p *Parser) failed() bool {
+	return len(p.errors) > 0
 }
 
 // Errors returns all parsing errors encountered by the parser.
 func (p *Parser) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		msgs[i] = err.Error()
+	}
+
+	return msgs
+}
+
+// ParseErrors returns all parsing errors encountered by the parser as structured values.
+func (p *Parser) ParseErrors() []*ParseError {
 	return p.errors
 }
+
+// MaxDepthReached returns the deepest nesting of objects and arrays reached while parsing, where
+// the root container is at depth 1, so that {"a": [1]} reaches 2. It helps choose a MaxDepth
+// suited to real payloads. A parse that fails reports the depth reached before the error, which is
+// at most MaxDepth, and a parser that has not parsed anything reports 0.
+func (p *Parser) MaxDepthReached() int {
+	return p.maxDepthReached
+}
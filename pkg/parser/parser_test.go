@@ -1,10 +1,18 @@
 package parser_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"reflect"
+	"runtime/debug"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/rafaelmgr12/jingo/pkg/parser"
 )
@@ -123,7 +131,7 @@ func TestParserErrors(t *testing.T) {
 		},
 		{
 			input:       `{"key" value}`,
-			expectedErr: "expected :, got ILLEGAL",
+			expectedErr: `expected : after key "key", got ILLEGAL`,
 		},
 		{
 			input:       `{"key": "value"`,
@@ -131,7 +139,7 @@ func TestParserErrors(t *testing.T) {
 		},
 		{
 			input:       `{"key": "value",}`,
-			expectedErr: "unexpected token ,",
+			expectedErr: "unexpected , before }",
 		},
 	}
 
@@ -153,6 +161,76 @@ func TestParserErrors(t *testing.T) {
 	}
 }
 
+func TestUnterminatedInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Array in object", `{"a": [1, 2`, "Line 1, Column 11: expected ], got EOF: the array opened at line 1, column 7 is not closed"},
+		{"Object after a member", `{"a": 1`, "Line 1, Column 7: expected }, got EOF: the object opened at line 1, column 1 is not closed"},
+		{"Object after a comma", `{"a": 1,`, "Line 1, Column 8: expected }, got EOF: the object opened at line 1, column 1 is not closed"},
+		{"Object before a value", `{"a":`, "Line 1, Column 5: expected }, got EOF: the object opened at line 1, column 1 is not closed"},
+		{"Object before a colon", `{"a"`, "Line 1, Column 4: expected }, got EOF: the object opened at line 1, column 1 is not closed"},
+		{"Empty array", `[`, "Line 1, Column 1: expected ], got EOF: the array opened at line 1, column 1 is not closed"},
+		{"Nested on several lines", "{\n  \"a\": [\n    {\"b\": 1}", "Line 3, Column 12: expected ], got EOF: the array opened at line 2, column 8 is not closed"},
+		{"String value", `{"a": "abc`, "Line 1, Column 7: unterminated string: the input ends before its closing quote"},
+		{"String element", `["x`, "Line 1, Column 2: unterminated string: the input ends before its closing quote"},
+		{"String key", `{"ke`, "Line 1, Column 2: unterminated string: the input ends before its closing quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input)
+
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestSeparatorErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Missing comma between elements", `[1 2]`, "Line 1, Column 4: expected , between elements, got NUMBER"},
+		{"Missing comma between members", "{\"a\": 1\n \"b\": 2}", "Line 2, Column 2: expected , between members, got STRING"},
+		{"Wrong token after an element", `[1 }`, "Line 1, Column 4: expected , or ], got }"},
+		{"Wrong token after a member", `{"a": 1: 2}`, "Line 1, Column 8: expected , or }, got :"},
+		{"Trailing comma in an array", `[1, 2,]`, "Line 1, Column 6: unexpected , before ]"},
+		{"Trailing comma in an object", `{"a": 1,}`, "Line 1, Column 8: unexpected , before }"},
+		{"Leading comma in an array", `[,1]`, "Line 1, Column 2: unexpected , where a value was expected"},
+		{"Double comma in an array", `[1,,2]`, "Line 1, Column 4: unexpected , where a value was expected"},
+		{"Leading comma in an object", `{,"a": 1}`, "Line 1, Column 2: unexpected , where a key was expected"},
+		{"Double comma in an object", `{"a": 1,,"b": 2}`, "Line 1, Column 9: unexpected , where a key was expected"},
+		{"Missing colon", `{"a" 1}`, `Line 1, Column 6: expected : after key "a", got NUMBER`},
+		{"Key without a value", `{"a"}`, `Line 1, Column 5: expected : after key "a", got }`},
+		{"Missing value", `{"a": }`, "Line 1, Column 7: expected a value after :, got }"},
+		{"Comma instead of a value", `{"a": , "b": 1}`, "Line 1, Column 7: expected a value after :, got ,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input)
+
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// Values skipped by ParseFields are reported the same way
+	_, err := parser.ParseFields(`{"a": 1, "b": [1 2]}`, "a")
+	if expected := "Line 1, Column 18: expected , between elements, got NUMBER"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
 func TestComplexJSON(t *testing.T) {
 	input := `{
         "key1": {
@@ -164,127 +242,1337 @@ func TestComplexJSON(t *testing.T) {
         "key4": true
     }`
 
-	l := parser.NewLexer(input)
-	p := parser.NewParser(l)
+	l := parser.NewLexer(input)
+	p := parser.NewParser(l)
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	if obj, ok := value.(*parser.Object); !ok || len(obj.Pairs) != 4 {
+		t.Fatalf("Parsing resulted in wrong object structure: %+v", value)
+	}
+}
+
+func TestNumberParsing(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantInt    bool
+		wantValue  interface{} // can be int64 or float64
+		shouldFail bool
+	}{
+		// Valid cases
+		{
+			input:      `{"num": 123}`,
+			wantInt:    true,
+			wantValue:  int64(123),
+			shouldFail: false,
+		},
+		{
+			input:      `{"num": 123.456}`,
+			wantInt:    false,
+			wantValue:  float64(123.456),
+			shouldFail: false,
+		},
+		{
+			input:      `{"num": -123}`,
+			wantInt:    true,
+			wantValue:  int64(-123),
+			shouldFail: false,
+		},
+		{
+			input:      `{"num": 1e5}`,
+			wantInt:    false,
+			wantValue:  float64(100000),
+			shouldFail: false,
+		},
+		{
+			input:      `{"num": 1.2e-3}`,
+			wantInt:    false,
+			wantValue:  float64(0.0012),
+			shouldFail: false,
+		},
+		// Invalid cases
+		{
+			input:      `{"num": 01234}`, // Leading zeros not allowed
+			shouldFail: true,
+		},
+		{
+			input:      `{"num": .123}`, // Must start with digit
+			shouldFail: true,
+		},
+		{
+			input:      `{"num": 123.}`, // Must have digits after decimal
+			shouldFail: true,
+		},
+		{
+			input:      `{"num": -}`, // Must have digits after minus
+			shouldFail: true,
+		},
+		{
+			input:      `{"num": 1.2e}`, // Must have digits after exponent
+			shouldFail: true,
+		},
+		{
+			input:      `{"num": 1.2e-}`, // Must have digits after exponent sign
+			shouldFail: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Case %d: %s", i, tt.input), func(t *testing.T) {
+			l := parser.NewLexer(tt.input)
+			p := parser.NewParser(l)
+			value, err := p.ParseJSON()
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Errorf("Expected error for input %s but got none", tt.input)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			obj, ok := value.(*parser.Object)
+			if !ok {
+				t.Fatalf("Expected Object, got %T", value)
+			}
+
+			num, ok := obj.Pairs["num"].(*parser.NumberLiteral)
+			if !ok {
+				t.Fatalf("Expected NumberLiteral, got %T", obj.Pairs["num"])
+			}
+
+			if num.IsInt != tt.wantInt {
+				t.Errorf("IsInt = %v, want %v", num.IsInt, tt.wantInt)
+			}
+
+			if tt.wantInt {
+				if num.Int != tt.wantValue.(int64) {
+					t.Errorf("Int = %d, want %d", num.Int, tt.wantValue.(int64))
+				}
+			} else {
+				if math.Abs(num.Float-tt.wantValue.(float64)) > 1e-10 {
+					t.Errorf("Float = %g, want %g", num.Float, tt.wantValue.(float64))
+				}
+			}
+		})
+	}
+}
+
+func TestMaxNumberLength(t *testing.T) {
+	longNumber := "1" + strings.Repeat("0", 1_000_000)
+
+	tests := []struct {
+		name       string
+		input      string
+		maxLength  int
+		shouldFail bool
+	}{
+		{
+			name:       "Default limit rejects huge literal",
+			input:      `[` + longNumber + `]`,
+			maxLength:  parser.DefaultMaxNumberLength,
+			shouldFail: true,
+		},
+		{
+			name:       "Custom limit rejects literal above it",
+			input:      `{"num": 123456}`,
+			maxLength:  5,
+			shouldFail: true,
+		},
+		{
+			name:      "Literal at the limit is accepted",
+			input:     `{"num": 12345}`,
+			maxLength: 5,
+		},
+		{
+			name:      "Disabled limit",
+			input:     `{"num": 123456}`,
+			maxLength: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(tt.input))
+			p.MaxNumberLength = tt.maxLength
+
+			_, err := p.ParseJSON()
+			if !tt.shouldFail {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *parser.ParseError, got %T: %v", err, err)
+			}
+
+			if !strings.Contains(parseErr.Message, "exceeds maximum length") {
+				t.Errorf("Unexpected error message: %q", parseErr.Message)
+			}
+		})
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	inputs := []string{"", "   ", "\n\t"}
+
+	for _, input := range inputs {
+		t.Run(fmt.Sprintf("%q", input), func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(input))
+
+			_, err := p.ParseJSON()
+
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *parser.ParseError, got %T: %v", err, err)
+			}
+
+			if !strings.Contains(parseErr.Message, "empty document") {
+				t.Errorf("Unexpected error message: %q", parseErr.Message)
+			}
+
+			p = parser.NewParser(parser.NewLexer(input))
+			p.EmptyAsNull = true
+
+			value, err := p.ParseJSON()
+			if err != nil {
+				t.Fatalf("Unexpected error with EmptyAsNull: %v", err)
+			}
+
+			if _, ok := value.(*parser.Null); !ok {
+				t.Fatalf("Expected *parser.Null, got %T", value)
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	input := `{"id": 7, "skip": {"deep": [1, 2, {"x": null}]}, "name": "jingo", "other": [true]}`
+
+	obj, err := parser.ParseFields(input, "id", "name", "missing")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(obj.Pairs) != 2 {
+		t.Fatalf("Expected 2 pairs, got %d: %v", len(obj.Pairs), obj.Pairs)
+	}
+
+	if obj.Pairs["id"].(*parser.NumberLiteral).Int != 7 {
+		t.Errorf("Expected id 7, got %v", obj.Pairs["id"])
+	}
+
+	if obj.Pairs["name"].(*parser.StringLiteral).Value != "jingo" {
+		t.Errorf("Expected name jingo, got %v", obj.Pairs["name"])
+	}
+
+	invalid := []string{
+		`{"id": 1, "skip": [1, 2,, 3]}`,
+		`{"id": 1, "skip": {"a" 1}}`,
+		`{"id": 1, "skip": {"a": 1,}}`,
+		`{"id": 1, "skip": 01}`,
+		`[1, 2]`,
+	}
+
+	for _, input := range invalid {
+		if _, err := parser.ParseFields(input, "id"); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+
+	// A skipped value cut short names where it was opened, like a parsed one
+	expected := "Line 1, Column 24: expected ], got EOF: the array opened at line 1, column 19 is not closed"
+	if _, err := parser.ParseFields(`{"id": 1, "skip": [1, 2,`, "id"); err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// config file
+	{
+		/* the name */ "name": "jingo", // trailing name note
+		"tags": [
+			// first tag
+			"a",
+			"b" /* before close */
+		]
+	} // end`
+
+	if _, err := parser.NewParser(parser.NewLexer(input)).ParseJSON(); err == nil {
+		t.Fatal("Expected comments to be rejected by default")
+	}
+
+	p := parser.NewParser(parser.NewLexer(input))
+	p.AllowComments = true
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if comments := parser.LeadingComments(value); comments != nil {
+		t.Fatalf("Expected comments to be discarded without RetainComments, got %v", comments)
+	}
+
+	p = parser.NewParser(parser.NewLexer(input))
+	p.AllowComments = true
+	p.RetainComments = true
+
+	value, err = p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	obj := value.(*parser.Object)
+	tags := obj.Pairs["tags"].(*parser.Array)
+
+	tests := []struct {
+		name     string
+		got      []string
+		expected []string
+	}{
+		{"root leading", parser.LeadingComments(obj), []string{"// config file"}},
+		{"root trailing", parser.TrailingComments(obj), []string{"// end"}},
+		{"member value", parser.LeadingComments(obj.Pairs["name"]), []string{"/* the name */"}},
+		{"after comma", parser.LeadingComments(tags), []string{"// trailing name note"}},
+		{"array element", parser.LeadingComments(tags.Elements[0]), []string{"// first tag"}},
+		{"container trailing", parser.TrailingComments(tags), []string{"/* before close */"}},
+		{"no comments", parser.LeadingComments(tags.Elements[1]), nil},
+	}
+
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.expected) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, tt.got)
+		}
+	}
+
+	// Comments before the closing token of the root are kept along with those after it
+	p = parser.NewParser(parser.NewLexer(`[1 /* last */] // end`))
+	p.AllowComments = true
+	p.RetainComments = true
+
+	value, err = p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got, expected := parser.TrailingComments(value), []string{"/* last */", "// end"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	p = parser.NewParser(parser.NewLexer(`{"a": 1 /* open`))
+	p.AllowComments = true
+
+	if _, err := p.ParseJSON(); err == nil {
+		t.Fatal("Expected error for unterminated block comment")
+	}
+}
+
+func TestParseWithComments(t *testing.T) {
+	input := `// config file
+	{
+		/* the name */ "name": "jingo",
+		"server": {
+			// port to listen on
+			"port": 8080
+			// more settings later
+		},
+		"a/b": [1, /* second */ 2]
+	} // end`
+
+	value, comments, err := parser.ParseWithComments(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		"":             {"// config file"},
+		"/~":           {"// end"},
+		"/name":        {"/* the name */"},
+		"/server/port": {"// port to listen on"},
+		"/server/~":    {"// more settings later"},
+		"/a~1b/1":      {"/* second */"},
+	}
+	if !reflect.DeepEqual(comments, expected) {
+		t.Errorf("Expected %q, got %q", expected, comments)
+	}
+
+	// The tree holds no comments
+	err = parser.Walk(value, func(pointer string, v parser.Value) error {
+		if parser.LeadingComments(v) != nil || parser.TrailingComments(v) != nil {
+			t.Errorf("Expected no comments attached at %q", pointer)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, comments, err := parser.ParseWithComments(`{"a": 1}`); err != nil || len(comments) != 0 {
+		t.Errorf("Expected no comments, got %v (%v)", comments, err)
+	}
+
+	if _, _, err := parser.ParseWithComments(`{"a": 1 /* open`); err == nil {
+		t.Error("Expected error for unterminated block comment")
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	input := []byte(`{"name": "jingo", "version": 1.5, "tags": ["a", "b"]}`)
+
+	value, err := parser.ParseBytes(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	obj := value.(*parser.Object)
+	if obj.Pairs["name"].(*parser.StringLiteral).Value != "jingo" {
+		t.Errorf("Expected name jingo, got %v", obj.Pairs["name"])
+	}
+
+	if obj.Pairs["version"].(*parser.NumberLiteral).Float != 1.5 {
+		t.Errorf("Expected version 1.5, got %v", obj.Pairs["version"])
+	}
+
+	if _, err := parser.ParseBytes(nil); err == nil {
+		t.Error("Expected error for empty input")
+	}
+
+	if _, err := parser.ParseBytes([]byte(`{"a": }`)); err == nil {
+		t.Error("Expected error for invalid input")
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	input := []byte{'['}
+
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			input = append(input, ',')
+		}
+
+		input = append(input, `{"key": [1, 2, 3], "other": 1.5, "flag": true}`...)
+	}
+
+	input = append(input, ']')
+
+	b.Run("Parse(string(b))", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.Parse(string(input)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("ParseBytes(b)", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.ParseBytes(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestStringDecoding(t *testing.T) {
+	input := "[\"plain\", \"\", \"tab\\there \\u00e9\\ud83c\\udf89\", \"bad \xff byte\", \"after\", \"multi\nline é\"]"
+	expected := []string{"plain", "", "tab\there é🎉", "bad \ufffd byte", "after", "multi\nline é"}
+
+	parsers := map[string]func() (parser.Value, error){
+		"Parse":      func() (parser.Value, error) { return parser.Parse(input) },
+		"ParseBytes": func() (parser.Value, error) { return parser.ParseBytes([]byte(input)) },
+		"ParseReader": func() (parser.Value, error) {
+			return parser.ParseReader(iotest.OneByteReader(strings.NewReader(input)))
+		},
+	}
+
+	for name, parse := range parsers {
+		t.Run(name, func(t *testing.T) {
+			value, err := parse()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			arr := value.(*parser.Array)
+			if len(arr.Elements) != len(expected) {
+				t.Fatalf("Expected %d strings, got %d", len(expected), len(arr.Elements))
+			}
+
+			for i, elem := range arr.Elements {
+				if got := elem.(*parser.StringLiteral).Value; got != expected[i] {
+					t.Errorf("Expected string %d to be %q, got %q", i, expected[i], got)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseStrings(b *testing.B) {
+	inputs := map[string]string{
+		"Escaped": `{"text": "line\none\ttab \"quoted\" \\ \u00e9\u20ac", "path": "C:\\Users\\me"}`,
+		"Plain":   `{"text": "a plain string value without any escapes", "path": "/home/me"}`,
+	}
+
+	for name, record := range inputs {
+		input := []byte("[" + strings.TrimSuffix(strings.Repeat(record+",", 1000), ",") + "]")
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseBytes(input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	value, perr := parser.ParsePartial(`[1, 2, {"a": [3, 4`)
+	if perr == nil {
+		t.Fatal("Expected error for truncated input")
+	}
+
+	arr, ok := value.(*parser.Array)
+	if !ok || len(arr.Elements) != 3 {
+		t.Fatalf("Expected partial array with 3 elements, got %#v", value)
+	}
+
+	inner := arr.Elements[2].(*parser.Object).Pairs["a"].(*parser.Array)
+	if len(inner.Elements) != 2 {
+		t.Fatalf("Expected nested partial array with 2 elements, got %d", len(inner.Elements))
+	}
+
+	value, perr = parser.ParsePartial(`{"a": 1, "b": tru`)
+	if perr == nil || perr.Line != 1 {
+		t.Fatalf("Expected positioned error, got %v", perr)
+	}
+
+	obj := value.(*parser.Object)
+	if _, ok := obj.Pairs["b"]; ok || len(obj.Pairs) != 1 {
+		t.Fatalf("Expected only the complete pair, got %v", obj.Pairs)
+	}
+
+	if value, perr = parser.ParsePartial(`garbage`); value != nil || perr == nil {
+		t.Fatalf("Expected nil value and error, got %v, %v", value, perr)
+	}
+
+	if value, perr = parser.ParsePartial(`{"a": [1]}`); value == nil || perr != nil {
+		t.Fatalf("Expected complete value without error, got %v, %v", value, perr)
+	}
+}
+
+func TestParseEmbedded(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		offset   int
+		expected string
+		end      int
+	}{
+		{"Object in a log line", `prefix {"a":1} suffix`, 0, `{"a":1}`, 14},
+		{"Braces in strings", `x={"a": "}{[", "b": [1]} y`, 0, `{"a":"}{[","b":[1]}`, 24},
+		{"From an offset", `[1] [2]`, 3, `[2]`, 7},
+		{"Scalar literal", `status: true done`, 0, `true`, 12},
+		{"Number", `took 42ms`, 0, `42`, 7},
+		{"Digits inside a word", `user42 {"id": 1}`, 0, `{"id":1}`, 16},
+		{"Malformed literal skipped", `nullable - [null]`, 0, `[null]`, 17},
+		{"Multiple lines", "a\nb {\"k\":\n[]}", 0, `{"k":[]}`, 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, end, err := parser.ParseEmbedded(tt.input, tt.offset)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			data, _ := parser.Marshal(value)
+			if string(data) != tt.expected || end != tt.end {
+				t.Errorf("Expected %s ending at %d, got %s ending at %d", tt.expected, tt.end, data, end)
+			}
+		})
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		text := `GET /a {"status": 200} then {"status": 404, "path": "/b"} end`
+
+		var statuses []string
+
+		for offset := 0; ; {
+			value, end, err := parser.ParseEmbedded(text, offset)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if obj, ok := value.(*parser.Object); ok {
+				statuses = append(statuses, obj.Pairs["status"].TokenLiteral())
+			}
+
+			offset = end
+		}
+
+		if !reflect.DeepEqual(statuses, []string{"200", "404"}) {
+			t.Errorf("Expected both statuses, got %v", statuses)
+		}
+	})
+
+	t.Run("Malformed object", func(t *testing.T) {
+		_, end, err := parser.ParseEmbedded(`log {"a": } more`, 0)
+
+		var parseErr *parser.ParseError
+		if !errors.As(err, &parseErr) || end != 5 {
+			t.Errorf("Expected a ParseError resuming at 5, got %v at %d", err, end)
+		}
+	})
+
+	t.Run("Position", func(t *testing.T) {
+		// The position is counted on over the malformed candidates before the value
+		text := "- x1\n- -\né - " + strings.Repeat("- \n", 1000) + `é {"a": }`
+
+		_, _, err := parser.ParseEmbedded(text, 2)
+
+		var parseErr *parser.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != 1003 || parseErr.Column != 9 {
+			t.Errorf("Expected a ParseError at line 1003, column 9, got %v", err)
+		}
+	})
+
+	t.Run("No value", func(t *testing.T) {
+		if _, end, err := parser.ParseEmbedded(`nothing here`, 0); !errors.Is(err, io.EOF) || end != 12 {
+			t.Errorf("Expected io.EOF at the end, got %v at %d", err, end)
+		}
+	})
+}
+
+func TestArrayHint(t *testing.T) {
+	p := parser.NewParser(parser.NewLexer(`{"rows": [1, 2, 3], "more": [4]}`))
+	p.ArrayHint = 64
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rows := value.(*parser.Object).Pairs["rows"].(*parser.Array)
+	if len(rows.Elements) != 3 || cap(rows.Elements) < 64 {
+		t.Errorf("Expected 3 elements with reserved capacity, got len=%d cap=%d", len(rows.Elements), cap(rows.Elements))
+	}
+
+	more := value.(*parser.Object).Pairs["more"].(*parser.Array)
+	if len(more.Elements) != 1 || cap(more.Elements) >= 64 {
+		t.Errorf("Expected the hint to apply to the first array only, got len=%d cap=%d", len(more.Elements), cap(more.Elements))
+	}
+}
+
+func BenchmarkArrayHint(b *testing.B) {
+	const size = 100_000
+
+	input := "[" + strings.TrimSuffix(strings.Repeat("1,", size), ",") + "]"
+
+	for _, hint := range []int{0, size} {
+		b.Run(fmt.Sprintf("hint=%d", hint), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				p := parser.NewParser(parser.NewLexer(input))
+				p.ArrayHint = hint
+
+				if _, err := p.ParseJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeKeysNFC(t *testing.T) {
+	const (
+		composed   = "caf\u00e9"
+		decomposed = "cafe\u0301"
+	)
+
+	input := `{"` + decomposed + `": 1}`
+
+	p := parser.NewParser(parser.NewLexer(input))
+	p.NormalizeKeysNFC = true
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := value.(*parser.Object).Pairs[composed]; !ok {
+		t.Errorf("Expected key to be normalized to NFC, got %v", value.(*parser.Object).SortedKeys())
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Composed and decomposed", `{"` + composed + `": 1, "` + decomposed + `": 2}`},
+		{"Exact duplicate", `{"a": 1, "a": 2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(tt.input))
+			p.NormalizeKeysNFC = true
+
+			_, err := p.ParseJSON()
+			if err == nil || !strings.Contains(err.Error(), "duplicate key") {
+				t.Errorf("Expected duplicate key error, got %v", err)
+			}
+		})
+	}
+
+	// Without the option both spellings are kept as distinct keys
+	object := parseObject(t, `{"`+composed+`": 1, "`+decomposed+`": 2}`)
+	if len(object.Pairs) != 2 {
+		t.Errorf("Expected 2 distinct keys, got %d", len(object.Pairs))
+	}
+}
+
+// decimal is a custom number type produced by a NumberFactory.
+type decimal struct {
+	parser.NumberLiteral
+	digits string
+}
+
+func (d *decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.digits), nil
+}
+
+func TestNumberFactory(t *testing.T) {
+	factory := func(literal string) (parser.Value, error) {
+		if strings.ContainsAny(literal, "eE") {
+			return nil, errors.New("exponents are not supported")
+		}
+
+		return &decimal{digits: literal}, nil
+	}
+
+	p := parser.NewParser(parser.NewLexer(`{"price": 19.990000000000000001, "qty": [3]}`))
+	p.NumberFactory = factory
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	object := value.(*parser.Object)
+
+	price, ok := object.Pairs["price"].(*decimal)
+	if !ok || price.digits != "19.990000000000000001" {
+		t.Errorf("Expected decimal 19.990000000000000001, got %#v", object.Pairs["price"])
+	}
+
+	if qty, ok := object.Pairs["qty"].(*parser.Array).Elements[0].(*decimal); !ok || qty.digits != "3" {
+		t.Errorf("Expected decimal 3 inside the array, got %#v", object.Pairs["qty"])
+	}
+
+	// Marshaled through MarshalJSON, with every digit kept
+	data, err := parser.Marshal(value)
+	if expected := `{"price":19.990000000000000001,"qty":[3]}`; err != nil || string(data) != expected {
+		t.Errorf("Expected %s, got %s, %v", expected, data, err)
+	}
+
+	if err := parser.CheckSerializable(value); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	price.digits = "19.99.9"
+	if _, err := parser.Marshal(value); err == nil || !strings.Contains(err.Error(), "invalid JSON from *parser_test.decimal") {
+		t.Errorf("Expected an error for invalid MarshalJSON output, got %v", err)
+	}
+
+	p = parser.NewParser(parser.NewLexer(`[1e5]`))
+	p.NumberFactory = factory
+
+	_, err = p.ParseJSON()
+	if err == nil || !strings.Contains(err.Error(), "invalid number 1e5: exponents are not supported") {
+		t.Errorf("Expected factory error, got %v", err)
+	}
+}
+
+func TestMaxKeysPerObject(t *testing.T) {
+	var b strings.Builder
+
+	b.WriteString("{\"data\": {")
+
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		fmt.Fprintf(&b, "\"k%d\": %d", i, i)
+	}
+
+	b.WriteString("}}")
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected string
+	}{
+		{"Over the limit", 999, "Line 1, Column 10: object exceeds maximum of 999 keys"},
+		{"At the limit", 1000, ""},
+		{"Disabled", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(b.String()))
+			p.MaxKeysPerObject = tt.limit
+
+			_, err := p.ParseJSON()
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestMaxArrayLength(t *testing.T) {
+	input := `{"data": [` + strings.Repeat("1, ", 999) + `1], "small": [[1, 2], []]}`
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected string
+	}{
+		{"Over the limit", 999, "Line 1, Column 10: array exceeds maximum of 999 elements"},
+		{"At the limit", 1000, ""},
+		{"Nested array over the limit", 1, "Line 1, Column 10: array exceeds maximum of 1 elements"},
+		{"Disabled", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(input, parser.WithMaxArrayLength(tt.limit))
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// The streamed array itself is not limited, but its elements are
+	stream, err := parser.StreamArray(strings.NewReader(`[1, 2, 3, [4, 5, 6]]`), parser.WithMaxArrayLength(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	count := 0
+	for range stream.All() {
+		count++
+	}
+
+	if expected := "Line 1, Column 11: array exceeds maximum of 2 elements"; count != 3 || stream.Err() == nil || stream.Err().Error() != expected {
+		t.Errorf("Expected 3 elements and %q, got %d and %v", expected, count, stream.Err())
+	}
+}
+
+func TestMaxStringEscapes(t *testing.T) {
+	escaped := `{"a": "` + strings.Repeat(`\u0041`, 1000) + `"}`
+
+	tests := []struct {
+		name     string
+		input    string
+		limit    int
+		expected string
+	}{
+		{"Over the limit", escaped, 999, "Line 1, Column 7: string exceeds maximum of 999 escape sequences"},
+		{"At the limit", escaped, 1000, ""},
+		{"Disabled", escaped, 0, ""},
+		{"Key", `{"ok": 1, "\n\t\n": 2}`, 2, "Line 1, Column 11: string exceeds maximum of 2 escape sequences"},
+		{"Surrogate pair", `["\ud83d\ude00"]`, 1, "Line 1, Column 2: string exceeds maximum of 1 escape sequences"},
+		{"Surrogate pair at the limit", `["\ud83d\ude00"]`, 2, ""},
+		{"Unescaped strings", `["` + strings.Repeat("A", 1000) + `"]`, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input, parser.WithMaxStringEscapes(tt.limit))
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	v, err := parser.Parse(escaped, parser.WithMaxStringEscapes(1000))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := parser.GetStringOr(v, "a", ""); got != strings.Repeat("A", 1000) {
+		t.Errorf("Expected the escapes to be decoded, got %q", got)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	keywords := map[string]parser.Value{
+		"None":      parser.NewNull(),
+		"True":      &parser.Boolean{Token: parser.Token{Type: parser.TokenTrue, Literal: "true"}, Value: true},
+		"False":     &parser.Boolean{Token: parser.Token{Type: parser.TokenFalse, Literal: "false"}, Value: false},
+		"+Infinity": &parser.NumberLiteral{Value: "+Infinity", Float: math.Inf(1), IsValid: true},
+		"undefined": parser.NewNull(),
+	}
+
+	p := parser.NewParser(parser.NewLexer(`{"a": None, "b": [True, False, true, null], "c": +Infinity, "d": undefined}`))
+	p.Keywords = keywords
+
+	value, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	object := value.(*parser.Object)
+
+	if _, ok := object.Pairs["a"].(*parser.Null); !ok {
+		t.Errorf("Expected None to parse as null, got %T", object.Pairs["a"])
+	}
+
+	elements := object.Pairs["b"].(*parser.Array).Elements
+	if !elements[0].(*parser.Boolean).Value || elements[1].(*parser.Boolean).Value || !elements[2].(*parser.Boolean).Value {
+		t.Errorf("Expected [true, false, true], got %v", elements)
+	}
+
+	if num := object.Pairs["c"].(*parser.NumberLiteral); !math.IsInf(num.Float, 1) {
+		t.Errorf("Expected +Infinity, got %v", num.Float)
+	}
+
+	if object.Pairs["a"] == keywords["None"] {
+		t.Error("Expected each keyword occurrence to get its own node")
+	}
+
+	// Comments before a keyword stay with it, and are dropped rather than passed on for a
+	// shared container
+	keywords["EMPTY"] = &parser.Object{Pairs: map[string]parser.Value{}}
+
+	value, err = parser.Parse(`[/* none */ None, /* empty */ EMPTY, 1]`, parser.WithKeywords(keywords), parser.WithRetainedComments())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	elements = value.(*parser.Array).Elements
+
+	if comments := parser.LeadingComments(elements[0]); !reflect.DeepEqual(comments, []string{"/* none */"}) {
+		t.Errorf("Expected the comment on the keyword, got %q", comments)
+	}
+
+	if comments := parser.LeadingComments(elements[1]); comments != nil {
+		t.Errorf("Expected no comment on the shared container, got %q", comments)
+	}
+
+	if comments := parser.LeadingComments(elements[2]); comments != nil {
+		t.Errorf("Expected no comment on the value after the keyword, got %q", comments)
+	}
+
+	tests := []struct {
+		name     string
+		keywords map[string]parser.Value
+		expected string
+	}{
+		{"Unknown bareword", keywords, "Line 1, Column 7: unknown keyword Nothing"},
+		{"Strict by default", nil, "Line 1, Column 7: expected string key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(`{"a": Nothing}`))
+			p.Keywords = tt.keywords
+
+			_, err := p.ParseJSON()
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestStreamingLargeInput(t *testing.T) {
+	var b strings.Builder
+
+	b.WriteString(`{"long": "` + strings.Repeat("日本", 5000) + `", "items": [`)
+
+	for i := 0; i < 2000; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		fmt.Fprintf(&b, `{"id": %d, "name": "é%d", "ok": true}`, i, i)
+	}
+
+	b.WriteString(`], "big": 12345678901234567890.5}`)
+
+	expected := mustParse(t, b.String())
+
+	readers := []struct {
+		name   string
+		reader func() io.Reader
+	}{
+		{"Chunked", func() io.Reader { return strings.NewReader(b.String()) }},
+		{"One byte at a time", func() io.Reader { return iotest.OneByteReader(strings.NewReader(b.String())) }},
+	}
+
+	for _, tt := range readers {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parser.NewParser(parser.NewLexer(tt.reader())).ParseJSON()
+			if err != nil {
+				t.Fatalf("Error parsing JSON: %v", err)
+			}
+
+			if !parser.Equal(value, expected) {
+				t.Error("Expected streamed document to match the in-memory parse")
+			}
+		})
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	const input = `{"name": "gzip", "values": [1, 2, 3]}`
+
+	var compressed bytes.Buffer
+
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write([]byte(input)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := mustParse(t, input)
+
+	t.Run("Plain", func(t *testing.T) {
+		value, err := parser.ParseReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !parser.Equal(value, expected) {
+			t.Error("Expected plain input to parse unchanged")
+		}
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		value, err := parser.ParseReader(bytes.NewReader(compressed.Bytes()))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !parser.Equal(value, expected) {
+			t.Error("Expected gzip input to be decompressed")
+		}
+	})
+
+	t.Run("Corrupt gzip", func(t *testing.T) {
+		corrupt := bytes.Clone(compressed.Bytes())
+		corrupt[len(corrupt)-5] ^= 0xff // Damage the checksum
+
+		_, err := parser.ParseReader(bytes.NewReader(corrupt))
+		if !errors.Is(err, gzip.ErrChecksum) {
+			t.Errorf("Expected checksum error, got %v", err)
+		}
+	})
+}
+
+func TestParseErrorPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Nested value", `{"items": [{"price": 1}, {"price": 2}, {"price": 3}, {"price": 01}]}`, "/items/3/price"},
+		{"Escaped key", `{"a/b": {"m~n": [1, tru]}}`, "/a~1b/m~0n/1"},
+		{"Container syntax", `{"items": [1, 2}`, "/items"},
+		{"Top level", `{"a": 1,}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input)
+
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *parser.ParseError, got %v", err)
+			}
+
+			if parseErr.Path != tt.expected {
+				t.Errorf("Expected path %q, got %q (%v)", tt.expected, parseErr.Path, err)
+			}
+		})
+	}
+}
+
+func TestLiteralCase(t *testing.T) {
+	for _, literal := range []string{"True", "FALSE", "NULL", "Null", "tRUE", "falsE", "nulL"} {
+		t.Run(literal, func(t *testing.T) {
+			for _, input := range []string{"[" + literal + "]", `{"a": ` + literal + "}"} {
+				_, err := parser.Parse(input)
+
+				var parseErr *parser.ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("Expected a *ParseError for %s, got %v", input, err)
+				}
+
+				if column := strings.Index(input, literal) + 1; parseErr.Line != 1 || parseErr.Column != column {
+					t.Errorf("Expected the error at 1:%d for %s, got %v", column, input, err)
+				}
+			}
+		})
+	}
+
+	t.Run("Accepted as an explicit keyword", func(t *testing.T) {
+		value, err := parser.Parse(`[True]`, parser.WithKeywords(map[string]parser.Value{"True": &parser.Boolean{Value: true}}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !parser.Equal(value, mustParse(t, `[true]`)) {
+			t.Errorf("Expected [true], got %v", value)
+		}
+	})
+}
+
+func TestJSONNumber(t *testing.T) {
+	for _, literal := range []string{"0", "-12", "1.50", "1e3", "2.5E-7", "123456789012345678901234567890"} {
+		t.Run(literal, func(t *testing.T) {
+			value := mustParse(t, "["+literal+"]")
+			number := value.(*parser.Array).Elements[0].(*parser.NumberLiteral).JSONNumber()
+
+			if number != json.Number(literal) {
+				t.Errorf("Expected %s, got %s", literal, number)
+			}
+
+			if _, err := number.Float64(); err != nil {
+				t.Errorf("Unexpected error converting %s: %v", number, err)
+			}
+		})
+	}
+}
+
+func TestNumberOverflow(t *testing.T) {
+	value, err := parser.Parse(`[9223372036854775807, 9223372036854775808, -9223372036854775809]`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	elements := value.(*parser.Array).Elements
+
+	if maxInt := elements[0].(*parser.NumberLiteral); !maxInt.IsInt || maxInt.Overflowed || maxInt.Int != math.MaxInt64 {
+		t.Errorf("Expected int64 max to fit exactly, got %#v", maxInt)
+	}
+
+	for _, elem := range elements[1:] {
+		num := elem.(*parser.NumberLiteral)
+		if !num.IsValid || num.IsInt || !num.Overflowed {
+			t.Errorf("Expected %s to be a valid overflowed float, got %#v", num.Value, num)
+		}
+	}
+
+	if f := elements[1].(*parser.NumberLiteral).Float; f != 9223372036854775808 {
+		t.Errorf("Expected the closest float64, got %v", f)
+	}
+
+	_, err = parser.Parse(`{"id": 9223372036854775808}`, parser.WithStrictNumbers())
+	if err == nil || err.Error() != "Line 1, Column 8: integer 9223372036854775808 overflows int64" {
+		t.Errorf("Expected overflow error, got %v", err)
+	}
 
-	value, err := p.ParseJSON()
+	if _, err := parser.Parse(`[9223372036854775807, 1.5]`, parser.WithStrictNumbers()); err != nil {
+		t.Errorf("Unexpected error for representable numbers: %v", err)
+	}
+}
+
+func TestDuplicateKeysToArray(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Promoted on repetition", `{"x": 1, "x": 2}`, `{"x":[1,2]}`},
+		{"Appended after promotion", `{"x": 1, "y": true, "x": "two", "x": {"n": 3}}`, `{"x":[1,"two",{"n":3}],"y":true}`},
+		{"Single occurrence stays scalar", `{"x": 1, "y": [2]}`, `{"x":1,"y":[2]}`},
+		{"Existing array is nested", `{"x": [1], "x": 2}`, `{"x":[[1],2]}`},
+		{"Nested objects", `{"a": {"x": 1, "x": 2}, "a": null}`, `{"a":[{"x":[1,2]},null]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parser.Parse(tt.input, parser.WithDuplicateKeysToArray())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got, err := parser.Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	value, err := parser.Parse(`{"x": 1, "x": 2}`)
 	if err != nil {
-		t.Fatalf("Error parsing JSON: %v", err)
+		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	if obj, ok := value.(*parser.Object); !ok || len(obj.Pairs) != 4 {
-		t.Fatalf("Parsing resulted in wrong object structure: %+v", value)
+	if x := value.(*parser.Object).Pairs["x"].(*parser.NumberLiteral); x.Int != 2 {
+		t.Errorf("Expected the last value to win by default, got %v", x)
 	}
 }
 
-func TestNumberParsing(t *testing.T) {
+func TestMaxDocumentSize(t *testing.T) {
 	tests := []struct {
-		input      string
-		wantInt    bool
-		wantValue  interface{} // can be int64 or float64
-		shouldFail bool
+		name     string
+		input    io.Reader
+		expected string
 	}{
-		// Valid cases
-		{
-			input:      `{"num": 123}`,
-			wantInt:    true,
-			wantValue:  int64(123),
-			shouldFail: false,
-		},
-		{
-			input:      `{"num": 123.456}`,
-			wantInt:    false,
-			wantValue:  float64(123.456),
-			shouldFail: false,
-		},
-		{
-			input:      `{"num": -123}`,
-			wantInt:    true,
-			wantValue:  int64(-123),
-			shouldFail: false,
-		},
-		{
-			input:      `{"num": 1e5}`,
-			wantInt:    false,
-			wantValue:  float64(100000),
-			shouldFail: false,
-		},
-		{
-			input:      `{"num": 1.2e-3}`,
-			wantInt:    false,
-			wantValue:  float64(0.0012),
-			shouldFail: false,
-		},
-		// Invalid cases
-		{
-			input:      `{"num": 01234}`, // Leading zeros not allowed
-			shouldFail: true,
-		},
-		{
-			input:      `{"num": .123}`, // Must start with digit
-			shouldFail: true,
-		},
-		{
-			input:      `{"num": 123.}`, // Must have digits after decimal
-			shouldFail: true,
-		},
-		{
-			input:      `{"num": -}`, // Must have digits after minus
-			shouldFail: true,
-		},
-		{
-			input:      `{"num": 1.2e}`, // Must have digits after exponent
-			shouldFail: true,
-		},
-		{
-			input:      `{"num": 1.2e-}`, // Must have digits after exponent sign
-			shouldFail: true,
-		},
+		{"Within the limit", strings.NewReader(`{"a": [1, 2]}`), ""},
+		{"Exactly the limit", strings.NewReader(`{"a": [1, 2, 3, 4, 5, 6, 7, 88]}` + "\n\n\n"), ""},
+		{"Endless string", io.MultiReader(strings.NewReader(`{"a": "`), iotest.OneByteReader(strings.NewReader(strings.Repeat("x", 1<<20)))), "Line 1, Column 32: document exceeds maximum size of 32 bytes"},
+		{"Missing closing bracket", strings.NewReader("[1,\n" + strings.Repeat("2,\n", 100)), "Line 11, Column 1: document exceeds maximum size of 32 bytes"},
 	}
 
-	for i, tt := range tests {
-		t.Run(fmt.Sprintf("Case %d: %s", i, tt.input), func(t *testing.T) {
-			l := parser.NewLexer(tt.input)
-			p := parser.NewParser(l)
-			value, err := p.ParseJSON()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(tt.input), parser.WithMaxDocumentSize(32))
 
-			if tt.shouldFail {
-				if err == nil {
-					t.Errorf("Expected error for input %s but got none", tt.input)
+			_, err := p.ParseJSON()
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				return
 			}
 
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestDeepNestingWithSmallStack(t *testing.T) {
+	// A recursive parser needs far more than this for a million levels
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	const depth = 1000000
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Arrays", strings.Repeat("[", depth) + strings.Repeat("]", depth)},
+		{"Objects", strings.Repeat(`{"a":`, depth) + "null" + strings.Repeat("}", depth)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parser.Parse(tt.input)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
 
-			obj, ok := value.(*parser.Object)
-			if !ok {
-				t.Fatalf("Expected Object, got %T", value)
+			levels := 0
+
+			for value != nil {
+				levels++
+
+				switch v := value.(type) {
+				case *parser.Array:
+					value = nil
+					if len(v.Elements) > 0 {
+						value = v.Elements[0]
+					}
+				case *parser.Object:
+					value = v.Pairs["a"]
+				default:
+					value = nil
+					levels--
+				}
 			}
 
-			num, ok := obj.Pairs["num"].(*parser.NumberLiteral)
-			if !ok {
-				t.Fatalf("Expected NumberLiteral, got %T", obj.Pairs["num"])
+			if levels != depth {
+				t.Errorf("Expected %d levels, got %d", depth, levels)
 			}
+		})
+	}
 
-			if num.IsInt != tt.wantInt {
-				t.Errorf("IsInt = %v, want %v", num.IsInt, tt.wantInt)
+	_, err := parser.Parse(strings.Repeat("[", depth), parser.WithMaxDepth(64))
+	if err == nil || !strings.Contains(err.Error(), "maximum nesting depth of 64 exceeded") {
+		t.Errorf("Expected depth error, got %v", err)
+	}
+
+	// Subtrees that are checked without being built are not recursed into either
+	skip := parser.WithShouldDescend(func(path string, _ parser.Token) bool { return path == "" })
+
+	for _, tt := range tests {
+		t.Run(tt.name+" skipped", func(t *testing.T) {
+			if _, err := parser.Parse(`{"a": `+tt.input+`}`, skip); err != nil {
+				t.Errorf("Unexpected error: %v", err)
 			}
 
-			if tt.wantInt {
-				if num.Int != tt.wantValue.(int64) {
-					t.Errorf("Int = %d, want %d", num.Int, tt.wantValue.(int64))
-				}
-			} else {
-				if math.Abs(num.Float-tt.wantValue.(float64)) > 1e-10 {
-					t.Errorf("Float = %g, want %g", num.Float, tt.wantValue.(float64))
-				}
+			if err := parser.ValidateStream(strings.NewReader(tt.input)); err != nil {
+				t.Errorf("Unexpected error: %v", err)
 			}
 		})
 	}
+
+	input := `{"a": ` + strings.Repeat("[", 50) + strings.Repeat("]", 50) + `}`
+	if _, err := parser.Parse(input, skip, parser.WithMaxDepth(10)); err == nil || !strings.Contains(err.Error(), "maximum nesting depth of 10 exceeded") {
+		t.Errorf("Expected depth error for a skipped subtree, got %v", err)
+	}
 }
 
 func FuzzParseJSON(f *testing.F) {
@@ -343,6 +1631,30 @@ func BenchmarkParseJSON(b *testing.B) {
 	}
 }
 
+func BenchmarkParseNesting(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		input string
+	}{
+		{"Shallow records", recordsDataset(1000)},
+		{"Small document", `{"key1": "value1", "key2": 123, "key3": [1, 2, 3], "key4": {"nestedKey": "nestedValue"}}`},
+		{"Deep arrays", strings.Repeat("[", 10000) + strings.Repeat("]", 10000)},
+		{"Deep objects", strings.Repeat(`{"a":`, 10000) + "null" + strings.Repeat("}", 10000)},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.Parse(bm.input); err != nil {
+					b.Fatalf("Error parsing JSON: %v", err)
+				}
+			}
+		})
+	}
+}
+
 func TestStreamingJSON(t *testing.T) {
 	input := `{
 		"key1": "value1",
@@ -554,3 +1866,234 @@ func hasMatchingError(errors []string, expectedErr string) bool {
 
 	return false
 }
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Solidus", `"a\/b"`, "a/b"},
+		{"Quote and backslash", `"say \"hi\" \\ bye"`, `say "hi" \ bye`},
+		{"Control characters", `"\b\f\n\r\t"`, "\b\f\n\r\t"},
+		{"Unicode", `"\u00e9\u20AC"`, "é€"},
+		{"Surrogate pair", `"\ud83d\ude00"`, "😀"},
+		{"Lone high surrogate", `"\ud83dx"`, "�x"},
+		{"Lone low surrogate", `"\ude00"`, "�"},
+		{"High surrogate before another escape", `"\ud83d\n"`, "�\n"},
+		{"Two high surrogates", `"\ud83d\ud83d\ude00"`, "�😀"},
+		{"Raw slash", `"a/b"`, "a/b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := mustParse(t, "["+tt.input+"]")
+
+			got := value.(*parser.Array).Elements[0].(*parser.StringLiteral).Value
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Escaped key", func(t *testing.T) {
+		obj := mustParse(t, `{"a\/b": 1}`).(*parser.Object)
+
+		if _, ok := obj.Pairs["a/b"]; !ok {
+			t.Errorf("Expected key a/b, got %v", obj.SortedKeys())
+		}
+
+		if _, ok := parser.ResolvePointer(obj, "/a~1b"); !ok {
+			t.Error("Expected /a~1b to resolve")
+		}
+	})
+
+	for _, input := range []string{`["\x"]`, `["\u12"]`, `["\u12g4"]`, `["\ud83d\u12"]`, `["\`} {
+		t.Run("Invalid "+input, func(t *testing.T) {
+			if _, err := parser.Parse(input); err == nil {
+				t.Errorf("Expected an error for %s, got nil", input)
+			}
+		})
+	}
+}
+
+func TestAllowLeadingZeros(t *testing.T) {
+	tests := []struct {
+		input    string
+		isInt    bool
+		i        int64
+		f        float64
+		rendered string
+	}{
+		{`007`, true, 7, 7, `7`},
+		{`00`, true, 0, 0, `0`},
+		{`-007`, true, -7, -7, `-7`},
+		{`007.50`, false, 0, 7.5, `7.5`},
+		{`0012e1`, false, 0, 120, `120`},
+		{`7`, true, 7, 7, `7`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			input := `{"n": ` + tt.input + `}`
+
+			if _, err := parser.Parse(input); tt.input != "7" && err == nil {
+				t.Errorf("Expected strict parsing to reject %s", tt.input)
+			}
+
+			value, err := parser.Parse(input, parser.WithLeadingZeros())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			n := value.(*parser.Object).Pairs["n"].(*parser.NumberLiteral)
+			if n.Value != tt.input || n.IsInt != tt.isInt || n.IsInt && n.Int != tt.i || n.Float != tt.f {
+				t.Errorf("Expected %s to hold %v, %d and %v, got %+v", tt.input, tt.isInt, tt.i, tt.f, n)
+			}
+
+			data, err := parser.Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if expected := `{"n":` + tt.rendered + `}`; string(data) != expected {
+				t.Errorf("Expected %s, got %s", expected, data)
+			}
+
+			if size := parser.SizeBytes(value); size != len(data) || !parser.FitsWithin(value, len(data)) {
+				t.Errorf("Expected a size of %d, got %d", len(data), size)
+			}
+		})
+	}
+
+	// The padded literal is kept as source text under PreserveFormatting
+	value, err := parser.Parse(`{"id": 007, "n": 1}`, parser.WithLeadingZeros(), parser.WithPreserveFormatting())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	value.(*parser.Object).Set("n", parser.NewNumberLiteral(parser.Token{Type: parser.TokenNumber, Literal: "2"}))
+
+	data, err := parser.MarshalWith(value, parser.MarshalOptions{PreserveFormatting: true})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if expected := `{"id": 007, "n": 2}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestTrimStringValues(t *testing.T) {
+	input := `{" key ": "  info ", "list": ["\tx\n", " ", "a b"]}`
+
+	t.Run("Default", func(t *testing.T) {
+		obj := mustParse(t, input).(*parser.Object)
+
+		if got := obj.Pairs[" key "].(*parser.StringLiteral).Value; got != "  info " {
+			t.Errorf("Expected the string untouched, got %q", got)
+		}
+
+		if got := obj.Pairs["list"].(*parser.Array).Elements[0].(*parser.StringLiteral).Value; got != "\tx\n" {
+			t.Errorf("Expected the escapes untouched, got %q", got)
+		}
+	})
+
+	t.Run("Trimmed", func(t *testing.T) {
+		value, err := parser.Parse(input, parser.WithTrimmedStrings())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := parser.Marshal(value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `{" key ":"info","list":["x","","a b"]}`
+		if string(data) != expected {
+			t.Errorf("Expected %s, got %s", expected, data)
+		}
+	})
+}
+
+func TestEmptyKeys(t *testing.T) {
+	input := `{"": 1, "a": {"": {"b": 2}, "c": 3}}`
+
+	v, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got, ok := v.(*parser.Object).Get(""); !ok || got.String() != "1" {
+		t.Errorf(`Expected Get("") to return 1, got %v, %v`, got, ok)
+	}
+
+	lookups := []struct {
+		name     string
+		pointer  string
+		path     string
+		expected int64
+	}{
+		{"Top-level key", "/", "[]", 1},
+		{"Nested key", "/a//b", "a..b", 2},
+		{"Sibling", "/a/c", "a.c", 3},
+	}
+
+	for _, tt := range lookups {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := parser.ResolvePointer(v, tt.pointer); !ok || got.String() != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected pointer %q to resolve to %d, got %v, %v", tt.pointer, tt.expected, got, ok)
+			}
+
+			if got := parser.GetIntOr(v, tt.path, -1); got != tt.expected {
+				t.Errorf("Expected path %q to resolve to %d, got %d", tt.path, tt.expected, got)
+			}
+		})
+	}
+
+	if _, ok := parser.GetOr(v, "a.", nil).(*parser.Object); !ok {
+		t.Error(`Expected path "a." to resolve to the object under the empty key`)
+	}
+
+	disallowed := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Top-level key", input, "Line 1, Column 2: empty key is not allowed"},
+		{"Nested key", `{"a": [{"x": 1, "": 2}]}`, "Line 1, Column 17: empty key is not allowed"},
+	}
+
+	for _, tt := range disallowed {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input, parser.WithoutEmptyKeys())
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if _, err := parser.Parse(`{"a": {" ": ""}}`, parser.WithoutEmptyKeys()); err != nil {
+		t.Errorf("Expected non-empty keys and empty values to be accepted, got %v", err)
+	}
+}
+
+func TestParseJSONConsecutiveDocuments(t *testing.T) {
+	p := parser.NewParser(parser.NewLexer("{\"a\": 1}\n[2, 3] {}"))
+
+	for _, expected := range []string{`{"a":1}`, `[2,3]`, `{}`} {
+		v, err := p.ParseJSON()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got, _ := parser.Marshal(v); string(got) != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+
+	if _, err := p.ParseJSON(); err == nil || !strings.Contains(err.Error(), "empty document") {
+		t.Errorf("Expected the end of the input to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// ApplyPatch applies the RFC 6902 JSON Patch patch, an array of operation objects such as
+// {"op": "replace", "path": "/port", "value": 8080}, to doc and returns the result. All six
+// operations are supported: add, remove, replace, move, copy and test. The operations are
+// applied in order to a copy of doc, so doc is left unchanged, and the first one that fails
+// stops the patch with an error naming its index, as RFC 6902 requires the patch to be applied
+// atomically. The values of the patch are copied into the result rather than shared.
+func ApplyPatch(doc Value, patch *Array) (Value, error) {
+	result := clone(doc)
+	if patch == nil {
+		return result, nil
+	}
+
+	for i, elem := range patch.Elements {
+		var err error
+
+		result, err = applyOperation(result, elem)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// applyOperation applies the JSON Patch operation op to root and returns the new root.
+func applyOperation(root, op Value) (Value, error) {
+	obj, ok := op.(*Object)
+	if !ok {
+		return nil, fmt.Errorf("operation is %s, not an object", aTypeOf(op))
+	}
+
+	name, err := operationString(obj, "op")
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := operationPointer(obj, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "add", "replace", "test":
+		value, ok := obj.Pairs["value"]
+		if !ok {
+			return nil, fmt.Errorf("%s operation has no value", name)
+		}
+
+		switch name {
+		case "add":
+			return patchAdd(root, path, clone(value))
+		case "replace":
+			if _, ok := resolveTokens(root, path); !ok {
+				return nil, fmt.Errorf("cannot replace %q: no value there", prefixPointer(path))
+			}
+
+			if len(path) == 0 {
+				return clone(value), nil
+			}
+
+			root, _, err = patchRemove(root, path)
+			if err != nil {
+				return nil, err
+			}
+
+			return patchAdd(root, path, clone(value))
+		default:
+			if current, ok := resolveTokens(root, path); !ok || !Equal(current, value) {
+				return nil, fmt.Errorf("test of %q failed", prefixPointer(path))
+			}
+
+			return root, nil
+		}
+
+	case "remove":
+		root, _, err = patchRemove(root, path)
+		return root, err
+
+	case "move", "copy":
+		from, err := operationPointer(obj, "from")
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := resolveTokens(root, from)
+		if !ok {
+			return nil, fmt.Errorf("cannot %s from %q: no value there", name, prefixPointer(from))
+		}
+
+		if name == "copy" {
+			return patchAdd(root, path, clone(value))
+		}
+
+		if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+			return nil, fmt.Errorf("cannot move %q into itself", prefixPointer(from))
+		}
+
+		root, value, err = patchRemove(root, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return patchAdd(root, path, value)
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+}
+
+// operationString returns the string member key of the operation op.
+func operationString(op *Object, key string) (string, error) {
+	s, ok := op.Pairs[key].(*StringLiteral)
+	if !ok {
+		return "", fmt.Errorf("operation has no string %q member", key)
+	}
+
+	return s.Value, nil
+}
+
+// operationPointer returns the reference tokens of the JSON Pointer held by the member key of the
+// operation op.
+func operationPointer(op *Object, key string) ([]string, error) {
+	pointer, err := operationString(op, key)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, ok := parsePointer(pointer)
+	if !ok {
+		return nil, fmt.Errorf("malformed pointer %q", pointer)
+	}
+
+	return tokens, nil
+}
+
+// patchAdd adds v at the location tokens identify in root and returns the new root: it replaces
+// root for the empty pointer, sets an object member, or inserts into an array at an index up to
+// its length, or at its end for "-".
+func patchAdd(root Value, tokens []string, v Value) (Value, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+
+	parent, ok := resolveTokens(root, tokens[:len(tokens)-1])
+	if !ok {
+		return nil, fmt.Errorf("cannot add at %q: parent does not exist", prefixPointer(tokens))
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch val := parent.(type) {
+	case *Object:
+		val.Set(last, v)
+	case *Array:
+		index, ok := len(val.Elements), last == "-"
+		if !ok {
+			index, ok = arrayIndex(last, len(val.Elements)+1)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("cannot add at %q: index out of range", prefixPointer(tokens))
+		}
+
+		val.Elements = slices.Insert(val.Elements, index, v)
+	default:
+		return nil, fmt.Errorf("cannot add at %q: parent is %s", prefixPointer(tokens), aTypeOf(parent))
+	}
+
+	return root, nil
+}
+
+// patchRemove removes the value at the location tokens identify in root, which cannot be root
+// itself, and returns the new root along with the removed value.
+func patchRemove(root Value, tokens []string) (Value, Value, error) {
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+
+	parent, _ := resolveTokens(root, tokens[:len(tokens)-1])
+	last := tokens[len(tokens)-1]
+
+	switch val := parent.(type) {
+	case *Object:
+		if removed, ok := val.Pairs[last]; ok {
+			delete(val.Pairs, last)
+			return root, removed, nil
+		}
+	case *Array:
+		if index, ok := arrayIndex(last, len(val.Elements)); ok {
+			removed := val.Elements[index]
+			val.Elements = slices.Delete(val.Elements, index, index+1)
+
+			return root, removed, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("cannot remove %q: no value there", prefixPointer(tokens))
+}
+
+// PatchDiff returns the RFC 6902 JSON Patch that turns from into to when applied with ApplyPatch,
+// for sending changes to consumers that speak JSON Patch rather than merge patches. Objects
+// present on both sides are compared member by member, in key order: removed members become
+// remove operations, added ones add operations, and members on both sides are compared in turn.
+// Arrays are compared with the edit script of ArrayDiff, so inserting an element into a long
+// array is a single add rather than a replace of every element after it; an element deleted
+// where another is inserted is compared with it like a member, so a change deep inside an element
+// stays a single operation. Any other difference, a scalar or a change of type, becomes a
+// replace, of the whole document when it is at the root. Values are compared like Equal, and
+// the values of the patch are copies, so it shares no nodes with to.
+//
+// PatchDiff returns an error when from or to is nil, since there is no document to patch.
+func PatchDiff(from, to Value) (*Array, error) {
+	if from == nil || to == nil {
+		return nil, errors.New("cannot diff a missing document")
+	}
+
+	patch := newArray(nil)
+	patchValues(patch, "", from, to)
+
+	return patch, nil
+}
+
+// patchValues appends to patch the operations that turn a into b, found at pointer.
+func patchValues(patch *Array, pointer string, a, b Value) {
+	if Equal(a, b) {
+		return
+	}
+
+	switch x := a.(type) {
+	case *Object:
+		if y, ok := b.(*Object); ok {
+			patchObjects(patch, pointer, x, y)
+			return
+		}
+
+	case *Array:
+		if y, ok := b.(*Array); ok {
+			patchArrays(patch, pointer, x, y)
+			return
+		}
+	}
+
+	appendOperation(patch, "replace", pointer, b)
+}
+
+// patchObjects appends to patch the operations that turn the object a into b, in key order.
+func patchObjects(patch *Array, pointer string, a, b *Object) {
+	keys := make([]string, 0, len(a.Pairs)+len(b.Pairs))
+	for k := range a.Pairs {
+		keys = append(keys, k)
+	}
+
+	for k := range b.Pairs {
+		if _, ok := a.Pairs[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := pointer + "/" + escapePointerToken(k)
+		old, inA := a.Pairs[k]
+		value, inB := b.Pairs[k]
+
+		switch {
+		case !inB:
+			appendOperation(patch, "remove", path, nil)
+		case !inA:
+			appendOperation(patch, "add", path, value)
+		default:
+			patchValues(patch, path, old, value)
+		}
+	}
+}
+
+// patchArrays appends to patch the operations that turn the array a into b, following the edit
+// script of ArrayDiff. Within each run of deletions and insertions between kept elements,
+// deletions and insertions are paired up and compared, and the rest are removed or added.
+func patchArrays(patch *Array, pointer string, a, b *Array) {
+	ops := ArrayDiff(a, b)
+	index := 0 // of the next element in the array being patched
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ArrayKeep {
+			index++
+			i++
+
+			continue
+		}
+
+		var deleted, inserted []Value
+		for ; i < len(ops) && ops[i].Kind == ArrayDelete; i++ {
+			deleted = append(deleted, ops[i].Value)
+		}
+
+		for ; i < len(ops) && ops[i].Kind == ArrayInsert; i++ {
+			inserted = append(inserted, ops[i].Value)
+		}
+
+		paired := min(len(deleted), len(inserted))
+		for j := 0; j < paired; j++ {
+			patchValues(patch, pointer+"/"+strconv.Itoa(index), deleted[j], inserted[j])
+			index++
+		}
+
+		for range deleted[paired:] {
+			appendOperation(patch, "remove", pointer+"/"+strconv.Itoa(index), nil)
+		}
+
+		for _, v := range inserted[paired:] {
+			appendOperation(patch, "add", pointer+"/"+strconv.Itoa(index), v)
+			index++
+		}
+	}
+}
+
+// appendOperation appends the JSON Patch operation op at pointer to patch, with a copy of value
+// unless it is nil.
+func appendOperation(patch *Array, op, pointer string, value Value) {
+	operation := newObject().
+		Set("op", &StringLiteral{Token: Token{Type: TokenString, Literal: op}, Value: op}).
+		Set("path", &StringLiteral{Token: Token{Type: TokenString, Literal: pointer}, Value: pointer})
+
+	if value != nil {
+		operation.Set("value", clone(value))
+	}
+
+	patch.Elements = append(patch.Elements, operation)
+}
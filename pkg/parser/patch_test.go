@@ -0,0 +1,124 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// Test cases from RFC 6902, appendix A
+	tests := []struct {
+		name, doc, patch, expected string
+	}{
+		{"Add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"Add element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"Remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"Remove element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"Replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"Move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"Move element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"Test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{"Add nested", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"Escaped key", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"Append", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"Copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"Replace root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := mustParse(t, tt.doc)
+			before, _ := parser.Marshal(doc)
+
+			got, err := parser.ApplyPatch(doc, mustParse(t, tt.patch).(*parser.Array))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !parser.Equal(got, mustParse(t, tt.expected)) {
+				data, _ := parser.Marshal(got)
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			if after, _ := parser.Marshal(doc); string(after) != string(before) {
+				t.Errorf("Expected the document to be left unchanged, got %s", after)
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name, doc, patch, expected string
+	}{
+		{"Failed test", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, `patch operation 0: test of "/baz" failed`},
+		{"Missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, `patch operation 0: cannot add at "/baz/bat": parent does not exist`},
+		{"Index out of range", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/3","value":1}]`, `patch operation 0: cannot add at "/foo/3": index out of range`},
+		{"Remove missing", `{"a":1}`, `[{"op":"add","path":"/b","value":2},{"op":"remove","path":"/c"}]`, `patch operation 1: cannot remove "/c": no value there`},
+		{"Move into itself", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, `patch operation 0: cannot move "/a" into itself`},
+		{"Unknown operation", `{}`, `[{"op":"merge","path":""}]`, `patch operation 0: unknown operation "merge"`},
+		{"Missing value", `{}`, `[{"op":"add","path":"/a"}]`, `patch operation 0: add operation has no value`},
+		{"Malformed pointer", `{}`, `[{"op":"remove","path":"a"}]`, `patch operation 0: malformed pointer "a"`},
+		{"Operation not an object", `{}`, `[[1]]`, `patch operation 0: operation is an array, not an object`},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.ApplyPatch(mustParse(t, tt.doc), mustParse(t, tt.patch).(*parser.Array))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestPatchDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string // the patch, or empty to only check the round trip
+	}{
+		{"Equal", `{"a": [1, 2.0]}`, `{"a": [1, 2.00]}`, `[]`},
+		{"Members", `{"port": 80, "host": "a", "tls": true}`, `{"port": 8080, "host": "a", "debug": false}`,
+			`[{"op":"add","path":"/debug","value":false},{"op":"replace","path":"/port","value":8080},{"op":"remove","path":"/tls"}]`},
+		{"Nested", `{"s": {"tls": {"cert": "a"}, "a/b": 1}}`, `{"s": {"tls": {"cert": "b"}}}`,
+			`[{"op":"remove","path":"/s/a~1b"},{"op":"replace","path":"/s/tls/cert","value":"b"}]`},
+		{"Array insert", `[1, 2, 3, 4, 5]`, `[1, 2, 9, 3, 4, 5]`, `[{"op":"add","path":"/2","value":9}]`},
+		{"Array delete", `[1, 2, 3, 4, 5]`, `[1, 3, 4, 5]`, `[{"op":"remove","path":"/1"}]`},
+		{"Array element changed", `[{"id": 1, "v": "a"}, {"id": 2}]`, `[{"id": 1, "v": "b"}, {"id": 2}]`,
+			`[{"op":"replace","path":"/0/v","value":"b"}]`},
+		{"Type changed", `{"a": {"x": 1}}`, `{"a": [1]}`, `[{"op":"replace","path":"/a","value":[1]}]`},
+		{"Root", `{}`, `[]`, `[{"op":"replace","path":"","value":[]}]`},
+		{"Moved elements", `["a", "b", "c", "d"]`, `["d", "c", "b", "a", "e"]`, ""},
+		{"Mixed", `{"list": [1, {"a": [1, 2]}, 3, 3], "keep": null, "gone": {}}`,
+			`{"list": [0, {"a": [2, 1]}, 3, "x", 4], "keep": null, "new": {"n": [true]}}`, ""},
+		{"Replaced runs", `[1, 2, 3, 9, 4]`, `[5, 9, 6, 7, 8]`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := mustParse(t, tt.from), mustParse(t, tt.to)
+
+			patch, err := parser.PatchDiff(from, to)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.expected != "" && !parser.Equal(patch, mustParse(t, tt.expected)) {
+				data, _ := parser.Marshal(patch)
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			got, err := parser.ApplyPatch(from, patch)
+			if err != nil || !parser.Equal(got, to) {
+				data, _ := parser.Marshal(patch)
+				t.Errorf("Expected the patch %s to turn %s into %s, got %v, %v", data, tt.from, tt.to, got, err)
+			}
+		})
+	}
+
+	if _, err := parser.PatchDiff(nil, mustParse(t, `{}`)); err == nil {
+		t.Error("Expected an error for a missing document")
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import "strings"
+
+// wildcard is the path segment that matches any object key or array index.
+const wildcard = "*"
+
+// parsePath splits a dotted path such as "meta.items[0].id" or "items.*.id" into its segments.
+// Array indexes may be written either as a bracketed suffix or as a dotted segment, so "a[0]" and
+// "a.0" are equivalent. The empty path has no segments and denotes the root. Every other segment
+// is taken as written, empty ones included, which denote the empty key: "a." is the member "" of
+// a and "a..b" the member b below it. A top-level empty key is written as an empty bracketed
+// segment, "[]", since the empty path is the root.
+func parsePath(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	var segments []string
+
+	for _, part := range strings.Split(path, ".") {
+		// Split off bracketed indexes: a[0][1] becomes a, 0, 1
+		for {
+			open := strings.IndexByte(part, '[')
+			if open < 0 || !strings.HasSuffix(part, "]") {
+				segments = append(segments, part)
+				break
+			}
+
+			if open > 0 {
+				segments = append(segments, part[:open])
+			}
+
+			end := strings.IndexByte(part[open:], ']') + open
+			segments = append(segments, part[open+1:end])
+			part = part[end+1:]
+
+			if part == "" {
+				break
+			}
+		}
+	}
+
+	return segments
+}
+
+// matchPath reports whether the segments of a concrete location match pattern, whose wildcard
+// segments match any single key or index.
+func matchPath(location, pattern []string) bool {
+	if len(location) != len(pattern) {
+		return false
+	}
+
+	for i, segment := range pattern {
+		if segment != wildcard && segment != location[i] {
+			return false
+		}
+	}
+
+	return true
+}
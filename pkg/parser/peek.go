@@ -0,0 +1,86 @@
+package parser
+
+// ValueType identifies the kind of a JSON value.
+type ValueType string
+
+const (
+	TypeObject  ValueType = "object"
+	TypeArray   ValueType = "array"
+	TypeString  ValueType = "string"
+	TypeNumber  ValueType = "number"
+	TypeBoolean ValueType = "boolean"
+	TypeNull    ValueType = "null"
+)
+
+// PeekType reports the type of the top-level value of input by reading only its first token, so
+// that a dispatcher can branch before committing to a full parse. The leading token is fully
+// validated: a string must be terminated, a number well formed, and a literal spelled exactly
+// true, false or null. Nothing after it is examined, so the rest of the document may still be
+// malformed. Empty input and an invalid leading token are reported as a *ParseError.
+func PeekType(input string) (ValueType, error) {
+	token := NewLexer(input).NextToken()
+
+	var t ValueType
+
+	switch token.Type {
+	case TokenBraceOpen:
+		t = TypeObject
+	case TokenBracketOpen:
+		t = TypeArray
+	case TokenString:
+		t = TypeString
+	case TokenNumber:
+		t = TypeNumber
+	case TokenTrue, TokenFalse:
+		t = TypeBoolean
+	case TokenNull:
+		t = TypeNull
+	case TokenEOF:
+		return "", peekError(token, "unexpected end of input: empty document")
+	case TokenIllegal:
+		return "", peekError(token, "invalid token: "+token.Literal)
+	default:
+		return "", peekError(token, "unexpected token "+string(token.Type))
+	}
+
+	return t, nil
+}
+
+// peekError builds a ParseError at the position of token.
+func peekError(token Token, message string) *ParseError {
+	return &ParseError{Line: token.Line, Column: token.Column, Message: message}
+}
+
+// aTypeOf returns the type of v with its indefinite article, as in "an object", for error
+// messages.
+func aTypeOf(v Value) string {
+	switch t := typeOf(v); t {
+	case TypeObject, TypeArray:
+		return "an " + string(t)
+	case "":
+		return "an unsupported value"
+	default:
+		return "a " + string(t)
+	}
+}
+
+// typeOf returns the ValueType of a node built by this package, or the empty type for anything
+// else.
+func typeOf(v Value) ValueType {
+	switch v.(type) {
+	case *Object:
+		return TypeObject
+	case *Array:
+		return TypeArray
+	case *StringLiteral:
+		return TypeString
+	case *NumberLiteral:
+		return TypeNumber
+	case *Boolean:
+		return TypeBoolean
+	case *Null:
+		return TypeNull
+	default:
+		return ""
+	}
+}
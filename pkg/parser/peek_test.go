@@ -0,0 +1,51 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestPeekType(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    parser.ValueType
+		expectedErr string
+	}{
+		{`  {"a": [1, 2`, parser.TypeObject, ""},
+		{"\n[", parser.TypeArray, ""},
+		{`"text" garbage`, parser.TypeString, ""},
+		{`-12.5e3`, parser.TypeNumber, ""},
+		{`true`, parser.TypeBoolean, ""},
+		{`false`, parser.TypeBoolean, ""},
+		{`null`, parser.TypeNull, ""},
+		{``, "", "Line 1, Column 0: unexpected end of input: empty document"},
+		{`   `, "", "Line 1, Column 3: unexpected end of input: empty document"},
+		{`"unterminated`, "", "Line 1, Column 1: invalid token: Unterminated string"},
+		{`01`, "", "Line 1, Column 1: invalid token: Invalid number format: leading zeros not allowed"},
+		{`nul`, "", "Line 1, Column 1: invalid token: Invalid token"},
+		{`}`, "", "Line 1, Column 1: unexpected token }"},
+		{`<xml/>`, "", "Line 1, Column 1: invalid token: <"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parser.PeekType(tt.input)
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("Expected error %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
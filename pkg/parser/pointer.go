@@ -0,0 +1,296 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResolvePointer returns the value identified by a JSON Pointer (RFC 6901) in the tree rooted at
+// root. The empty pointer identifies root itself. It reports false when the pointer is malformed
+// or does not identify an existing value.
+func ResolvePointer(root Value, pointer string) (Value, bool) {
+	tokens, ok := parsePointer(pointer)
+	if !ok {
+		return nil, false
+	}
+
+	return resolveTokens(root, tokens)
+}
+
+// ResolveRelative resolves a Relative JSON Pointer, as defined by the Relative JSON Pointer draft
+// (draft-bhutton-relative-json-pointer-00), against the value identified by the JSON Pointer
+// basePointer in the tree rooted at root.
+//
+// The relative pointer starts with a non-negative integer giving how many levels to climb up the
+// ancestry of the base value, optionally followed by an index manipulation such as +1 or -2 that
+// moves to a sibling array element. It ends either with a JSON Pointer to resolve from there, as
+// in "1/foo", or with "#", as in "0#", which returns the key or index of the value reached rather
+// than the value itself: a *StringLiteral for an object member and a *NumberLiteral for an array
+// element. It reports false when either pointer is malformed or does not resolve.
+func ResolveRelative(root Value, basePointer, relative string) (Value, bool) {
+	base, ok := parsePointer(basePointer)
+	if !ok {
+		return nil, false
+	}
+
+	// The base value must exist, even when climbing to one of its ancestors
+	if _, ok := resolveTokens(root, base); !ok {
+		return nil, false
+	}
+
+	// Leading non-negative integer, without leading zeros
+	end := 0
+	for end < len(relative) && relative[end] >= '0' && relative[end] <= '9' {
+		end++
+	}
+
+	if end == 0 || (end > 1 && relative[0] == '0') {
+		return nil, false
+	}
+
+	up, err := strconv.Atoi(relative[:end])
+	if err != nil || up > len(base) {
+		return nil, false
+	}
+
+	tokens := base[:len(base)-up]
+	rest := relative[end:]
+
+	// Optional index manipulation
+	if rest != "" && (rest[0] == '+' || rest[0] == '-') {
+		tokens, rest, ok = shiftIndex(root, tokens, rest)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	if rest == "#" {
+		return pointerKey(root, tokens)
+	}
+
+	suffix, ok := parsePointer(rest)
+	if !ok {
+		return nil, false
+	}
+
+	return resolveTokens(root, append(tokens[:len(tokens):len(tokens)], suffix...))
+}
+
+// AppendPath appends v to the array identified by the JSON Pointer pointer in the tree rooted at
+// root, for building up arrays such as a list of log entries in place. A nil v is appended as
+// null. When nothing is found at the pointer and createMissing is set, a new array holding v is
+// stored there, along with empty objects for every missing key on the way to it; array elements
+// are never created. AppendPath returns an error when the pointer is malformed, when it does not
+// resolve and createMissing is not set, or when a value that is not an array is found at the
+// pointer or a scalar on the way to it. The tree is left unchanged on error.
+func AppendPath(root Value, pointer string, v Value, createMissing bool) error {
+	tokens, ok := parsePointer(pointer)
+	if !ok {
+		return fmt.Errorf("cannot append at %q: malformed pointer", pointer)
+	}
+
+	if v == nil {
+		v = NewNull()
+	}
+
+	current := root
+
+	for i, token := range tokens {
+		var next Value
+
+		switch val := current.(type) {
+		case *Object:
+			next = val.Pairs[token]
+		case *Array:
+			if index, ok := arrayIndex(token, len(val.Elements)); ok {
+				next = val.Elements[index]
+			}
+		default:
+			return fmt.Errorf("cannot append at %q: %q holds %s", pointer, prefixPointer(tokens[:i]), aTypeOf(current))
+		}
+
+		if next == nil {
+			return appendMissing(current, tokens[i:], pointer, v, createMissing)
+		}
+
+		current = next
+	}
+
+	arr, ok := current.(*Array)
+	if !ok {
+		return fmt.Errorf("cannot append at %q: it holds %s, not an array", pointer, aTypeOf(current))
+	}
+
+	arr.Elements = append(arr.Elements, v)
+
+	return nil
+}
+
+// appendMissing stores a new array holding v at the missing location that tokens identify
+// below the container parent, creating objects for the keys in between when createMissing is set.
+func appendMissing(parent Value, tokens []string, pointer string, v Value, createMissing bool) error {
+	obj, ok := parent.(*Object)
+	if !createMissing || !ok {
+		return fmt.Errorf("cannot append at %q: pointer does not resolve to a value", pointer)
+	}
+
+	arr := newArray(nil)
+	arr.Elements = append(arr.Elements, v)
+
+	var child Value = arr
+	for i := len(tokens) - 1; i > 0; i-- {
+		child = newObject().Set(tokens[i], child)
+	}
+
+	obj.Set(tokens[0], child)
+
+	return nil
+}
+
+// prefixPointer joins reference tokens back into a JSON Pointer.
+func prefixPointer(tokens []string) string {
+	var b strings.Builder
+
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escapePointerToken(token))
+	}
+
+	return b.String()
+}
+
+// shiftIndex applies the index manipulation at the start of rest to the last of tokens, which
+// must identify an array element. It returns the adjusted tokens and the remainder of rest.
+func shiftIndex(root Value, tokens []string, rest string) ([]string, string, bool) {
+	end := 1
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+
+	if end == 1 || (end > 2 && rest[1] == '0') || len(tokens) == 0 {
+		return nil, "", false
+	}
+
+	offset, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return nil, "", false
+	}
+
+	parent, ok := resolveTokens(root, tokens[:len(tokens)-1])
+	if !ok {
+		return nil, "", false
+	}
+
+	arr, ok := parent.(*Array)
+	if !ok {
+		return nil, "", false
+	}
+
+	index, ok := arrayIndex(tokens[len(tokens)-1], len(arr.Elements))
+	if !ok || index+offset < 0 || index+offset >= len(arr.Elements) {
+		return nil, "", false
+	}
+
+	shifted := append(tokens[:len(tokens)-1:len(tokens)-1], strconv.Itoa(index+offset))
+
+	return shifted, rest[end:], true
+}
+
+// pointerKey returns the key or index by which the value identified by tokens is reached.
+func pointerKey(root Value, tokens []string) (Value, bool) {
+	if len(tokens) == 0 {
+		return nil, false // The root has no key
+	}
+
+	if _, ok := resolveTokens(root, tokens); !ok {
+		return nil, false
+	}
+
+	last := tokens[len(tokens)-1]
+
+	parent, _ := resolveTokens(root, tokens[:len(tokens)-1])
+	if _, ok := parent.(*Array); ok {
+		return NewNumberLiteral(Token{Type: TokenNumber, Literal: last}), true
+	}
+
+	return &StringLiteral{Token: Token{Type: TokenString, Literal: last}, Value: last}, true
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, bool) {
+	if pointer == "" {
+		return nil, true
+	}
+
+	if pointer[0] != '/' {
+		return nil, false
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+
+		// Every ~ must start a ~0 or ~1 escape
+		if strings.Count(token, "~") != strings.Count(token, "~0")+strings.Count(token, "~1") {
+			return nil, false
+		}
+
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, true
+}
+
+// resolveTokens follows the reference tokens from root.
+func resolveTokens(root Value, tokens []string) (Value, bool) {
+	current := root
+
+	for _, token := range tokens {
+		switch val := current.(type) {
+		case *Object:
+			next, ok := val.Pairs[token]
+			if !ok {
+				return nil, false
+			}
+
+			current = next
+
+		case *Array:
+			index, ok := arrayIndex(token, len(val.Elements))
+			if !ok {
+				return nil, false
+			}
+
+			current = val.Elements[index]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return current, current != nil
+}
+
+// arrayIndex parses an array index reference token, which has no sign or leading zeros, and
+// checks it against the array length.
+func arrayIndex(token string, length int) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return 0, false
+		}
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index >= length {
+		return 0, false
+	}
+
+	return index, true
+}
@@ -0,0 +1,158 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestResolvePointer(t *testing.T) {
+	// Document from RFC 6901, section 5
+	value := mustParse(t, `{"foo": ["bar", "baz"], "": 0, "a/b": 1, "c%d": 2, "e^f": 3, "g|h": 4, "i\\j": 5, " ": 7, "m~n": 8}`)
+
+	tests := []struct {
+		pointer  string
+		expected string
+		ok       bool
+	}{
+		{"/foo/0", "bar", true},
+		{"/", "0", true},
+		{"/a~1b", "1", true},
+		{"/c%d", "2", true},
+		{"/ ", "7", true},
+		{"/m~0n", "8", true},
+		{"/foo/2", "", false},
+		{"/foo/-", "", false},
+		{"/foo/01", "", false},
+		{"/missing", "", false},
+		{"/m~2n", "", false},
+		{"foo", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			got, ok := parser.ResolvePointer(value, tt.pointer)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+
+			if ok && got.TokenLiteral() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got.TokenLiteral())
+			}
+		})
+	}
+
+	if got, ok := parser.ResolvePointer(value, ""); !ok || got != value {
+		t.Error("Expected the empty pointer to resolve to the root")
+	}
+}
+
+func TestResolveRelative(t *testing.T) {
+	// Examples from the Relative JSON Pointer draft, section 5.1
+	value := mustParse(t, `{"foo": ["bar", "baz", "biz"], "highly": {"nested": {"objects": true}}}`)
+
+	tests := []struct {
+		base     string
+		relative string
+		expected string
+		ok       bool
+	}{
+		{"/foo/1", "0", "baz", true},
+		{"/foo/1", "1/0", "bar", true},
+		{"/foo/1", "0-1", "bar", true},
+		{"/foo/1", "2/highly/nested/objects", "true", true},
+		{"/foo/1", "0#", "1", true},
+		{"/foo/1", "0+1#", "2", true},
+		{"/foo/1", "1#", "foo", true},
+		{"/highly/nested", "0/objects", "true", true},
+		{"/highly/nested", "1/nested/objects", "true", true},
+		{"/highly/nested", "2/foo/0", "bar", true},
+		{"/highly/nested", "0#", "nested", true},
+		{"/highly/nested", "1#", "highly", true},
+		{"/foo/1", "0+2", "", false},
+		{"/foo/1", "3", "", false},
+		{"/foo/1", "2#", "", false},
+		{"/foo/1", "01", "", false},
+		{"/highly/nested", "0+1", "", false},
+		{"/foo/1", "0foo", "", false},
+		{"/foo/9", "1/0", "", false},
+		{"/missing", "1#", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base+" "+tt.relative, func(t *testing.T) {
+			got, ok := parser.ResolveRelative(value, tt.base, tt.relative)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+
+			if ok && got.TokenLiteral() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got.TokenLiteral())
+			}
+		})
+	}
+
+	key, _ := parser.ResolveRelative(value, "/foo/1", "0#")
+	if _, ok := key.(*parser.NumberLiteral); !ok {
+		t.Errorf("Expected array index as *parser.NumberLiteral, got %T", key)
+	}
+}
+
+func TestAppendPath(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		pointer       string
+		createMissing bool
+		expected      string
+		err           string
+	}{
+		{"Existing array", `{"log": [{"n": 1}]}`, "/log", false, `{"log":[{"n":1},"entry"]}`, ""},
+		{"Root array", `[1]`, "", false, `[1,"entry"]`, ""},
+		{"Array in an array", `{"a": [[], [1]]}`, "/a/1", false, `{"a":[[],[1,"entry"]]}`, ""},
+		{"Created", `{"a": {}}`, "/a/log", true, `{"a":{"log":["entry"]}}`, ""},
+		{"Created with objects on the way", `{"a": [{}]}`, "/a/0/b/c~1d", true, `{"a":[{"b":{"c/d":["entry"]}}]}`, ""},
+		{"Missing", `{"a": {}}`, "/a/log", false, "", `cannot append at "/a/log": pointer does not resolve to a value`},
+		{"Missing element", `{"a": []}`, "/a/0", true, "", `cannot append at "/a/0": pointer does not resolve to a value`},
+		{"Not an array", `{"a": {"b": 1}}`, "/a", true, "", `cannot append at "/a": it holds an object, not an array`},
+		{"Scalar on the way", `{"a": {"b": 1}}`, "/a/b/c", true, "", `cannot append at "/a/b/c": "/a/b" holds a number`},
+		{"Malformed", `{"a": []}`, "a", true, "", `cannot append at "a": malformed pointer`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := mustParse(t, tt.input)
+			before, _ := parser.Marshal(value)
+
+			err := parser.AppendPath(value, tt.pointer, &parser.StringLiteral{Value: "entry"}, tt.createMissing)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+
+				if after, _ := parser.Marshal(value); string(after) != string(before) {
+					t.Errorf("Expected the tree to be unchanged on error, got %s", after)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got, _ := parser.Marshal(value); string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	value := mustParse(t, `{"log": []}`)
+	if err := parser.AppendPath(value, "/log", nil, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got, _ := parser.Marshal(value); string(got) != `{"log":[null]}` {
+		t.Errorf("Expected nil to be appended as null, got %s", got)
+	}
+}
@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"sort"
+	"strconv"
+)
+
+// source is the source text of a node parsed under PreserveFormatting, along with what the node
+// held when it was parsed, so that later modifications can be detected.
+type source struct {
+	// text is the source text of the node, from its first token to its last.
+	text string
+	// scalar is the Value of a string or number, the literal of a boolean, or the Bytes of a
+	// RawMessage.
+	scalar string
+	// pairs are the members of an object.
+	pairs map[string]Value
+	// elements are the elements of an array.
+	elements []Value
+}
+
+// recordSource records the source text of v, which spans from the token of v to the current
+// token, along with a snapshot of its contents.
+func (p *Parser) recordSource(v Value) {
+	src := &source{}
+
+	var start int
+
+	switch val := v.(type) {
+	case *Object:
+		start = val.Token.start
+		src.pairs = make(map[string]Value, len(val.Pairs))
+
+		for k, child := range val.Pairs {
+			src.pairs[k] = child
+		}
+	case *Array:
+		start = val.Token.start
+		src.elements = append([]Value(nil), val.Elements...)
+	case *StringLiteral:
+		start = val.Token.start
+		src.scalar = val.Value
+	case *NumberLiteral:
+		start = val.Token.start
+		src.scalar = val.Value
+	case *Boolean:
+		start = val.Token.start
+		src.scalar = strconv.FormatBool(val.Value)
+	case *Null:
+		start = val.Token.start
+	case *RawMessage:
+		start = val.Token.start
+		src.scalar = string(val.Bytes)
+	default:
+		return
+	}
+
+	src.text = p.lexer.text(start, p.currentToken.end)
+	v.(interface{ info() *nodeInfo }).info().source = src
+}
+
+// markPreserved adds v to preserved when v, and every value nested in it, still holds what it
+// was parsed with under PreserveFormatting, so that its source text can be written in place of
+// its serialization. It reports whether v was added.
+func markPreserved(v Value, preserved map[Value]bool) bool {
+	n, ok := v.(interface{ info() *nodeInfo })
+	if !ok || n.info().source == nil {
+		// Values below may still be unmodified
+		switch val := v.(type) {
+		case *Object:
+			for _, child := range val.Pairs {
+				markPreserved(child, preserved)
+			}
+		case *Array:
+			for _, elem := range val.Elements {
+				markPreserved(elem, preserved)
+			}
+		}
+
+		return false
+	}
+
+	src := n.info().source
+	unmodified := true
+
+	switch val := v.(type) {
+	case *Object:
+		unmodified = len(val.Pairs) == len(src.pairs)
+
+		// Every child is visited so that unmodified ones are marked below a modified object
+		for k, child := range val.Pairs {
+			if !markPreserved(child, preserved) || src.pairs[k] != child {
+				unmodified = false
+			}
+		}
+	case *Array:
+		unmodified = len(val.Elements) == len(src.elements)
+
+		for i, elem := range val.Elements {
+			if !markPreserved(elem, preserved) || i >= len(src.elements) || src.elements[i] != elem {
+				unmodified = false
+			}
+		}
+	case *StringLiteral:
+		unmodified = val.Value == src.scalar
+	case *NumberLiteral:
+		unmodified = val.Value == src.scalar
+	case *Boolean:
+		unmodified = strconv.FormatBool(val.Value) == src.scalar
+	case *RawMessage:
+		unmodified = string(val.Bytes) == src.scalar
+	}
+
+	if unmodified {
+		preserved[v] = true
+	}
+
+	return unmodified
+}
+
+// splice is a child of a container written under PreserveFormatting in place of the child the
+// container was parsed with, whose text spans from start to end in the source of the container.
+type splice struct {
+	start, end int
+	key        string
+	index      int
+	value      Value
+}
+
+// writeSpliced writes the container v as its source text with the text of each member or element
+// that was replaced or modified since parsing swapped for its serialization, so that the rest of
+// its formatting is kept. It reports false, having written nothing, when v gained or lost members
+// or elements, or its source cannot be matched with its children, and must be written in full.
+func writeSpliced(b *encodeBuffer, v Value, opts *MarshalOptions, depth int) (bool, error) {
+	if opts.Omit != nil || opts.annotate {
+		return false, nil // the source text would keep what they leave out or add
+	}
+
+	n, ok := v.(interface{ info() *nodeInfo })
+	if !ok || n.info().source == nil {
+		return false, nil
+	}
+
+	src := n.info().source
+
+	var splices []splice
+
+	add := func(original Value, s splice) bool {
+		o, ok := original.(interface{ info() *nodeInfo })
+		token := tokenOf(original)
+
+		if !ok || o.info().source == nil || token == nil {
+			return false
+		}
+
+		s.start = token.start - tokenOf(v).start
+		s.end = s.start + len(o.info().source.text)
+		splices = append(splices, s)
+
+		return s.start >= 0 && s.end <= len(src.text) && src.text[s.start:s.end] == o.info().source.text
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		if len(val.Pairs) != len(src.pairs) {
+			return false, nil
+		}
+
+		for k, child := range val.Pairs {
+			original, ok := src.pairs[k]
+			if !ok {
+				return false, nil
+			}
+
+			if (original != child || !opts.preserved[child]) && !add(original, splice{key: k, index: -1, value: child}) {
+				return false, nil
+			}
+		}
+
+	case *Array:
+		if len(val.Elements) != len(src.elements) {
+			return false, nil
+		}
+
+		for i, elem := range val.Elements {
+			if (src.elements[i] != elem || !opts.preserved[elem]) && !add(src.elements[i], splice{index: i, value: elem}) {
+				return false, nil
+			}
+		}
+
+	default:
+		return false, nil
+	}
+
+	sort.Slice(splices, func(i, j int) bool { return splices[i].start < splices[j].start })
+
+	for i := 1; i < len(splices); i++ {
+		if splices[i].start < splices[i-1].end {
+			return false, nil
+		}
+	}
+
+	written := 0
+
+	for _, s := range splices {
+		b.writeString(src.text[written:s.start])
+
+		if err := opts.writeChild(b, s.key, s.index, s.value, depth+1); err != nil {
+			return true, err
+		}
+
+		written = s.end
+	}
+
+	b.writeString(src.text[written:])
+
+	return true, nil
+}
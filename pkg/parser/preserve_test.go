@@ -0,0 +1,149 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestPreserveFormatting(t *testing.T) {
+	const input = `{
+  "name":   "Ada",   // the user
+  "langs": [ "en" ,"fr" ],
+  "address": {"city" : "London", "zip": 12345.0}
+}`
+
+	preserve := parser.MarshalOptions{PreserveFormatting: true}
+
+	parse := func(t *testing.T) *parser.Object {
+		t.Helper()
+
+		value, err := parser.Parse(input, parser.WithRetainedComments(), parser.WithPreserveFormatting())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return value.(*parser.Object)
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(o *parser.Object)
+		expected string
+	}{
+		{
+			name:     "Unmodified",
+			modify:   func(*parser.Object) {},
+			expected: input,
+		},
+		{
+			name: "Changed scalar",
+			modify: func(o *parser.Object) {
+				o.Pairs["address"].(*parser.Object).Pairs["city"].(*parser.StringLiteral).Value = "Paris"
+			},
+			expected: strings.Replace(input, `"London"`, `"Paris"`, 1),
+		},
+		{
+			name: "Appended element",
+			modify: func(o *parser.Object) {
+				langs := o.Pairs["langs"].(*parser.Array)
+				langs.Elements = append(langs.Elements, &parser.StringLiteral{Value: "de"})
+			},
+			expected: strings.Replace(input, `[ "en" ,"fr" ]`, `["en","fr","de"]`, 1),
+		},
+		{
+			name: "Replaced member",
+			modify: func(o *parser.Object) {
+				o.Set("name", &parser.StringLiteral{Value: "Grace"})
+				o.Pairs["langs"].(*parser.Array).Elements[1] = parser.NewNull()
+			},
+			expected: strings.Replace(strings.Replace(input, `"Ada"`, `"Grace"`, 1), `"fr"`, `null`, 1),
+		},
+		{
+			name: "Added member",
+			modify: func(o *parser.Object) {
+				o.Set("id", parser.NewNull())
+			},
+			expected: `{"address":{"city" : "London", "zip": 12345.0},"id":null,"langs":[ "en" ,"fr" ],"name":"Ada"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := parse(t)
+			tt.modify(o)
+
+			out, err := parser.MarshalWith(o, preserve)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(out) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, out)
+			}
+		})
+	}
+
+	t.Run("Reader", func(t *testing.T) {
+		value, err := parser.ParseReader(iotest.OneByteReader(strings.NewReader(input)),
+			parser.WithRetainedComments(), parser.WithPreserveFormatting())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		out, err := parser.MarshalWith(value, preserve)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if string(out) != input {
+			t.Errorf("Expected %s, got %s", input, out)
+		}
+	})
+
+	t.Run("Omit", func(t *testing.T) {
+		o := parse(t)
+		o.Pairs["address"].(*parser.Object).Pairs["city"].(*parser.StringLiteral).Value = "Paris"
+
+		opts := preserve
+		opts.Omit = func(path string, _ parser.Value) bool { return path == "/name" }
+
+		out, err := parser.MarshalWith(o, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The containers holding a modified value are written in full, so that Omit applies
+		const expected = `{"address":{"city":"Paris","zip":12345.0},"langs":[ "en" ,"fr" ]}`
+		if string(out) != expected {
+			t.Errorf("Expected %s, got %s", expected, out)
+		}
+	})
+
+	t.Run("Not requested by the marshaler", func(t *testing.T) {
+		out, err := parser.Marshal(parse(t))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		const expected = `{"address":{"city":"London","zip":12345.0},"langs":["en","fr"],"name":"Ada"}`
+		if string(out) != expected {
+			t.Errorf("Expected %s, got %s", expected, out)
+		}
+	})
+
+	t.Run("Not recorded by the parser", func(t *testing.T) {
+		value := mustParse(t, `[1,  2]`)
+
+		out, err := parser.MarshalWith(value, preserve)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if string(out) != `[1,2]` {
+			t.Errorf("Expected [1,2], got %s", out)
+		}
+	})
+}
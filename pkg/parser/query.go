@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QueryOptions configures ToQueryWith and FromQueryWith. The zero value gives ToQuery and
+// FromQuery.
+type QueryOptions struct {
+	// Flatten accepts nested objects and arrays, naming their contents with dotted keys, so that
+	// {"filter": {"tag": "a"}} becomes filter.tag=a and [{"id": 1}] under items becomes
+	// items.0.id=1. The dots are not escaped, so a key that holds one reads the same as a nested
+	// key, and FromQueryWith nests both. Arrays of scalars are still written as repeated
+	// parameters under the key of the array, but once an array holds an object or an array, every
+	// element is named by its index, so [7, {"id": 1}] under items becomes items.0=7&items.1.id=1
+	// rather than a parameter that is also the parent of others.
+	Flatten bool
+}
+
+// ToQuery encodes the object o as URL query parameters, such as to call an API that takes its
+// arguments in the query string rather than in a body. Every member must be a scalar or an array
+// of scalars: strings are written as they are, numbers with their literal, booleans as true or
+// false and null as the empty string, and an array becomes one parameter per element, repeated
+// under its key in order, so {"tag": ["a", "b"]} encodes as tag=a&tag=b. An empty array writes
+// no parameter at all. A nested object, or an array holding one or an array, is reported as an
+// error naming its key; use ToQueryWith with Flatten to encode them with dotted keys instead. A
+// nil object encodes as no parameters.
+//
+// The types of the values are lost: 1, "1" and [1] all encode as n=1. Use FromQuery to read
+// parameters back into an object of strings.
+func (o *Object) ToQuery() (url.Values, error) {
+	return o.ToQueryWith(QueryOptions{})
+}
+
+// ToQueryWith is like ToQuery, configured by opts.
+func (o *Object) ToQueryWith(opts QueryOptions) (url.Values, error) {
+	values := url.Values{}
+	if o == nil {
+		return values, nil
+	}
+
+	for _, k := range o.SortedKeys() {
+		if err := addQuery(values, k, o.Pairs[k], opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// addQuery adds the parameters encoding v under key to values.
+func addQuery(values url.Values, key string, v Value, opts QueryOptions) error {
+	switch val := v.(type) {
+	case *Object:
+		if !opts.Flatten {
+			return fmt.Errorf("cannot encode %q as a query parameter: it holds a nested object", key)
+		}
+
+		for _, k := range val.SortedKeys() {
+			if err := addQuery(values, key+"."+k, val.Pairs[k], opts); err != nil {
+				return err
+			}
+		}
+
+	case *Array:
+		indexed := false
+
+		for i, elem := range val.Elements {
+			switch elem.(type) {
+			case *Object, *Array:
+				if !opts.Flatten {
+					return fmt.Errorf("cannot encode %q as a query parameter: element %d is a nested %s", key, i, typeOf(elem))
+				}
+
+				indexed = true
+			}
+		}
+
+		for i, elem := range val.Elements {
+			elemKey := key
+			if indexed {
+				elemKey = key + "." + strconv.Itoa(i)
+			}
+
+			if err := addQuery(values, elemKey, elem, opts); err != nil {
+				return err
+			}
+		}
+
+	case *StringLiteral:
+		values.Add(key, val.Value)
+
+	case *NumberLiteral:
+		literal := val.Value
+		if literal == "" {
+			literal = strconv.FormatFloat(val.Float, 'g', -1, 64)
+		}
+
+		values.Add(key, literal)
+
+	case *Boolean:
+		values.Add(key, strconv.FormatBool(val.Value))
+
+	case *Null, nil:
+		values.Add(key, "")
+
+	default:
+		return fmt.Errorf("cannot encode %q as a query parameter: unsupported value %T", key, v)
+	}
+
+	return nil
+}
+
+// FromQuery decodes URL query parameters into an object, the inverse of ToQuery: a parameter
+// given once becomes a string member, and one repeated becomes an array of its strings, in order.
+// A parameter given with no value, as in ?debug, becomes the empty string. Every value is read
+// as a string, since the query string does not record whether n=1 was a number; use AsNumber
+// or ParseTyped to recover the types.
+func FromQuery(values url.Values) *Object {
+	o, _ := FromQueryWith(values, QueryOptions{}) // cannot fail without Flatten
+
+	return o
+}
+
+// FromQueryWith is like FromQuery, configured by opts. Under Flatten, dotted keys are split into
+// nested objects, so filter.tag=a decodes as {"filter": {"tag": "a"}}; indexes become object keys
+// rather than array elements, since an index cannot be told apart from a key made of digits. It
+// returns an error when a key is both a parameter and the parent of others, as with a=1&a.b=2.
+func FromQueryWith(values url.Values, opts QueryOptions) (*Object, error) {
+	o := newObject()
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys) // for the same error every time
+
+	for _, k := range keys {
+		var v Value
+
+		if params := values[k]; len(params) == 1 {
+			v = &StringLiteral{Token: Token{Type: TokenString, Literal: params[0]}, Value: params[0]}
+		} else {
+			arr := newArray(nil)
+			for _, param := range params {
+				arr.Elements = append(arr.Elements, &StringLiteral{Token: Token{Type: TokenString, Literal: param}, Value: param})
+			}
+
+			v = arr
+		}
+
+		parent, segments := o, []string{k}
+		if opts.Flatten {
+			segments = strings.Split(k, ".")
+		}
+
+		for i, segment := range segments[:len(segments)-1] {
+			next, ok := parent.Pairs[segment]
+			if !ok {
+				next = newObject()
+				parent.Set(segment, next)
+			}
+
+			if parent, ok = next.(*Object); !ok {
+				return nil, fmt.Errorf("cannot decode query parameter %q: %q is also a parameter", k, strings.Join(segments[:i+1], "."))
+			}
+		}
+
+		key := segments[len(segments)-1]
+		parent.Set(key, v)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,107 @@
+package parser_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestToQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		flatten  bool
+		expected string
+	}{
+		{"Scalars", `{"q": "a b&c", "page": 2, "ratio": 1.50, "debug": true, "cursor": null}`, false,
+			"cursor=&debug=true&page=2&q=a+b%26c&ratio=1.50"},
+		{"Repeated", `{"tag": ["go", "json", 3], "none": []}`, false, "tag=go&tag=json&tag=3"},
+		{"Empty", `{}`, false, ""},
+		{"Flattened", `{"filter": {"tag": "a", "range": {"min": 1}}, "items": [{"id": 1}, 7, [2, 3]], "n": 1}`, true,
+			"filter.range.min=1&filter.tag=a&items.0.id=1&items.1=7&items.2=2&items.2=3&n=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustParse(t, tt.input).(*parser.Object).ToQueryWith(parser.QueryOptions{Flatten: tt.flatten})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got.Encode() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got.Encode())
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Nested object", `{"a": 1, "filter": {"tag": "a"}}`, `cannot encode "filter" as a query parameter: it holds a nested object`},
+		{"Object in array", `{"items": [1, {"id": 1}]}`, `cannot encode "items" as a query parameter: element 1 is a nested object`},
+		{"Array in array", `{"m": [[1]]}`, `cannot encode "m" as a query parameter: element 0 is a nested array`},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := mustParse(t, tt.input).(*parser.Object).ToQuery(); err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	var nilObject *parser.Object
+	if got, err := nilObject.ToQuery(); err != nil || len(got) != 0 {
+		t.Errorf("Expected no parameters for a nil object, got %v, %v", got, err)
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	values, _ := url.ParseQuery("q=a+b&tag=go&tag=json&debug&filter.tag=x&filter.range.min=1")
+
+	expected := `{"q": "a b", "tag": ["go", "json"], "debug": "", "filter.tag": "x", "filter.range.min": "1"}`
+	if got := parser.FromQuery(values); !parser.Equal(got, mustParse(t, expected)) {
+		t.Errorf("Expected %s, got %v", expected, got)
+	}
+
+	got, err := parser.FromQueryWith(values, parser.QueryOptions{Flatten: true})
+	expected = `{"q": "a b", "tag": ["go", "json"], "debug": "", "filter": {"tag": "x", "range": {"min": "1"}}}`
+
+	if err != nil || !parser.Equal(got, mustParse(t, expected)) {
+		t.Errorf("Expected %s, got %v, %v", expected, got, err)
+	}
+
+	// Round trip, with the values read back as strings
+	original := mustParse(t, `{"filter": {"tag": "a"}, "ids": ["1", "2"], "name": "x"}`).(*parser.Object)
+
+	query, err := original.ToQueryWith(parser.QueryOptions{Flatten: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if back, err := parser.FromQueryWith(query, parser.QueryOptions{Flatten: true}); err != nil || !parser.Equal(back, original) {
+		t.Errorf("Expected the round trip to give back the object, got %v, %v", back, err)
+	}
+
+	// Arrays holding containers decode as objects keyed by index
+	mixed := mustParse(t, `{"items": [7, {"id": 1}, [2, 3]]}`).(*parser.Object)
+
+	query, err = mixed.ToQueryWith(parser.QueryOptions{Flatten: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = `{"items": {"0": "7", "1": {"id": "1"}, "2": ["2", "3"]}}`
+	if back, err := parser.FromQueryWith(query, parser.QueryOptions{Flatten: true}); err != nil || !parser.Equal(back, mustParse(t, expected)) {
+		t.Errorf("Expected %s, got %v, %v", expected, back, err)
+	}
+
+	conflict, _ := url.ParseQuery("a=1&a.b=2")
+	if _, err := parser.FromQueryWith(conflict, parser.QueryOptions{Flatten: true}); err == nil ||
+		err.Error() != `cannot decode query parameter "a.b": "a" is also a parameter` {
+		t.Errorf("Expected a conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestRawMessage(t *testing.T) {
+	t.Run("Marshal", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			raw      string
+			expected string
+			err      string
+		}{
+			{"Object", `{"b": [1, 2]}`, `{"a":{"b":[1,2]}}`, ""},
+			{"Scalar", ` 42 `, `{"a":42}`, ""},
+			{"Escaped", `["<é>"]`, `{"a":["\u003cé\u003e"]}`, ""},
+			{"Malformed", `{"b": }`, "", "invalid raw JSON"},
+			{"Trailing value", `1 2`, "", "invalid raw JSON"},
+			{"Empty", ``, "", "invalid raw JSON"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				obj := mustParse(t, `{}`).(*parser.Object)
+				obj.Pairs["a"] = &parser.RawMessage{Bytes: []byte(tt.raw)}
+
+				out, err := parser.Marshal(obj)
+				if tt.err != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.err) {
+						t.Fatalf("Expected an error containing %q, got %v", tt.err, err)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if string(out) != tt.expected {
+					t.Errorf("Expected %s, got %s", tt.expected, out)
+				}
+			})
+		}
+	})
+
+	t.Run("Equal", func(t *testing.T) {
+		a := &parser.RawMessage{Bytes: []byte(`[1]`)}
+
+		if !parser.Equal(a, &parser.RawMessage{Bytes: []byte(`[1]`)}) {
+			t.Error("Expected equal bytes to be equal")
+		}
+
+		if parser.Equal(a, &parser.RawMessage{Bytes: []byte(`[ 1 ]`)}) {
+			t.Error("Expected differently written bytes to differ")
+		}
+	})
+}
+
+func TestRawPaths(t *testing.T) {
+	input := `{"id": 7, "payload": {"x": [1,  2], "y": "é"}, "items": [true, {"k" : null}, 3]}`
+
+	tests := []struct {
+		name     string
+		pointer  string
+		expected string
+	}{
+		{"Object member", "/payload", `{"x": [1,  2], "y": "é"}`},
+		{"Array element", "/items/1", `{"k" : null}`},
+		{"Scalar", "/id", `7`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parser.Parse(input, parser.WithRawPaths(tt.pointer))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			v, err := parser.Extract(root, tt.pointer)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			raw, ok := v.(*parser.RawMessage)
+			if !ok {
+				t.Fatalf("Expected a *RawMessage, got %T", v)
+			}
+
+			if string(raw.Bytes) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, raw.Bytes)
+			}
+
+			// The rest of the tree is built as usual
+			if _, ok := root.(*parser.Object).Pairs["items"].(*parser.Array); !ok && tt.pointer != "/items/1" {
+				t.Errorf("Expected items to be parsed, got %T", root.(*parser.Object).Pairs["items"])
+			}
+		})
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		root, err := parser.Parse(input, parser.WithRawPaths("/payload"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		out, err := parser.Marshal(root)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !strings.Contains(string(out), `"payload":{"x":[1,2],"y":"é"}`) {
+			t.Errorf("Expected the payload compacted, got %s", out)
+		}
+
+		out, err = parser.MarshalWith(root, parser.MarshalOptions{Indent: " ", ASCIIOnly: true})
+		if expected := "\"payload\":{\n  \"x\":[\n   1,\n   2\n  ],\n  \"y\":\"\\u00e9\"\n }"; err != nil || !strings.Contains(string(out), expected) {
+			t.Errorf("Expected the payload written with the options, got %s, %v", out, err)
+		}
+	})
+
+	t.Run("Parser options", func(t *testing.T) {
+		// The raw value is checked again with the options it was accepted with
+		root, err := parser.Parse(`{"payload": {"x": /* note */ 01}}`, parser.WithRawPaths("/payload"), parser.WithComments(),
+			parser.WithLeadingZeros())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if out, err := parser.Marshal(root); err != nil || string(out) != `{"payload":{"x":1}}` {
+			t.Errorf("Expected the raw value to marshal, got %s, %v", out, err)
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := parser.Parse(`{"payload": {"x": }}`, parser.WithRawPaths("/payload"))
+		if err == nil {
+			t.Fatal("Expected a malformed raw value to be a parse error")
+		}
+	})
+}
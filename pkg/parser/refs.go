@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// refKey is the member that makes an object a reference.
+const refKey = "$ref"
+
+// ResolveRefs returns a copy of the tree rooted at root in which every local reference, an object
+// with a "$ref" string member such as {"$ref": "#/definitions/user"}, is replaced by a copy of the
+// value its URI fragment points to in root, as found in JSON Schema and OpenAPI documents. The
+// fragment is a JSON Pointer, percent-decoded first, and "#" alone refers to root itself. Like
+// OpenAPI, the other members of a reference object are dropped. References that are not local,
+// such as "other.json#/a", are kept as they are. root itself is left untouched.
+//
+// References found inside a referenced value are resolved too, so chains of references are
+// followed. Every occurrence gets its own copy, so the result never shares nodes and can be
+// modified freely, at the cost of duplicating values referenced many times. A reference that is
+// reached again while its own target is being expanded, such as a schema that refers to itself
+// or two definitions that refer to each other, could only be expanded forever and makes
+// ResolveRefs fail with an error naming the chain of references, as does a reference that does
+// not resolve. Errors give the location the reference would have in the resolved tree.
+func ResolveRefs(root Value) (Value, error) {
+	return resolveRefs(root, root, "", nil)
+}
+
+// resolveRefs resolves the references in v, found at the JSON Pointer location in root, while the
+// references in expanding are being expanded.
+func resolveRefs(root, v Value, location string, expanding []string) (Value, error) {
+	switch val := v.(type) {
+	case *Object:
+		if ref, ok := localRef(val); ok {
+			return resolveRef(root, ref, location, expanding)
+		}
+
+		result := &Object{Token: val.Token, Pairs: make(map[string]Value, len(val.Pairs))}
+		result.nodeInfo = cloneInfo(&val.nodeInfo)
+
+		// Sorted, so that the error for a document with several bad references is stable
+		for _, k := range val.SortedKeys() {
+			resolved, err := resolveRefs(root, val.Pairs[k], location+"/"+escapePointerToken(k), expanding)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Pairs[k] = resolved
+		}
+
+		return result, nil
+
+	case *Array:
+		result := &Array{Token: val.Token, Elements: make([]Value, 0, len(val.Elements))}
+		result.nodeInfo = cloneInfo(&val.nodeInfo)
+
+		for i, elem := range val.Elements {
+			resolved, err := resolveRefs(root, elem, fmt.Sprintf("%s/%d", location, i), expanding)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Elements = append(result.Elements, resolved)
+		}
+
+		return result, nil
+
+	default:
+		return clone(v), nil
+	}
+}
+
+// resolveRef returns the resolved copy of the target of ref, found at location.
+func resolveRef(root Value, ref, location string, expanding []string) (Value, error) {
+	if slices.Contains(expanding, ref) {
+		chain := strings.Join(append(slices.Clip(expanding), ref), " -> ")
+		return nil, fmt.Errorf("circular reference at %q: %s", location, chain)
+	}
+
+	pointer, err := url.PathUnescape(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("reference %q at %q: %w", ref, location, err)
+	}
+
+	target, ok := ResolvePointer(root, pointer)
+	if !ok {
+		return nil, fmt.Errorf("reference %q at %q does not resolve to a value", ref, location)
+	}
+
+	return resolveRefs(root, target, location, append(slices.Clip(expanding), ref))
+}
+
+// localRef returns the reference held by o when o is a reference object with a local reference.
+func localRef(o *Object) (string, bool) {
+	ref, ok := o.Pairs[refKey].(*StringLiteral)
+	if !ok || !strings.HasPrefix(ref.Value, "#") {
+		return "", false
+	}
+
+	return ref.Value, true
+}
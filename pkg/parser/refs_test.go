@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestResolveRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			"Local reference",
+			`{"defs": {"id": {"type": "integer"}}, "props": {"a": {"$ref": "#/defs/id"}, "b": {"$ref": "#/defs/id", "description": "x"}}}`,
+			`{"defs":{"id":{"type":"integer"}},"props":{"a":{"type":"integer"},"b":{"type":"integer"}}}`,
+			"",
+		},
+		{
+			"Chain",
+			`{"a": {"$ref": "#/b"}, "b": {"$ref": "#/c"}, "c": [1]}`,
+			`{"a":[1],"b":[1],"c":[1]}`,
+			"",
+		},
+		{
+			"Nested references",
+			`{"defs": {"list": {"items": {"$ref": "#/defs/item"}}, "item": "x"}, "root": {"$ref": "#/defs/list"}}`,
+			`{"defs":{"item":"x","list":{"items":"x"}},"root":{"items":"x"}}`,
+			"",
+		},
+		{"Escaped pointer", `{"a b": {"c/d": 1}, "r": [{"$ref": "#/a%20b/c~1d"}]}`, `{"a b":{"c/d":1},"r":[1]}`, ""},
+		{"Remote reference kept", `{"r": {"$ref": "other.json#/a"}}`, `{"r":{"$ref":"other.json#/a"}}`, ""},
+		{"Non-string ref kept", `{"props": {"$ref": {"type": "string"}}}`, `{"props":{"$ref":{"type":"string"}}}`, ""},
+		{"Self reference", `{"node": {"next": {"$ref": "#/node"}}}`, "", `circular reference at "/node/next/next": #/node -> #/node`},
+		{"Mutual references", `{"a": {"$ref": "#/b"}, "b": {"$ref": "#/a"}}`, "", `circular reference at "/a": #/b -> #/a -> #/b`},
+		{"Root reference", `{"a": {"$ref": "#"}}`, "", "circular reference"},
+		{"Unresolved", `{"a": {"$ref": "#/missing"}}`, "", `reference "#/missing" at "/a" does not resolve to a value`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := mustParse(t, tt.input)
+			original, _ := parser.Marshal(root)
+
+			resolved, err := parser.ResolveRefs(root)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected an error containing %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got, _ := parser.Marshal(resolved); string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if after, _ := parser.Marshal(root); string(after) != string(original) {
+				t.Errorf("Expected the input to be untouched, got %s", after)
+			}
+
+			if err := parser.CheckAcyclic(resolved); err != nil {
+				t.Errorf("Expected no shared nodes, got %v", err)
+			}
+		})
+	}
+}
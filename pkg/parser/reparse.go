@@ -0,0 +1,275 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReparseRange updates the tree returned by the last successful ParseJSON of p after an edit of
+// its input, for editors that must keep the tree of a large document current as it is typed in.
+// newInput is the complete edited document, in which the bytes between the offsets start and end
+// of the previous input were replaced. Only the innermost container whose brackets enclose the
+// edit is parsed again from newInput and spliced into the tree in place of the old one; the rest
+// of the tree is kept, with its positions updated. ReparseRange returns the root of the updated
+// tree, which is the same root unless the edit reached it.
+//
+// When the edit touches the brackets of the outermost container, or the parsed container does
+// not end where the edit says it should, as when brackets are added or removed, the whole of
+// newInput is parsed again instead, and the first error of that parse is returned, leaving the
+// tree and input of p as they were. Otherwise, the result is the tree that parsing newInput would
+// produce, and newInput becomes the input of the next call. OnValue is only called for the values
+// that are parsed again.
+//
+// ReparseRange relies on the source spans recorded under PreserveFormatting and fails without
+// them, as it does for documents parsed with ParseFields or NormalizeKeysNFC. Containers that
+// record no source, such as the arrays built by DuplicateKeysToArray, are parsed again as part of
+// their parent.
+func (p *Parser) ReparseRange(newInput string, start, end int) (Value, error) {
+	if !p.PreserveFormatting || p.root == nil || p.root.(interface{ info() *nodeInfo }).info().source == nil {
+		return nil, errors.New("ReparseRange requires a document parsed with PreserveFormatting")
+	}
+
+	if start < 0 || start > end || end > len(p.source) {
+		return nil, errors.New("ReparseRange: edit range out of bounds")
+	}
+
+	delta := len(newInput) - len(p.source)
+
+	target, ancestors := findReparseTarget(p.root, start, end)
+	if target != nil {
+		if value := p.reparseContainer(newInput, target, ancestors, delta); value != nil {
+			shiftPositions(p.root, target, p.source, newInput, end, delta)
+			p.splice(newInput, target, value, ancestors, delta)
+			p.source = newInput
+
+			return p.root, nil
+		}
+	}
+
+	// Parse the whole document again
+	full := NewParser(NewLexer(newInput))
+	full.ParserOptions = p.ParserOptions
+
+	value, err := full.ParseJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	p.root, p.source = full.root, full.source
+
+	return value, nil
+}
+
+// reparseStep is a step of the descent from the root to the container to parse again: the
+// container left and the key or index followed.
+type reparseStep struct {
+	container Value
+	key       string
+	index     int
+}
+
+// findReparseTarget returns the innermost container below root whose brackets strictly enclose
+// the bytes between start and end, along with the steps leading to it, or nil when the root
+// itself does not enclose them.
+func findReparseTarget(root Value, start, end int) (Value, []reparseStep) {
+	if !encloses(root, start, end) {
+		return nil, nil
+	}
+
+	target := root
+
+	var steps []reparseStep
+
+	for {
+		var next reparseStep
+
+		found := false
+
+		switch val := target.(type) {
+		case *Object:
+			for k, child := range val.Pairs {
+				if encloses(child, start, end) {
+					next, found = reparseStep{container: target, key: k, index: -1}, true
+					target = child
+
+					break
+				}
+			}
+		case *Array:
+			for i, elem := range val.Elements {
+				if encloses(elem, start, end) {
+					next, found = reparseStep{container: target, index: i}, true
+					target = elem
+
+					break
+				}
+			}
+		}
+
+		if !found {
+			return target, steps
+		}
+
+		steps = append(steps, next)
+	}
+}
+
+// encloses reports whether v is a container with a recorded source whose brackets lie strictly
+// outside the bytes between start and end.
+func encloses(v Value, start, end int) bool {
+	switch v.(type) {
+	case *Object, *Array:
+	default:
+		return false
+	}
+
+	src := v.(interface{ info() *nodeInfo }).info().source
+	if src == nil {
+		return false
+	}
+
+	first := tokenOf(v).start
+
+	return first < start && end < first+len(src.text)
+}
+
+// reparseContainer parses the edited text of target from newInput, at the depth and path of
+// target in the tree. It returns nil when the text does not parse as a single container ending
+// where the edit says it should.
+func (p *Parser) reparseContainer(newInput string, target Value, steps []reparseStep, delta int) Value {
+	first := tokenOf(target).start
+	last := first + len(target.(interface{ info() *nodeInfo }).info().source.text) + delta
+
+	sub := NewParser(newLexerAt(newInput, first))
+	sub.ParserOptions = p.ParserOptions
+	sub.depth = len(steps)
+
+	for _, step := range steps {
+		sub.path = append(sub.path, pathElement{key: step.key, index: step.index})
+	}
+
+	sub.start()
+
+	if sub.currentToken.Type != tokenOf(target).Type || sub.currentToken.start != first {
+		return nil
+	}
+
+	value := sub.parseContainer()
+	if sub.failed() || sub.currentToken.end != last {
+		return nil
+	}
+
+	return value
+}
+
+// splice puts value in the place of target in the tree, and updates the recorded source of the
+// ancestors of target, whose text changed with the edit.
+func (p *Parser) splice(newInput string, target, value Value, steps []reparseStep, delta int) {
+	if len(steps) == 0 {
+		p.root = value
+		return
+	}
+
+	for i, step := range steps {
+		src := step.container.(interface{ info() *nodeInfo }).info().source
+		first := tokenOf(step.container).start
+		src.text = newInput[first : first+len(src.text)+delta]
+
+		if i < len(steps)-1 {
+			continue
+		}
+
+		// The parent of target holds the new value, in its snapshot too
+		switch parent := step.container.(type) {
+		case *Object:
+			parent.Pairs[step.key] = value
+			src.pairs[step.key] = value
+		case *Array:
+			parent.Elements[step.index] = value
+			src.elements[step.index] = value
+		}
+	}
+}
+
+// shiftPositions moves the tokens of the nodes of the tree rooted at v that start after the edit
+// ending at the offset end of oldInput to their place in newInput, skipping the subtree of target,
+// which is parsed again. Their offsets move by delta, their lines by the number of lines added, and
+// the columns of those on the line where the edit ends by the change in its length.
+func shiftPositions(v, target Value, oldInput, newInput string, end, delta int) {
+	oldLine, oldColumn := positionAt(oldInput, end)
+	newLine, newColumn := positionAt(newInput, end+delta)
+
+	var shift func(v Value)
+
+	shift = func(v Value) {
+		if v == target {
+			return
+		}
+
+		if token := tokenOf(v); token != nil && token.start >= end {
+			if token.Line == oldLine {
+				token.Column += newColumn - oldColumn
+			}
+
+			token.Line += newLine - oldLine
+			token.start += delta
+			token.end += delta
+		}
+
+		switch val := v.(type) {
+		case *Object:
+			for _, child := range val.Pairs {
+				shift(child)
+			}
+		case *Array:
+			for _, elem := range val.Elements {
+				shift(elem)
+			}
+		}
+	}
+
+	shift(v)
+}
+
+// positionAt returns the line and column of the character at offset in input, counted like the
+// lexer counts them.
+func positionAt(input string, offset int) (int, int) {
+	return advancePosition(input, 0, offset, 1, 1)
+}
+
+// advancePosition returns the line and column of the character at offset to in input, given
+// those of the character at offset from, so that positions further and further into the input
+// can be found without counting from its start every time.
+func advancePosition(input string, from, to, line, column int) (int, int) {
+	skipped := input[from:to]
+
+	newlines := strings.Count(skipped, "\n")
+	if newlines == 0 {
+		return line, column + utf8.RuneCountInString(skipped)
+	}
+
+	lineStart := strings.LastIndexByte(skipped, '\n') + 1
+
+	return line + newlines, utf8.RuneCountInString(skipped[lineStart:]) + 1
+}
+
+// tokenOf returns the token of a node built by this package, or nil for anything else.
+func tokenOf(v Value) *Token {
+	switch val := v.(type) {
+	case *Object:
+		return &val.Token
+	case *Array:
+		return &val.Token
+	case *StringLiteral:
+		return &val.Token
+	case *NumberLiteral:
+		return &val.Token
+	case *Boolean:
+		return &val.Token
+	case *Null:
+		return &val.Token
+	default:
+		return nil
+	}
+}
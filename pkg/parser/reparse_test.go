@@ -0,0 +1,206 @@
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestReparseRange(t *testing.T) {
+	const input = `{
+  "name": "Ada",
+  "address": {"city": "London", "zip": 12345},
+  "langs": ["en", "fr"],
+  "active": true
+}`
+
+	type edit struct {
+		old, replacement string
+	}
+
+	tests := []struct {
+		name  string
+		edits []edit
+		// sameRoot reports whether the root is kept, with only a nested container parsed again.
+		sameRoot bool
+	}{
+		{
+			name:     "Changed scalar",
+			sameRoot: true,
+			edits:    []edit{{old: `"London"`, replacement: `"Paris"`}},
+		},
+		{
+			name:     "Inserted member",
+			sameRoot: true,
+			edits:    []edit{{old: `"zip": 12345`, replacement: "\"zip\": 12345,\n    \"country\": \"UK\""}},
+		},
+		{
+			name:     "Inserted element",
+			sameRoot: true,
+			edits:    []edit{{old: `"fr"`, replacement: `"fr", "de"`}},
+		},
+		{
+			name:  "Edited root member",
+			edits: []edit{{old: `"Ada"`, replacement: `"Grace Hopper"`}},
+		},
+		{
+			name:     "Added brackets",
+			sameRoot: true,
+			edits:    []edit{{old: `"London"`, replacement: `["London"]`}},
+		},
+		{
+			name:  "Removed closing brace",
+			edits: []edit{{old: `12345}`, replacement: `12345, "street": {"no": 12}}`}},
+		},
+		{
+			name:  "Edited root brackets",
+			edits: []edit{{old: "{\n  \"name\"", replacement: "{\"id\": 1,\n  \"name\""}},
+		},
+		{
+			name:     "Consecutive edits",
+			sameRoot: true,
+			edits: []edit{
+				{old: `"London"`, replacement: "\"Lon\ndon\""},
+				{old: `"en"`, replacement: `"pt"`},
+				{old: `12345`, replacement: `54321`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(parser.NewLexer(input), parser.WithPreserveFormatting())
+
+			root, err := p.ParseJSON()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			current := input
+
+			for _, e := range tt.edits {
+				start := strings.Index(current, e.old)
+				end := start + len(e.old)
+				current = current[:start] + e.replacement + current[end:]
+
+				value, err := p.ReparseRange(current, start, end)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				expected, err := parser.Parse(current)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if (value == root) != tt.sameRoot {
+					t.Errorf("Expected the root to be kept: %v", tt.sameRoot)
+				}
+
+				if !parser.Equal(value, expected) {
+					t.Errorf("Expected %#v, got %#v", expected, value)
+				}
+
+				if got, want := positions(t, value), positions(t, expected); got != want {
+					t.Errorf("Expected positions\n%s\ngot\n%s", want, got)
+				}
+
+				b, err := parser.MarshalWith(value, parser.MarshalOptions{PreserveFormatting: true})
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if string(b) != current {
+					t.Errorf("Expected preserved source %q, got %q", current, b)
+				}
+			}
+		})
+	}
+}
+
+func TestReparseRangeErrors(t *testing.T) {
+	const input = `{"a": [1, 2]}`
+
+	t.Run("Without PreserveFormatting", func(t *testing.T) {
+		p := parser.NewParser(parser.NewLexer(input))
+		if _, err := p.ParseJSON(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := p.ReparseRange(`{"a": [1, 3]}`, 10, 11); err == nil {
+			t.Error("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Out of bounds", func(t *testing.T) {
+		p := parser.NewParser(parser.NewLexer(input), parser.WithPreserveFormatting())
+		if _, err := p.ParseJSON(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := p.ReparseRange(input, 5, 50); err == nil {
+			t.Error("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Invalid edit", func(t *testing.T) {
+		p := parser.NewParser(parser.NewLexer(input), parser.WithPreserveFormatting())
+
+		value, err := p.ParseJSON()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err = p.ReparseRange(`{"a": [1, ]}`, 10, 11)
+		if err == nil || err.Error() != "Line 1, Column 9: unexpected , before ]" {
+			t.Errorf("Expected an error at the comma, got %v", err)
+		}
+
+		// The tree is kept for the next edit
+		got, err := p.ReparseRange(`{"a": [1, 2, 3]}`, 11, 11)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got != value || len(value.(*parser.Object).Pairs["a"].(*parser.Array).Elements) != 3 {
+			t.Errorf("Expected the array of the original tree to be updated, got %#v", got)
+		}
+	})
+}
+
+// positions lists the line and column of every value in the tree rooted at v.
+func positions(t *testing.T, v parser.Value) string {
+	t.Helper()
+
+	var b strings.Builder
+
+	err := parser.Walk(v, func(pointer string, v parser.Value) error {
+		var token parser.Token
+
+		switch val := v.(type) {
+		case *parser.Object:
+			token = val.Token
+		case *parser.Array:
+			token = val.Token
+		case *parser.StringLiteral:
+			token = val.Token
+		case *parser.NumberLiteral:
+			token = val.Token
+		case *parser.Boolean:
+			token = val.Token
+		case *parser.Null:
+			token = val.Token
+		}
+
+		fmt.Fprintf(&b, "%s %d:%d\n", pointer, token.Line, token.Column)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package parser
+
+import "strconv"
+
+// ReplaceWhere returns a copy of the tree rooted at v in which every value for which match returns
+// true is replaced with what replacement returns for it, such as to redact the strings matching a
+// pattern or round every number. match is called with the JSON Pointer of each value, as Walk
+// gives it, and v itself is left untouched: match and replacement only see the copy, which
+// replacement may modify and return.
+//
+// Values are visited in pre-order, in the order of Walk, so a container is matched before its
+// children. A replaced value is not descended into, nor is the value replacing it, so no value
+// nested in a match is ever passed to match. The root may be replaced too. A nil returned by
+// replacement is stored as null.
+func ReplaceWhere(v Value, match func(path string, v Value) bool, replacement func(old Value) Value) Value {
+	return replaceWhere("", clone(v), match, replacement)
+}
+
+// replaceWhere replaces the matches found in v, at pointer in the copied tree, for ReplaceWhere.
+func replaceWhere(pointer string, v Value, match func(string, Value) bool, replacement func(Value) Value) Value {
+	if match(pointer, v) {
+		if result := replacement(v); result != nil {
+			return result
+		}
+
+		return NewNull()
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		for _, k := range val.SortedKeys() {
+			val.Pairs[k] = replaceWhere(pointer+"/"+escapePointerToken(k), val.Pairs[k], match, replacement)
+		}
+
+	case *Array:
+		for i, elem := range val.Elements {
+			val.Elements[i] = replaceWhere(pointer+"/"+strconv.Itoa(i), elem, match, replacement)
+		}
+	}
+
+	return v
+}
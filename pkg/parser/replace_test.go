@@ -0,0 +1,81 @@
+package parser_test
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestReplaceWhere(t *testing.T) {
+	email := regexp.MustCompile(`^[^@]+@[^@]+$`)
+
+	isEmail := func(_ string, v parser.Value) bool {
+		s, ok := v.(*parser.StringLiteral)
+
+		return ok && email.MatchString(s.Value)
+	}
+	redact := func(parser.Value) parser.Value { return &parser.StringLiteral{Value: "[redacted]"} }
+
+	isNumber := func(_ string, v parser.Value) bool {
+		_, ok := v.(*parser.NumberLiteral)
+
+		return ok
+	}
+	round := func(old parser.Value) parser.Value {
+		return &parser.NumberLiteral{Float: math.Round(old.(*parser.NumberLiteral).Float), IsValid: true}
+	}
+
+	atPath := func(pointer string) func(string, parser.Value) bool {
+		return func(path string, _ parser.Value) bool { return path == pointer }
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		match       func(string, parser.Value) bool
+		replacement func(parser.Value) parser.Value
+		expected    string
+	}{
+		{"Redact strings", `{"user": {"email": "a@b.c", "name": "Ana"}, "cc": ["x@y.z", "none"]}`, isEmail, redact,
+			`{"cc":["[redacted]","none"],"user":{"email":"[redacted]","name":"Ana"}}`},
+		{"Round numbers", `{"a": 1.4, "b": [2.6, {"c": 7.5}]}`, isNumber, round, `{"a":1,"b":[3,{"c":8}]}`},
+		{"Container", `{"secret": {"key": "k"}, "ok": 1}`, atPath("/secret"), redact, `{"ok":1,"secret":"[redacted]"}`},
+		{"Root", `[1]`, atPath(""), func(parser.Value) parser.Value { return &parser.Object{} }, `{}`},
+		{"Nil replacement", `{"a": 1}`, atPath("/a"), func(parser.Value) parser.Value { return nil }, `{"a":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := mustParse(t, tt.input)
+
+			got, err := parser.MarshalWith(parser.ReplaceWhere(original, tt.match, tt.replacement), parser.MarshalOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if !parser.Equal(original, mustParse(t, tt.input)) {
+				t.Error("Expected the original tree to be untouched")
+			}
+		})
+	}
+
+	// Matches are found in pre-order, and the children of a match are not visited
+	var visited []string
+
+	parser.ReplaceWhere(mustParse(t, `{"a": {"b": 1}, "c": [2]}`), func(path string, v parser.Value) bool {
+		visited = append(visited, path)
+
+		return path == "/a"
+	}, func(old parser.Value) parser.Value { return old })
+
+	if expected := []string{"", "/a", "/c", "/c/0"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Expected visits %v, got %v", expected, visited)
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// SizeBytes returns the length in bytes of the compact JSON that Marshal produces for v, computed
+// without serializing it. This lets callers budget memory or transfer size up front. Values that
+// Marshal cannot write are counted by their literal, such as invalid numbers, or as zero, such as
+// values whose MarshalJSON fails.
+func SizeBytes(v Value) int {
+	switch val := v.(type) {
+	case *Object:
+		size := 2 // {}
+		if n := len(val.Pairs); n > 1 {
+			size += n - 1 // commas
+		}
+
+		for k, v := range val.Pairs {
+			size += stringSize(k) + 1 + SizeBytes(v) // key:value
+		}
+
+		return size
+
+	case *Array:
+		size := 2 // []
+		if n := len(val.Elements); n > 1 {
+			size += n - 1 // commas
+		}
+
+		for _, elem := range val.Elements {
+			size += SizeBytes(elem)
+		}
+
+		return size
+
+	case *StringLiteral:
+		return stringSize(val.Value)
+
+	case *NumberLiteral:
+		s, err := formatNumber(val, &MarshalOptions{})
+		if err != nil {
+			return len(val.Value)
+		}
+
+		return len(s)
+
+	case *Boolean:
+		if val.Value {
+			return len("true")
+		}
+
+		return len("false")
+
+	case *Null:
+		return len("null")
+
+	case *RawMessage:
+		parsed, err := val.parse()
+		if err != nil {
+			return len(val.Bytes)
+		}
+
+		return SizeBytes(parsed)
+
+	case json.Marshaler:
+		marshaled, err := marshalerValue(val)
+		if err != nil {
+			return 0
+		}
+
+		return SizeBytes(marshaled)
+
+	default:
+		return 0
+	}
+}
+
+// FitsWithin reports whether the compact JSON that Marshal produces for v, as measured by
+// SizeBytes, is at most maxBytes long, such as to check a payload against the body-size limit of
+// an API before sending it or to decide where to split a batch. Indented output is larger. It
+// stops counting as soon as the running size goes over maxBytes, so checking a large tree against
+// a small limit only visits as much of it as fits.
+func FitsWithin(v Value, maxBytes int) bool {
+	return sizeWithin(v, maxBytes) >= 0
+}
+
+// sizeWithin returns what is left of budget once the size of v is taken away, stopping early with
+// a negative result as soon as the budget is exceeded.
+func sizeWithin(v Value, budget int) int {
+	switch val := v.(type) {
+	case *Object:
+		budget -= 2 + max(len(val.Pairs)-1, 0) // braces and commas
+
+		for k, v := range val.Pairs {
+			if budget < 0 {
+				return budget
+			}
+
+			budget = sizeWithin(v, budget-stringSize(k)-1) // key:value
+		}
+
+		return budget
+
+	case *Array:
+		budget -= 2 + max(len(val.Elements)-1, 0) // brackets and commas
+
+		for _, elem := range val.Elements {
+			if budget < 0 {
+				return budget
+			}
+
+			budget = sizeWithin(elem, budget)
+		}
+
+		return budget
+
+	default:
+		return budget - SizeBytes(v)
+	}
+}
+
+// stringSize returns the length of s once quoted and escaped by writeString with the options of
+// Marshal.
+func stringSize(s string) int {
+	size := 2 // quotes
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\' || c == '\b' || c == '\f' || c == '\n' || c == '\r' || c == '\t':
+				size += 2
+			case c < 0x20 || c == '<' || c == '>' || c == '&':
+				size += 6 // \u00XX
+			default:
+				size++
+			}
+
+			i++
+
+			continue
+		}
+
+		r, n := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case r == utf8.RuneError && n == 1:
+			size += utf8.RuneLen(utf8.RuneError)
+		case r == '\u2028' || r == '\u2029':
+			size += 6 // \u2028, escaped unless NoEscapeHTML is set
+		default:
+			size += n
+		}
+
+		i += n
+	}
+
+	return size
+}
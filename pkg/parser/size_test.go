@@ -0,0 +1,108 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestSizeBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		value parser.Value
+	}{
+		{"Empty object", mustParse(t, `{}`)},
+		{"Empty array", mustParse(t, `[]`)},
+		{"Mixed document", mustParse(t, `{"name": "こんにちは", "n": [1, -2.5e10, 0], "ok": true, "no": false, "x": null, "deep": {"a": [[], {}]}}`)},
+		{"Escapes", &parser.Array{Elements: []parser.Value{
+			&parser.StringLiteral{Value: "quote \" backslash \\ newline \n bell \x07 invalid \xff html <a>& separators \u2028\u2029"},
+		}}},
+		{"Escaped key", &parser.Object{Pairs: map[string]parser.Value{
+			"tab\tkey": &parser.Null{},
+			"plain":    &parser.Boolean{Value: true},
+		}}},
+		{"Constructed numbers", &parser.Array{Elements: []parser.Value{
+			&parser.NumberLiteral{Int: 42, Float: 42, IsInt: true, IsValid: true},
+			&parser.NumberLiteral{Float: 1.5, IsValid: true},
+			&parser.NumberLiteral{Float: 1e21, IsValid: true},
+		}}},
+		{"Leading zeros", mustParse(t, `{"id": 007, "n": [-00.50, 0012e1]}`, parser.WithLeadingZeros())},
+		{"Raw with whitespace", mustParse(t, `{"raw": { "x" : [1, 2] }, "n": 1}`, parser.WithRawPaths("/raw"))},
+		{"Marshaler", &parser.Object{Pairs: map[string]parser.Value{
+			"price": &decimal{digits: "19.990000000000000001"},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := parser.SizeBytes(tt.value); got != len(data) {
+				t.Errorf("Expected %d bytes (%s), got %d", len(data), data, got)
+			}
+		})
+	}
+}
+
+func TestFitsWithin(t *testing.T) {
+	value := mustParse(t, `{"name": "こんにちは", "n": [1, -2.5e10, 0], "deep": {"a": [[], {}]}, "s": "<\u2028>"}`)
+	size := parser.SizeBytes(value)
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		expected bool
+	}{
+		{"Exact size", size, true},
+		{"Larger", size + 100, true},
+		{"One byte short", size - 1, false},
+		{"Much smaller", 3, false},
+		{"Negative", -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.FitsWithin(value, tt.maxBytes); got != tt.expected {
+				t.Errorf("Expected %v for %d bytes against a size of %d, got %v", tt.expected, tt.maxBytes, size, got)
+			}
+		})
+	}
+
+	if !parser.FitsWithin(mustParse(t, `[]`), 2) || parser.FitsWithin(mustParse(t, `[]`), 1) {
+		t.Error("Expected an empty array to take 2 bytes")
+	}
+
+	// The limit is exactly the length of the output of Marshal, whatever the literals held
+	values := []struct {
+		name  string
+		value parser.Value
+	}{
+		{"Constructed", &parser.Object{Pairs: map[string]parser.Value{
+			"int":   &parser.NumberLiteral{Int: 42, Float: 42, IsInt: true, IsValid: true},
+			"float": &parser.NumberLiteral{Float: 1.5, IsValid: true},
+			"price": &decimal{digits: "19.99"},
+		}}},
+		{"Padded", mustParse(t, `{"id": 007, "n": [-00.50]}`, parser.WithLeadingZeros())},
+		{"Raw", mustParse(t, `{"raw": { "x" : [1, 2] }}`, parser.WithRawPaths("/raw"))},
+	}
+
+	for _, tt := range values {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parser.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !parser.FitsWithin(tt.value, len(data)) {
+				t.Errorf("Expected %s to fit within %d bytes", data, len(data))
+			}
+
+			if parser.FitsWithin(tt.value, len(data)-1) {
+				t.Errorf("Expected %s not to fit within %d bytes", data, len(data)-1)
+			}
+		})
+	}
+}
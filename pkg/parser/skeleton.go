@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// Skeleton returns an outline of the tree rooted at v that keeps its shape but none of its data,
+// for generating example schemas from sample documents. Object keys are preserved, every string
+// becomes "string", every number 0, every boolean false, and null stays null. Each non-empty
+// array is collapsed to a single element outlining its first element. The result is a new tree;
+// v is left untouched.
+func Skeleton(v Value) Value {
+	return skeleton(clone(v))
+}
+
+// skeleton outlines the copied tree rooted at v in place for Skeleton, returning the value to
+// store in place of v.
+func skeleton(v Value) Value {
+	switch val := v.(type) {
+	case *Object:
+		for k, child := range val.Pairs {
+			val.Pairs[k] = skeleton(child)
+		}
+
+	case *Array:
+		if len(val.Elements) > 1 {
+			val.Elements = []Value{val.Elements[0]} // drop the rest rather than keep them reachable
+		}
+
+		for i, elem := range val.Elements {
+			val.Elements[i] = skeleton(elem)
+		}
+
+	case *StringLiteral:
+		val.Token, val.Value = Token{Type: TokenString, Literal: "string"}, "string"
+
+	case *NumberLiteral:
+		return NewNumberLiteral(Token{Type: TokenNumber, Literal: "0"})
+
+	case *Boolean:
+		val.Token, val.Value = Token{Type: TokenFalse, Literal: "false"}, false
+
+	default:
+		return NewNull()
+	}
+
+	return v
+}
+
+// KeyMask returns a mask of the tree rooted at v that shows which fields exist without revealing
+// any value, for permission previews and for logging the shape of sensitive documents. Objects
+// keep all their keys and each non-empty array is collapsed to a single element masking its
+// first element, like Skeleton does, but every scalar becomes null. Unlike Skeleton, the mask
+// therefore does not tell the types of the values apart either. The result is a new tree; v is
+// left untouched.
+func KeyMask(v Value) Value {
+	switch val := v.(type) {
+	case *Object:
+		result := newObject()
+		for k, child := range val.Pairs {
+			result.Pairs[k] = KeyMask(child)
+		}
+
+		return result
+
+	case *Array:
+		result := newArray(nil)
+		if len(val.Elements) > 0 {
+			result.Elements = append(result.Elements, KeyMask(val.Elements[0]))
+		}
+
+		return result
+
+	default:
+		return NewNull()
+	}
+}
+
+// Intersect returns the outline, in the form of Skeleton, of the structure common to all of docs,
+// for finding the fields guaranteed to be present across a dataset of samples. Objects keep only
+// the keys present in every document, and the elements of arrays at the same location are
+// reconciled together into a single element, so a key is kept inside an array only if every
+// element of every document has it. An array that is empty in any document is kept empty.
+//
+// Where the documents disagree on the type of a value, the outline holds a string naming every
+// type found, sorted and joined by "|", such as "number|string" for a field that is sometimes a
+// number and sometimes a string, or "null|object" for an optional object whose keys are then
+// dropped. A placeholder string from Skeleton is always a single type name, so a conflict can be
+// told apart by its "|". Intersect returns nil when docs is empty, and the docs are left
+// untouched.
+func Intersect(docs ...Value) Value {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return intersect(docs)
+}
+
+// intersect reconciles values, of which there is at least one, found at the same location.
+func intersect(values []Value) Value {
+	types := make(map[ValueType]bool)
+	for _, v := range values {
+		types[outlineType(v)] = true
+	}
+
+	if len(types) > 1 {
+		names := make([]string, 0, len(types))
+		for t := range types {
+			names = append(names, string(t))
+		}
+
+		sort.Strings(names)
+
+		conflict := strings.Join(names, "|")
+
+		return &StringLiteral{Token: Token{Type: TokenString, Literal: conflict}, Value: conflict}
+	}
+
+	switch first := values[0].(type) {
+	case *Object:
+		result := newObject()
+
+	keys:
+		for k := range first.Pairs {
+			children := make([]Value, 0, len(values))
+
+			for _, v := range values {
+				child, ok := v.(*Object).Pairs[k]
+				if !ok {
+					continue keys
+				}
+
+				children = append(children, child)
+			}
+
+			result.Pairs[k] = intersect(children)
+		}
+
+		return result
+
+	case *Array:
+		result := newArray(nil)
+
+		var elements []Value
+
+		for _, v := range values {
+			array := v.(*Array)
+			if len(array.Elements) == 0 {
+				return result
+			}
+
+			elements = append(elements, array.Elements...)
+		}
+
+		result.Elements = append(result.Elements, intersect(elements))
+
+		return result
+
+	default:
+		return Skeleton(first)
+	}
+}
+
+// outlineType returns the type of the outline Skeleton makes for v, which is null for values
+// this package does not know.
+func outlineType(v Value) ValueType {
+	if t := typeOf(v); t != "" {
+		return t
+	}
+
+	return TypeNull
+}
+
+// CompatibleShape reports whether a and b have the same structure, ignoring their scalar
+// values, for detecting schema drift between documents such as the configurations of two
+// environments. Objects are compatible when they have the same keys with compatible values: a key
+// missing from either side makes them incompatible. Likewise, arrays are compatible when they have
+// the same length and compatible elements at every index, so [1, 2] and [3, 4] are compatible but
+// [1, 2] and [3] are not. Scalars are compatible when they have the same type, null being a type
+// of its own. Every document is compatible with itself.
+func CompatibleShape(a, b Value) bool {
+	if outlineType(a) != outlineType(b) {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *Object:
+		y := b.(*Object)
+		if len(x.Pairs) != len(y.Pairs) {
+			return false
+		}
+
+		for k, v := range x.Pairs {
+			other, ok := y.Pairs[k]
+			if !ok || !CompatibleShape(v, other) {
+				return false
+			}
+		}
+
+		return true
+
+	case *Array:
+		y := b.(*Array)
+		if len(x.Elements) != len(y.Elements) {
+			return false
+		}
+
+		for i := range x.Elements {
+			if !CompatibleShape(x.Elements[i], y.Elements[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return true
+	}
+}
+
+// draft07 is the URI of the JSON Schema draft-07 meta-schema, written as the $schema of
+// InferSchema.
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// InferSchema returns a draft-07 JSON Schema describing the sample document v, as a tree that
+// can be marshaled like any other, to jump-start the authoring of a schema from example data.
+// Every value is described by its "type": "object", "array", "string", "boolean", "null", or
+// "integer" for numbers written without a fraction or exponent and "number" for the others.
+// Objects list each of their keys under "properties" and all of them under "required", and arrays
+// describe their elements under "items". The root also names the draft in "$schema".
+//
+// The inference is best-effort and looks at this single sample only: arrays are described by
+// their first element alone, and an empty array gets no "items", every key present is required
+// and no other key is known to be optional, and a null value is typed "null" rather than as what
+// it stands in for. No formats, ranges or enums are inferred. v is left untouched.
+func InferSchema(v Value) Value {
+	schema := inferSchema(v)
+	schema.Set("$schema", &StringLiteral{Token: Token{Type: TokenString, Literal: draft07}, Value: draft07})
+
+	return schema
+}
+
+// inferSchema returns the schema describing v, without $schema, for InferSchema.
+func inferSchema(v Value) *Object {
+	name := outlineType(v)
+	if n, ok := v.(*NumberLiteral); ok && (n.IsInt || n.Overflowed) {
+		name = "integer"
+	}
+
+	schema := newObject().Set("type", &StringLiteral{Token: Token{Type: TokenString, Literal: string(name)}, Value: string(name)})
+
+	switch val := v.(type) {
+	case *Object:
+		properties := newObject()
+		required := newArray(nil)
+
+		for _, k := range val.SortedKeys() {
+			properties.Pairs[k] = inferSchema(val.Pairs[k])
+			required.Elements = append(required.Elements, &StringLiteral{Token: Token{Type: TokenString, Literal: k}, Value: k})
+		}
+
+		schema.Set("properties", properties)
+
+		if len(required.Elements) > 0 {
+			schema.Set("required", required)
+		}
+
+	case *Array:
+		if len(val.Elements) > 0 {
+			schema.Set("items", inferSchema(val.Elements[0]))
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,191 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Scalars", `{"s": "Ana", "n": 42.5, "b": true, "z": null}`, `{"b":false,"n":0,"s":"string","z":null}`},
+		{"Array collapsed", `{"tags": ["a", "b", "c"], "none": []}`, `{"none":[],"tags":["string"]}`},
+		{"Records", `[{"id": 1, "user": {"name": "x", "roles": ["admin"]}}, {"id": 2}]`, `[{"id":0,"user":{"name":"string","roles":["string"]}}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parser.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			original, _ := parser.Marshal(value)
+
+			got, err := parser.Marshal(parser.Skeleton(value))
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if after, _ := parser.Marshal(value); string(after) != string(original) {
+				t.Errorf("Expected the input to be untouched, got %s", after)
+			}
+		})
+	}
+
+	if got := parser.Skeleton(&parser.StringLiteral{Value: "text"}); got.String() != "string" {
+		t.Errorf("Expected a string placeholder for a scalar root, got %s", got)
+	}
+}
+
+func TestKeyMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Scalars", `{"s": "Ana", "n": 42.5, "b": true, "z": null}`, `{"b":null,"n":null,"s":null,"z":null}`},
+		{"Array collapsed", `{"tags": ["a", "b", "c"], "none": []}`, `{"none":[],"tags":[null]}`},
+		{"Records", `[{"id": 1, "user": {"name": "x", "roles": ["admin"]}}, {"id": 2}]`, `[{"id":null,"user":{"name":null,"roles":[null]}}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := mustParse(t, tt.input)
+			original, _ := parser.Marshal(value)
+
+			got, err := parser.Marshal(parser.KeyMask(value))
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if after, _ := parser.Marshal(value); string(after) != string(original) {
+				t.Errorf("Expected the input to be untouched, got %s", after)
+			}
+		})
+	}
+
+	if got := parser.KeyMask(&parser.StringLiteral{Value: "secret"}); got.String() != "null" {
+		t.Errorf("Expected null for a scalar root, got %s", got)
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   []string
+		expected string
+	}{
+		{"Single document", []string{`{"a": 1, "b": ["x"]}`}, `{"a":0,"b":["string"]}`},
+		{"Common keys", []string{`{"id": 1, "name": "x", "extra": true}`, `{"id": 2, "name": "y"}`}, `{"id":0,"name":"string"}`},
+		{"Nested", []string{`{"user": {"id": 1, "email": "a"}}`, `{"user": {"id": 2}}`}, `{"user":{"id":0}}`},
+		{"Type conflict", []string{`{"v": 1}`, `{"v": "1"}`, `{"v": 2}`}, `{"v":"number|string"}`},
+		{"Optional object", []string{`{"v": {"a": 1}}`, `{"v": null}`}, `{"v":"null|object"}`},
+		{"Array elements", []string{`[{"id": 1, "x": 1}, {"id": 2}]`, `[{"id": 3, "x": 2}]`}, `[{"id":0}]`},
+		{"Empty array", []string{`{"tags": ["a"]}`, `{"tags": []}`}, `{"tags":[]}`},
+		{"Root conflict", []string{`{}`, `[]`}, `"array|object"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := make([]parser.Value, len(tt.inputs))
+			for i, input := range tt.inputs {
+				docs[i] = mustParse(t, input)
+			}
+
+			got, err := parser.Marshal(parser.Intersect(docs...))
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if got := parser.Intersect(); got != nil {
+		t.Errorf("Expected nil without documents, got %v", got)
+	}
+}
+
+func TestCompatibleShape(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"Different values", `{"host": "a", "port": 80, "tls": true}`, `{"host": "b", "port": 443, "tls": false}`, true},
+		{"Missing key", `{"host": "a", "port": 80}`, `{"host": "a"}`, false},
+		{"Type changed", `{"port": 80}`, `{"port": "80"}`, false},
+		{"Null is a type", `{"proxy": null}`, `{"proxy": "x"}`, false},
+		{"Nested", `{"db": {"user": "a"}}`, `{"db": {"name": "a"}}`, false},
+		{"Array elements", `{"hosts": ["a", "b"]}`, `{"hosts": ["c", "d"]}`, true},
+		{"Array lengths", `{"hosts": ["a", "b"]}`, `{"hosts": ["c"]}`, false},
+		{"Element shapes", `[{"id": 1}, {"id": 2}]`, `[{"id": 3}, {"id": 4, "x": 1}]`, false},
+		{"Mixed array", `[1, "x", null]`, `[2, "y", null]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+
+			if got := parser.CompatibleShape(a, b); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+
+			if got := parser.CompatibleShape(b, a); got != tt.expected {
+				t.Errorf("Expected %v with the arguments swapped, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	const schema = `"$schema":"http://json-schema.org/draft-07/schema#"`
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Scalars", `{"s": "x", "i": 1, "f": 1.5, "e": 1e3, "b": false, "z": null}`,
+			`{` + schema + `,"properties":{"b":{"type":"boolean"},"e":{"type":"number"},"f":{"type":"number"},"i":{"type":"integer"},` +
+				`"s":{"type":"string"},"z":{"type":"null"}},"required":["b","e","f","i","s","z"],"type":"object"}`},
+		{"Items from the first element", `[{"id": 1, "tags": []}, "other"]`,
+			`{` + schema + `,"items":{"properties":{"id":{"type":"integer"},"tags":{"type":"array"}},"required":["id","tags"],"type":"object"},"type":"array"}`},
+		{"Empty object", `{"nested": {}}`,
+			`{` + schema + `,"properties":{"nested":{"properties":{},"type":"object"}},"required":["nested"],"type":"object"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := mustParse(t, tt.input)
+
+			got, err := parser.Marshal(parser.InferSchema(v))
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if !parser.Equal(v, mustParse(t, tt.input)) {
+				t.Error("Expected the input to be untouched")
+			}
+		})
+	}
+}
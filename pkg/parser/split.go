@@ -0,0 +1,89 @@
+package parser
+
+// SplitJSON is a bufio.SplitFunc that splits a stream of JSON values, such as a JSON Lines file,
+// into one token per value, for reading it with the familiar bufio.Scanner API:
+//
+//	scanner := bufio.NewScanner(r)
+//	scanner.Split(parser.SplitJSON)
+//
+//	for scanner.Scan() {
+//		v, err := parser.ParseScannerToken(scanner.Bytes())
+//		...
+//	}
+//
+// Like the records of CountRecords, each value must be an object or an array, and values may be
+// separated by any whitespace, newlines or none included. A value spanning several reads of the
+// underlying reader is only returned once it is complete, which Scanner supports by growing its
+// buffer, up to its maximum token size. SplitJSON only finds where values end, by matching
+// braces and brackets outside strings, and does not validate them: text that does not start an
+// object or array is returned as a token of its own, up to the next whitespace, brace or bracket,
+// and the rest of the input is returned as a final token when it ends inside a value, so that
+// ParseScannerToken reports either as a ParseError.
+func SplitJSON(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for start < len(data) && isSpace(data[start]) {
+		start++
+	}
+
+	if start == len(data) {
+		return len(data), nil, nil
+	}
+
+	if c := data[start]; c != '{' && c != '[' {
+		for i := start; i < len(data); i++ {
+			if c := data[i]; isSpace(c) || c == '{' || c == '[' {
+				return i, data[start:i], nil
+			}
+		}
+
+		return splitRest(data, start, atEOF)
+	}
+
+	depth := 0
+	inString := false
+
+	for i := start; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case inString:
+			if c == '\\' {
+				i++ // skip the escaped character
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth--; depth == 0 {
+				return i + 1, data[start : i+1], nil
+			}
+		}
+	}
+
+	return splitRest(data, start, atEOF)
+}
+
+// splitRest returns the rest of data, from start, as the final token for SplitJSON at the end of
+// the input, or asks for more data otherwise.
+func splitRest(data []byte, start int, atEOF bool) (int, []byte, error) {
+	if !atEOF {
+		return start, nil, nil
+	}
+
+	return len(data), data[start:], nil
+}
+
+// isSpace reports whether c is JSON whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// ParseScannerToken parses a token returned by a bufio.Scanner splitting its input with
+// SplitJSON, configured by opts. The token is copied, so the tree stays valid after the Scanner
+// reuses its buffer for the next token.
+func ParseScannerToken(tok []byte, opts ...Option) (Value, error) {
+	return Parse(string(tok), opts...)
+}
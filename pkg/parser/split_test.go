@@ -0,0 +1,87 @@
+package parser_test
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestSplitJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"JSON Lines", "{\"a\": 1}\n[2]\n{\"b\": {\"c\": []}}\n", []string{`{"a": 1}`, `[2]`, `{"b": {"c": []}}`}},
+		{"No separators", `{"a":1}[2]{}`, []string{`{"a":1}`, `[2]`, `{}`}},
+		{"Brackets in strings", `{"s": "}]\"{"} ["[", "\\"]`, []string{`{"s": "}]\"{"}`, `["[", "\\"]`}},
+		{"Multi-line value", "{\n  \"a\": [\n    1\n  ]\n}\n\n", []string{"{\n  \"a\": [\n    1\n  ]\n}"}},
+		{"Scalar", `{} 42 []`, []string{`{}`, `42`, `[]`}},
+		{"Truncated", `{"a": 1} {"b": [`, []string{`{"a": 1}`, `{"b": [`}},
+		{"Whitespace only", " \n\t", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reading a byte at a time makes every value span several refills of the buffer
+			scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(tt.input)))
+			scanner.Split(parser.SplitJSON)
+
+			var got []string
+			for scanner.Scan() {
+				got = append(got, scanner.Text())
+			}
+
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(`[` + strings.Repeat(`1,`, 100) + `1]`))
+	scanner.Buffer(make([]byte, 16), 64)
+	scanner.Split(parser.SplitJSON)
+
+	if scanner.Scan() || !errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		t.Errorf("Expected a value over the maximum token size to fail, got %v", scanner.Err())
+	}
+}
+
+func TestParseScannerToken(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("{\"id\": 1}\n{\"id\": 2}\n42\n{\"id\":"))
+	scanner.Split(parser.SplitJSON)
+
+	var (
+		values []parser.Value
+		errs   []string
+	)
+
+	for scanner.Scan() {
+		v, err := parser.ParseScannerToken(scanner.Bytes())
+		if err != nil {
+			errs = append(errs, err.Error())
+
+			continue
+		}
+
+		values = append(values, v)
+	}
+
+	if len(values) != 2 || parser.GetIntOr(values[0], "id", 0) != 1 || parser.GetIntOr(values[1], "id", 0) != 2 {
+		t.Errorf("Expected the two records to survive the buffer being reused, got %v", values)
+	}
+
+	expected := []string{"Line 1, Column 1: expected { or [, got NUMBER", "Line 1, Column 6: expected }, got EOF: the object opened at line 1, column 1 is not closed"}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected errors %q, got %q", expected, errs)
+	}
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ArrayStream reads the elements of a top-level JSON array one at a time, for arrays too large
+// to be held in memory at once. It is created by StreamArray.
+type ArrayStream struct {
+	p       *Parser
+	err     error
+	started bool
+}
+
+// StreamArray starts reading a JSON array from r, configured by opts, and returns a stream over
+// its elements. Only the opening bracket is read before StreamArray returns; it fails if the
+// input does not start with one. The elements are then parsed lazily as the stream is ranged
+// over with All, so that memory use is bounded by the largest element rather than the size of
+// the array. Unlike ParseReader, StreamArray does not decompress gzip input.
+func StreamArray(r io.Reader, opts ...Option) (*ArrayStream, error) {
+	s := &ArrayStream{p: NewParser(NewLexer(r), opts...)}
+	s.p.start()
+
+	switch s.p.currentToken.Type {
+	case TokenBracketOpen:
+		s.p.enter()        // The array counts toward MaxDepth
+		s.p.takeComments() // Comments before the array belong to no element
+	case TokenEOF:
+		s.p.addError("unexpected end of input: empty document")
+	default:
+		s.p.addError("expected [, got %s", s.p.currentToken.Type)
+	}
+
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// All returns an iterator over the index and value of each element of the array, in order. An
+// element that fails to parse, a failure reading the input, or content after the closing
+// bracket ends the iteration; Err then reports what went wrong. The values yielded are complete
+// trees that stay valid after the iteration moves on. A stream can only be ranged over once.
+func (s *ArrayStream) All() iter.Seq2[int, Value] {
+	return func(yield func(int, Value) bool) {
+		if s.started || s.err != nil {
+			return
+		}
+
+		s.started = true
+		p := s.p
+
+		for i := 0; ; i++ {
+			if i == 0 && p.peekToken.Type == TokenBracketClose {
+				break
+			}
+
+			if i > 0 {
+				if p.peekToken.Type != TokenComma {
+					if p.peekToken.Type != TokenBracketClose {
+						p.expectedComma(p.peekToken, false)
+					}
+
+					break
+				}
+
+				p.nextToken() // move past comma
+
+				if p.peekToken.Type == TokenBracketClose {
+					p.addError("unexpected , before ]")
+					break
+				}
+			}
+
+			p.nextToken() // move to the element
+
+			p.path = append(p.path, pathElement{index: i})
+			value := p.checkString(p.parseValue())
+
+			// Containers are reported to OnValue when they are closed
+			if value != nil && p.OnValue != nil && typeOf(value) != TypeObject && typeOf(value) != TypeArray {
+				p.report(value, p.currentToken)
+			}
+
+			p.path = p.path[:len(p.path)-1]
+
+			if s.fail() != nil {
+				return
+			}
+
+			if !yield(i, value) {
+				return
+			}
+		}
+
+		if !p.failed() {
+			p.nextToken() // move to ]
+
+			if p.peekToken.Type != TokenEOF {
+				p.nextToken()
+				p.addError("unexpected token %s after the document", p.currentToken.Type)
+			}
+		}
+
+		s.fail()
+	}
+}
+
+// Err returns the error that ended the iteration of All, or nil when the whole array was read.
+func (s *ArrayStream) Err() error {
+	return s.err
+}
+
+// fail records the first error of the input or the parser in err, if any, and returns err.
+func (s *ArrayStream) fail() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.p.lexer.readErr != nil {
+		s.err = fmt.Errorf("reading input: %w", s.p.lexer.readErr)
+	} else if s.p.failed() {
+		s.err = s.p.errors[0]
+	}
+
+	return s.err
+}
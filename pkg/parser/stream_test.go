@@ -0,0 +1,138 @@
+package parser_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestStreamArray(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   io.Reader
+		count    int
+		expected string
+	}{
+		{"Small array", strings.NewReader(`[1, "two", {"three": [3]}, null]`), 4, ""},
+		{"Empty array", strings.NewReader(` [ ] `), 0, ""},
+		{"Large stream", &recordsReader{remaining: 100000, tail: strings.NewReader(`null]`)}, 100001, ""},
+		{"Malformed element", strings.NewReader(`[1, {"a": }, 3]`), 1, "Line 1, Column 11: expected a value after :, got }"},
+		{"Missing comma", strings.NewReader(`[1 2]`), 1, "Line 1, Column 4: expected , between elements, got NUMBER"},
+		{"Trailing content", strings.NewReader(`[1] [2]`), 1, "Line 1, Column 5: unexpected token [ after the document"},
+		{"Read error", io.MultiReader(strings.NewReader(`[1, 2, `), iotest.ErrReader(errors.New("disk failure"))), 2, "reading input: disk failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := parser.StreamArray(tt.reader)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			count := 0
+
+			for i, value := range stream.All() {
+				if i != count {
+					t.Fatalf("Expected index %d, got %d", count, i)
+				}
+
+				if value == nil {
+					t.Fatalf("Expected a value at index %d", i)
+				}
+
+				count++
+			}
+
+			if count != tt.count {
+				t.Errorf("Expected %d elements, got %d", tt.count, count)
+			}
+
+			err = stream.Err()
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestStreamArrayErrorPath(t *testing.T) {
+	stream, err := parser.StreamArray(strings.NewReader(`[1, {"a": [true, nul]}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := range stream.All() {
+		if i > 0 {
+			t.Fatalf("Expected the error to stop the iteration, got index %d", i)
+		}
+	}
+
+	var parseErr *parser.ParseError
+	if !errors.As(stream.Err(), &parseErr) {
+		t.Fatalf("Expected a *ParseError, got %v", stream.Err())
+	}
+
+	if parseErr.Path != "/1/a/1" {
+		t.Errorf("Expected path /1/a/1, got %q", parseErr.Path)
+	}
+}
+
+func TestStreamArrayValues(t *testing.T) {
+	stream, err := parser.StreamArray(strings.NewReader(`[{"id": 1}, {"id": 2}, {"id": 3}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var ids []int64
+
+	for _, value := range stream.All() {
+		ids = append(ids, parser.GetIntOr(value, "id", -1))
+		if len(ids) == 2 {
+			break
+		}
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("Expected [1 2], got %v", ids)
+	}
+
+	if err := stream.Err(); err != nil {
+		t.Errorf("Unexpected error after break: %v", err)
+	}
+
+	for range stream.All() {
+		t.Fatal("Expected a stream to be ranged over only once")
+	}
+}
+
+func TestStreamArrayNotAnArray(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Object", `{"a": 1}`, "Line 1, Column 1: expected [, got {"},
+		{"Empty", ``, "Line 1, Column 0: unexpected end of input: empty document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.StreamArray(strings.NewReader(tt.input))
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
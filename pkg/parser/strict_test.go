@@ -0,0 +1,99 @@
+package parser_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+// TestStrictRFC8259Corpus parses the files of testdata/rfc8259, named like those of the
+// JSONTestSuite: the y_ files must be accepted and the n_ files rejected. The corpus is a
+// hand-written subset of the suite, not a copy of its files, and leaves out its i_ cases, which
+// TestStrictRFC8259 covers where this package has chosen an outcome.
+func TestStrictRFC8259Corpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "rfc8259", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected corpus files, got %v", err)
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, err = parser.Parse(string(data), parser.WithStrictRFC8259())
+
+			switch {
+			case strings.HasPrefix(name, "y_") && err != nil:
+				t.Errorf("Expected %q to be accepted, got %v", data, err)
+			case strings.HasPrefix(name, "n_") && err == nil:
+				t.Errorf("Expected %q to be rejected", data)
+			}
+		})
+	}
+}
+
+func TestStrictRFC8259(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Trailing document", `{"a": 1} {}`, "Line 1, Column 10: unexpected token { after the document"},
+		{"Trailing scalar", `[1] 2`, "Line 1, Column 5: unexpected token NUMBER after the document"},
+		{"Control character", "{\"a\": \"x\ty\"}", "Line 1, Column 7: string holds an unescaped control character"},
+		{"Control character in key", "{\"a\nb\": 1}", "Line 1, Column 2: string holds an unescaped control character"},
+		{"Invalid UTF-8", "[\"ab\xffc\"]", "Line 1, Column 5: invalid UTF-8 byte 0xff at byte offset 4"},
+		{"NUL byte", "[1]\x00", "Line 1, Column 4: unexpected token ILLEGAL after the document"},
+		{"Byte order mark", "\ufeff[]", "Line 1, Column 1: input starts with a UTF-8 byte order mark, which JSON text must not begin with"},
+		{"Unterminated string root", `"abc`, "Line 1, Column 1: unterminated string: the input ends before its closing quote"},
+		{"Closing bracket root", `]`, "Line 1, Column 1: expected a value, got ]"},
+		{"Invalid number root", `01`, "Line 1, Column 1: expected a value, got ILLEGAL"},
+		{"Out of range number root", `1e400`, "Line 1, Column 1: invalid number format: 1e400"},
+		{"Huge exponent", `[1.5e+9999]`, "Line 1, Column 2: invalid number format: 1.5e+9999"},
+		{"Huge exponent with zeros", `[0.4e00669999999999]`, "Line 1, Column 2: invalid number format: 0.4e00669999999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.input, parser.WithStrictRFC8259())
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	v, err := parser.Parse(` "text" `, parser.WithStrictRFC8259())
+	if s, ok := v.(*parser.StringLiteral); err != nil || !ok || s.Value != "text" {
+		t.Errorf("Expected a string document, got %v, %v", v, err)
+	}
+
+	v, err = parser.Parse(`[-1e-9999]`, parser.WithStrictRFC8259())
+	if err != nil || v.(*parser.Array).Elements[0].(*parser.NumberLiteral).Float != 0 {
+		t.Errorf("Expected a tiny number to be read as zero, got %v, %v", v, err)
+	}
+
+	var encErr *parser.EncodingError
+	if _, err := parser.Parse("{\"a\": \"\xc3\"}", parser.WithStrictRFC8259()); !errors.As(err, &encErr) {
+		t.Errorf("Expected an EncodingError for invalid UTF-8, got %v", err)
+	}
+
+	// Without the option the same texts are accepted as before
+	for _, input := range []string{`{"a": 1} {}`, "[\"x\ty\"]", "[\"ab\xffc\"]"} {
+		if _, err := parser.Parse(input); err != nil {
+			t.Errorf("Expected %q to be accepted without StrictRFC8259, got %v", input, err)
+		}
+	}
+
+	if _, err := parser.Parse(`42`); err == nil {
+		t.Error("Expected a scalar document to be rejected without StrictRFC8259")
+	}
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ellipsis marks the point where Summary cut its output.
+const ellipsis = "…"
+
+// Summary renders v as compact single-line JSON of at most maxLen runes, suitable for logging
+// payload previews. When the full rendering would be longer, it is cut short and ends with "…",
+// which counts towards maxLen. Unlike String, the length is bounded, and rendering stops as soon
+// as the limit is reached, so huge strings and deeply nested trees are never rendered in full.
+// Object members are written in sorted key order. A maxLen of zero or less yields "".
+func Summary(v Value, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+
+	w := &summaryWriter{limit: maxLen}
+	w.value(v)
+
+	if w.runes <= maxLen {
+		return w.b.String()
+	}
+
+	// Keep maxLen-1 runes and append the ellipsis
+	s := w.b.String()
+	cut := 0
+
+	for i := 0; i < maxLen-1; i++ {
+		_, size := utf8.DecodeRuneInString(s[cut:])
+		cut += size
+	}
+
+	return s[:cut] + ellipsis
+}
+
+// summaryWriter renders compact JSON and stops once more than limit runes have been written.
+type summaryWriter struct {
+	b     strings.Builder
+	runes int
+	limit int
+}
+
+// full reports whether the output already exceeds the limit.
+func (w *summaryWriter) full() bool {
+	return w.runes > w.limit
+}
+
+// write appends s to the output.
+func (w *summaryWriter) write(s string) {
+	w.b.WriteString(s)
+	w.runes += utf8.RuneCountInString(s)
+}
+
+// str appends s as a quoted JSON string, escaping at most as much of it as can be shown.
+func (w *summaryWriter) str(s string) {
+	if n := w.limit - w.runes + 1; utf8.RuneCountInString(s) > n {
+		cut := 0
+		for i := 0; i < n; i++ {
+			_, size := utf8.DecodeRuneInString(s[cut:])
+			cut += size
+		}
+
+		s = s[:cut]
+	}
+
+	var b encodeBuffer
+
+	_ = writeString(&b, s, &MarshalOptions{NoEscapeHTML: true})
+	w.write(string(b.buf))
+}
+
+// value appends the JSON text of v to the output.
+func (w *summaryWriter) value(v Value) {
+	if w.full() {
+		return
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		w.write("{")
+
+		for i, k := range val.SortedKeys() {
+			if w.full() {
+				return
+			}
+
+			if i > 0 {
+				w.write(",")
+			}
+
+			w.str(k)
+			w.write(":")
+			w.value(val.Pairs[k])
+		}
+
+		w.write("}")
+
+	case *Array:
+		w.write("[")
+
+		for i, elem := range val.Elements {
+			if w.full() {
+				return
+			}
+
+			if i > 0 {
+				w.write(",")
+			}
+
+			w.value(elem)
+		}
+
+		w.write("]")
+
+	case *StringLiteral:
+		w.str(val.Value)
+
+	case *NumberLiteral:
+		w.write(val.Value)
+
+	case *Boolean:
+		w.write(val.String())
+
+	case *Null:
+		w.write("null")
+	}
+}
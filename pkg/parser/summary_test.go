@@ -0,0 +1,46 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestSummary(t *testing.T) {
+	value := mustParse(t, `{"user": {"name": "Ana", "roles": ["admin", "dev"]}, "id": 7}`)
+
+	tests := []struct {
+		name     string
+		maxLen   int
+		expected string
+	}{
+		{"Fits", 100, `{"id":7,"user":{"name":"Ana","roles":["admin","dev"]}}`},
+		{"Exact length", 54, `{"id":7,"user":{"name":"Ana","roles":["admin","dev"]}}`},
+		{"Truncated", 20, `{"id":7,"user":{"na…`},
+		{"Single rune", 1, `…`},
+		{"Disabled", 0, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.Summary(value, tt.maxLen); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummaryHugeString(t *testing.T) {
+	value := &parser.StringLiteral{Value: strings.Repeat("é", 1<<20)}
+
+	got := parser.Summary(value, 10)
+	if n := utf8.RuneCountInString(got); n != 10 {
+		t.Errorf("Expected 10 runes, got %d: %s", n, got)
+	}
+
+	if expected := `"éééééééé` + "…"; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
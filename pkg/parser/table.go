@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ToTable lays out a, an array of objects such as the records of an API response, as a table
+// for writing with encoding/csv or handing to other tabular tools: every element is a row, and
+// the headers are the union of the keys of all the elements, in sorted order, so each row holds
+// one cell per header and a row missing a key has an empty cell for it.
+//
+// A string cell holds the string itself, without quotes or escapes, and a null cell is empty,
+// like a missing key. Any other value is written as compact JSON, as with MarshalWith and zero
+// MarshalOptions: a number keeps its original literal, a boolean is true or false, and a nested
+// object or array is serialized whole, as in {"a":1}, so that no data is lost. Nothing is
+// coerced, so the string "1" and the number 1 both give the cell 1. It returns an error when an
+// element is not an object or a cell cannot be marshaled, and an empty table for a nil array.
+func ToTable(a *Array) (headers []string, rows [][]string, err error) {
+	headers, rows = []string{}, [][]string{}
+	if a == nil {
+		return headers, rows, nil
+	}
+
+	seen := make(map[string]bool)
+
+	for i, elem := range a.Elements {
+		obj, ok := elem.(*Object)
+		if !ok {
+			return nil, nil, fmt.Errorf("row %d holds %s, not an object", i, aTypeOf(elem))
+		}
+
+		for k := range obj.Pairs {
+			if !seen[k] {
+				seen[k] = true
+				headers = append(headers, k)
+			}
+		}
+	}
+
+	sort.Strings(headers)
+
+	for i, elem := range a.Elements {
+		obj := elem.(*Object)
+
+		row := make([]string, len(headers))
+		for j, k := range headers {
+			if row[j], err = tableCell(obj.Pairs[k]); err != nil {
+				return nil, nil, fmt.Errorf("row %d, column %q: %w", i, k, err)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return headers, rows, nil
+}
+
+// tableCell returns the cell ToTable writes for v, where a nil v stands for a missing key.
+func tableCell(v Value) (string, error) {
+	switch val := v.(type) {
+	case nil, *Null:
+		return "", nil
+	case *StringLiteral:
+		return val.Value, nil
+	default:
+		data, err := MarshalWith(v, MarshalOptions{NoEscapeHTML: true})
+		if err != nil {
+			return "", err
+		}
+
+		return string(data), nil
+	}
+}
@@ -0,0 +1,58 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestToTable(t *testing.T) {
+	arr := mustParse(t, `[
+		{"id": 1, "name": "Ana, \"A\"", "tags": ["x", "y"]},
+		{"id": 2.50, "active": true, "meta": {"a": 1}},
+		{"name": null, "id": "3"}
+	]`).(*parser.Array)
+
+	headers, rows, err := parser.ToTable(arr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := []string{"active", "id", "meta", "name", "tags"}; !reflect.DeepEqual(headers, expected) {
+		t.Errorf("Expected headers %q, got %q", expected, headers)
+	}
+
+	expected := [][]string{
+		{"", "1", "", `Ana, "A"`, `["x","y"]`},
+		{"true", "2.50", `{"a":1}`, "", ""},
+		{"", "3", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected rows %q, got %q", expected, rows)
+	}
+
+	headers, rows, err = parser.ToTable(nil)
+	if err != nil || len(headers) != 0 || len(rows) != 0 || headers == nil || rows == nil {
+		t.Errorf("Expected an empty table for a nil array, got %q, %q, %v", headers, rows, err)
+	}
+
+	errorTests := []struct {
+		name     string
+		input    *parser.Array
+		expected string
+	}{
+		{"Not an object", mustParse(t, `[{"a": 1}, [1]]`).(*parser.Array), "row 1 holds an array, not an object"},
+		{"Invalid cell", &parser.Array{Elements: []parser.Value{
+			&parser.Object{Pairs: map[string]parser.Value{"n": &parser.NumberLiteral{Value: "1x"}}},
+		}}, `row 0, column "n": invalid number: "1x"`},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parser.ToTable(tt.input); err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ const (
 	TokenTrue         TokenType = "TRUE"
 	TokenFalse        TokenType = "FALSE"
 	TokenNull         TokenType = "NULL"
+	TokenWord         TokenType = "WORD"
 	TokenEOF          TokenType = "EOF"
 	TokenIllegal      TokenType = "ILLEGAL"
 )
@@ -28,4 +29,10 @@ type Token struct {
 	Literal string
 	Line    int
 	Column  int
+	// Comments holds the comments that precede the token. It is only populated when the lexer
+	// retains comments.
+	Comments []string
+	// start and end are the byte offsets of the token in the input. They are only recorded when
+	// the lexer preserves the source.
+	start, end int
 }
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// TruncateStrings returns a copy of the tree rooted at v in which every string value longer than
+// maxLen bytes is cut to at most maxLen bytes, without splitting a character, and followed by an
+// ellipsis and its original length, as in "abcd…(4096 bytes)". This bounds the size of payloads
+// written to logs, such as requests embedding base64 blobs, while keeping their shape. Keys are
+// never truncated, and v itself is left untouched. A maxLen of zero or less keeps only the marker.
+func TruncateStrings(v Value, maxLen int) Value {
+	result := clone(v)
+	truncateStrings(result, maxLen)
+
+	return result
+}
+
+// truncateStrings truncates the strings of the copied tree rooted at v in place for
+// TruncateStrings.
+func truncateStrings(v Value, maxLen int) {
+	switch val := v.(type) {
+	case *Object:
+		for _, child := range val.Pairs {
+			truncateStrings(child, maxLen)
+		}
+
+	case *Array:
+		for _, elem := range val.Elements {
+			truncateStrings(elem, maxLen)
+		}
+
+	case *StringLiteral:
+		val.Value = truncateString(val.Value, maxLen)
+		val.Token.Literal = val.Value
+	}
+}
+
+// truncateString shortens s for TruncateStrings when it is longer than maxLen bytes.
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+
+	cut := max(maxLen, 0)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut] + "…(" + strconv.Itoa(len(s)) + " bytes)"
+}
+
+// TruncateArrays returns a copy of the tree rooted at v in which every array longer than maxLen
+// elements keeps only its first maxLen elements, followed by a string marker counting the ones
+// dropped, as in [1,2,"…(98 more)"]. Like TruncateStrings, which it pairs with for sanitizing
+// payloads before logging them, it keeps the shape of the output bounded and shows that something
+// was omitted. Arrays nested at any depth are truncated too, including those inside the elements
+// that are kept, and v itself is left untouched. A maxLen of zero or less keeps only the marker.
+func TruncateArrays(v Value, maxLen int) Value {
+	result := clone(v)
+	truncateArrays(result, max(maxLen, 0))
+
+	return result
+}
+
+// truncateArrays truncates the arrays of the copied tree rooted at v in place for TruncateArrays.
+func truncateArrays(v Value, maxLen int) {
+	switch val := v.(type) {
+	case *Object:
+		for _, child := range val.Pairs {
+			truncateArrays(child, maxLen)
+		}
+
+	case *Array:
+		if dropped := len(val.Elements) - maxLen; dropped > 0 {
+			val.Elements = append(val.Elements[:maxLen], &StringLiteral{Value: "…(" + strconv.Itoa(dropped) + " more)"})
+		}
+
+		for _, elem := range val.Elements {
+			truncateArrays(elem, maxLen)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestTruncateStrings(t *testing.T) {
+	blob := strings.Repeat("QUJD", 1024)
+
+	tests := []struct {
+		name     string
+		input    string
+		maxLen   int
+		expected string
+	}{
+		{"Long value", `{"blob": "` + blob + `", "id": 7}`, 4, `{"blob":"QUJD…(4096 bytes)","id":7}`},
+		{"Short values kept", `["abc", "abcd", true, null, 1.50]`, 4, `["abc","abcd",true,null,1.50]`},
+		{"Nested", `{"a": [{"b": "abcdefgh"}]}`, 2, `{"a":[{"b":"ab…(8 bytes)"}]}`},
+		{"Keys kept", `{"abcdefgh": "x"}`, 2, `{"abcdefgh":"x"}`},
+		{"Character boundary", `["héllo"]`, 2, `["h…(6 bytes)"]`},
+		{"Zero", `["abc"]`, 0, `["…(3 bytes)"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := mustParse(t, tt.input)
+
+			got, err := parser.MarshalWith(parser.TruncateStrings(original, tt.maxLen), parser.MarshalOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if !parser.Equal(original, mustParse(t, tt.input)) {
+				t.Error("Expected the original tree to be untouched")
+			}
+		})
+	}
+
+	// The token is cut along with the value, so the full text does not stay in the tree
+	truncated := parser.TruncateStrings(mustParse(t, `{"blob": "`+blob+`"}`), 4).(*parser.Object).Pairs["blob"]
+	if got := truncated.TokenLiteral(); got != "QUJD…(4096 bytes)" {
+		t.Errorf("Expected the token literal to be truncated, got %.20s", got)
+	}
+}
+
+func TestTruncateArrays(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxLen   int
+		expected string
+	}{
+		{"Long array", `{"ids": [1, 2, 3, 4, 5], "n": 5}`, 2, `{"ids":[1,2,"…(3 more)"],"n":5}`},
+		{"Short arrays kept", `[[1, 2], []]`, 2, `[[1,2],[]]`},
+		{"Nested", `[{"a": [1, 2, 3]}, [4, 5, 6], 7]`, 2, `[{"a":[1,2,"…(1 more)"]},[4,5,"…(1 more)"],"…(1 more)"]`},
+		{"Zero", `["a", "b"]`, 0, `["…(2 more)"]`},
+		{"Negative", `["a"]`, -1, `["…(1 more)"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := mustParse(t, tt.input)
+
+			got, err := parser.MarshalWith(parser.TruncateArrays(original, tt.maxLen), parser.MarshalOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+
+			if !parser.Equal(original, mustParse(t, tt.input)) {
+				t.Error("Expected the original tree to be untouched")
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseTyped parses input like Parse and then coerces its values to the types that schema, a JSON
+// Schema such as the one InferSchema returns, declares for them, so that messy data, such as an
+// API that sends some numbers as strings, reaches the shape its consumer expects in one step. It
+// returns the coerced tree along with a *CoercionError for every value that was coerced or could
+// not be, in key order, or nil when there is none. When input does not parse, it returns nil
+// and the *ParseError alone.
+//
+// Only the "type", "properties", "additionalProperties" and "items" keywords are read, "items"
+// holding either one schema for every element or an array of schemas, one per index. A "type"
+// may name one type or list several, and a value of any of them is left as it is. Otherwise the
+// types are tried in the order listed, and the first coercion that succeeds is applied:
+//
+//   - to "number", a string holding a valid JSON number literal, so "42" and "-1.5" but not
+//     " 42" or "0x1F", as AsNumber reads them;
+//   - to "integer", such a string when its number is integral, so "42" and "1e3" but not "1.5";
+//     a number with a fraction is never rounded;
+//   - to "string", a number, written with its literal, or a boolean, written true or false;
+//   - to "boolean", the strings "true" and "false" and the numbers 0 and 1.
+//
+// A value that matches none of the types and that none of these coercions applies to, such as an
+// object where a string is expected, a string where an array is, or any value where null is, is
+// kept as it is and reported as not coerced. The members and elements of a container are coerced
+// only when the container itself is of the type the schema declares, or when the schema declares
+// none. Keys without a schema, and parts of the schema that are not objects, such as the boolean
+// schema true, leave values unchanged. Keywords such as "$ref", "anyOf" and "format" are ignored.
+func ParseTyped(input string, schema Value) (Value, []error) {
+	v, err := Parse(input)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var errs []error
+
+	v = coerceTyped(v, schema, "", &errs)
+
+	return v, errs
+}
+
+// coerceTyped coerces v, found at pointer, and its contents in place to the types schema
+// declares for ParseTyped, appending a *CoercionError to errs for every value coerced or not.
+func coerceTyped(v Value, schema Value, pointer string, errs *[]error) Value {
+	s, ok := schema.(*Object)
+	if !ok {
+		return v
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !matchesType(v, types) {
+		coerced := false
+
+		for _, t := range types {
+			if c, ok := coerceToType(v, t); ok {
+				*errs = append(*errs, &CoercionError{Path: pointer, Value: v, Type: t, Coerced: true})
+				v, coerced = c, true
+
+				break
+			}
+		}
+
+		if !coerced {
+			*errs = append(*errs, &CoercionError{Path: pointer, Value: v, Type: strings.Join(types, " or ")})
+		}
+
+		return v
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		properties, _ := s.Pairs["properties"].(*Object)
+
+		for _, k := range val.SortedKeys() {
+			child, ok := s.Pairs["additionalProperties"]
+			if properties != nil {
+				if property, found := properties.Pairs[k]; found {
+					child, ok = property, true
+				}
+			}
+
+			if ok {
+				val.Pairs[k] = coerceTyped(val.Pairs[k], child, pointer+"/"+escapePointerToken(k), errs)
+			}
+		}
+
+	case *Array:
+		for i, elem := range val.Elements {
+			items := s.Pairs["items"]
+			if tuple, ok := items.(*Array); ok {
+				items = nil
+				if i < len(tuple.Elements) {
+					items = tuple.Elements[i]
+				}
+			}
+
+			val.Elements[i] = coerceTyped(elem, items, pointer+"/"+strconv.Itoa(i), errs)
+		}
+	}
+
+	return v
+}
+
+// schemaTypes returns the types named by the "type" keyword of schema, which holds either one
+// type name or an array of them.
+func schemaTypes(schema *Object) []string {
+	switch t := schema.Pairs["type"].(type) {
+	case *StringLiteral:
+		return []string{t.Value}
+	case *Array:
+		var types []string
+
+		for _, elem := range t.Elements {
+			if s, ok := elem.(*StringLiteral); ok {
+				types = append(types, s.Value)
+			}
+		}
+
+		return types
+	default:
+		return nil
+	}
+}
+
+// matchesType reports whether v is of one of types. As in JSON Schema, a number is an integer
+// when it has no fraction, whether or not it is written with one, like 1.0.
+func matchesType(v Value, types []string) bool {
+	for _, t := range types {
+		switch n, isNumber := v.(*NumberLiteral); {
+		case t == "integer" && isNumber:
+			if n.IsInt || n.Overflowed || n.Float == math.Trunc(n.Float) && !math.IsInf(n.Float, 0) {
+				return true
+			}
+		case t == string(outlineType(v)):
+			return true
+		}
+	}
+
+	return false
+}
+
+// coerceToType returns v converted to the JSON Schema type t for ParseTyped, reporting false when
+// it cannot be.
+func coerceToType(v Value, t string) (Value, bool) {
+	switch t {
+	case "number", "integer":
+		s, ok := v.(*StringLiteral)
+		if !ok {
+			return nil, false
+		}
+
+		if _, ok := AsNumber(s); !ok {
+			return nil, false
+		}
+
+		n := NewNumberLiteral(Token{Type: TokenNumber, Literal: s.Value})
+		if t == "integer" && !matchesType(n, []string{t}) {
+			return nil, false
+		}
+
+		return n, true
+
+	case "string":
+		var literal string
+
+		switch val := v.(type) {
+		case *NumberLiteral:
+			if !val.IsValidNumber() {
+				return nil, false
+			}
+
+			literal = val.Value
+		case *Boolean:
+			literal = strconv.FormatBool(val.Value)
+		default:
+			return nil, false
+		}
+
+		return &StringLiteral{Token: Token{Type: TokenString, Literal: literal}, Value: literal}, true
+
+	case "boolean":
+		var b bool
+
+		switch val := v.(type) {
+		case *StringLiteral:
+			if val.Value != "true" && val.Value != "false" {
+				return nil, false
+			}
+
+			b = val.Value == "true"
+		case *NumberLiteral:
+			if !val.IsValidNumber() || val.Float != 0 && val.Float != 1 {
+				return nil, false
+			}
+
+			b = val.Float == 1
+		default:
+			return nil, false
+		}
+
+		token := Token{Type: TokenFalse, Literal: "false"}
+		if b {
+			token = Token{Type: TokenTrue, Literal: "true"}
+		}
+
+		return &Boolean{Token: token, Value: b}, true
+
+	default:
+		return nil, false
+	}
+}
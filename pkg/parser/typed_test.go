@@ -0,0 +1,87 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestParseTyped(t *testing.T) {
+	schema := mustParse(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"price": {"type": "number"},
+			"name": {"type": "string"},
+			"active": {"type": "boolean"},
+			"note": {"type": ["string", "null"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"point": {"type": "array", "items": [{"type": "number"}, {"type": "integer"}]}
+		},
+		"additionalProperties": {"type": "number"}
+	}`)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		errors   []string
+	}{
+		{"Already typed", `{"id": 1.0, "price": 2, "name": "a", "active": true, "note": null, "tags": []}`,
+			`{"id": 1.0, "price": 2, "name": "a", "active": true, "note": null, "tags": []}`, nil},
+		{"Coerced", `{"id": "42", "price": "-1.5e1", "name": 7.50, "active": "false", "note": true, "tags": [1, false], "extra": "3"}`,
+			`{"id": 42, "price": -1.5e1, "name": "7.50", "active": false, "note": "true", "tags": ["1", "false"], "extra": 3}`,
+			[]string{
+				`string "false" at "/active" was coerced to boolean`,
+				`string "3" at "/extra" was coerced to number`,
+				`string "42" at "/id" was coerced to integer`,
+				`number 7.50 at "/name" was coerced to string`,
+				`boolean true at "/note" was coerced to string`,
+				`string "-1.5e1" at "/price" was coerced to number`,
+				`number 1 at "/tags/0" was coerced to string`,
+				`boolean false at "/tags/1" was coerced to string`,
+			}},
+		{"Given up", `{"id": "1.5", "price": " 42", "active": "yes", "note": {}, "tags": "a", "point": ["1", 2.5, "x"]}`,
+			`{"id": "1.5", "price": " 42", "active": "yes", "note": {}, "tags": "a", "point": [1, 2.5, "x"]}`,
+			[]string{
+				`string "yes" at "/active" cannot be coerced to boolean`,
+				`string "1.5" at "/id" cannot be coerced to integer`,
+				`object at "/note" cannot be coerced to string or null`,
+				`string "1" at "/point/0" was coerced to number`,
+				`number 2.5 at "/point/1" cannot be coerced to integer`,
+				`string " 42" at "/price" cannot be coerced to number`,
+				`string "a" at "/tags" cannot be coerced to array`,
+			}},
+		{"Booleans from numbers", `{"active": 1}`, `{"active": true}`, []string{`number 1 at "/active" was coerced to boolean`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := parser.ParseTyped(tt.input, schema)
+			if !parser.Equal(got, mustParse(t, tt.expected)) {
+				data, _ := parser.Marshal(got)
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+
+			if !reflect.DeepEqual(messages, tt.errors) {
+				t.Errorf("Expected warnings %q, got %q", tt.errors, messages)
+			}
+		})
+	}
+
+	if got, errs := parser.ParseTyped(`{"a": `, schema); got != nil || len(errs) != 1 {
+		t.Errorf("Expected the parse error alone, got %v, %v", got, errs)
+	}
+
+	// A value that is coerced keeps the type of its coercion
+	got, _ := parser.ParseTyped(`["12"]`, mustParse(t, `{"items": {"type": ["boolean", "integer"]}}`))
+	if n, ok := got.(*parser.Array).Elements[0].(*parser.NumberLiteral); !ok || !n.IsInt || n.Int != 12 {
+		t.Errorf("Expected the integer 12, got %#v", got)
+	}
+}
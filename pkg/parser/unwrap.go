@@ -0,0 +1,34 @@
+package parser
+
+// Unwrap returns a copy of the tree rooted at v in which every array holding exactly one element
+// is replaced by that element, recursively, so that {"items": ["x"]} becomes {"items": "x"}. This
+// normalizes documents from converters, such as legacy XML-to-JSON tools, that wrap single values
+// in arrays only some of the time. Nested arrays collapse from the inside out, so [["x"]] becomes
+// "x". The transform is lossy: a genuine one-element list cannot be told apart from a single value
+// afterwards, so it should only be applied to documents known to have this quirk. Empty arrays
+// and arrays of two or more elements are kept. v itself is left untouched.
+func Unwrap(v Value) Value {
+	return unwrap(clone(v))
+}
+
+// unwrap collapses the single-element arrays of the copied tree rooted at v in place for Unwrap,
+// returning the value to store in place of v.
+func unwrap(v Value) Value {
+	switch val := v.(type) {
+	case *Object:
+		for k, child := range val.Pairs {
+			val.Pairs[k] = unwrap(child)
+		}
+
+	case *Array:
+		if len(val.Elements) == 1 {
+			return unwrap(val.Elements[0])
+		}
+
+		for i, elem := range val.Elements {
+			val.Elements[i] = unwrap(elem)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,37 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func TestUnwrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Single value", `{"items": ["x"]}`, `{"items": "x"}`},
+		{"Several values kept", `{"items": ["x", "y"]}`, `{"items": ["x", "y"]}`},
+		{"Empty kept", `{"items": []}`, `{"items": []}`},
+		{"Nested arrays", `{"a": [["x"]]}`, `{"a": "x"}`},
+		{"Inside elements", `{"rows": [{"id": [1]}, {"id": [2, 3]}]}`, `{"rows": [{"id": 1}, {"id": [2, 3]}]}`},
+		{"Wrapped object", `{"user": [{"name": ["Ada"]}]}`, `{"user": {"name": "Ada"}}`},
+		{"Root", `[{"a": 1}]`, `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := mustParse(t, tt.input)
+
+			if got := parser.Unwrap(original); !parser.Equal(got, mustParse(t, tt.expected)) {
+				t.Errorf("Expected %s, got %#v", tt.expected, got)
+			}
+
+			if !parser.Equal(original, mustParse(t, tt.input)) {
+				t.Error("Expected the original tree to be untouched")
+			}
+		})
+	}
+}
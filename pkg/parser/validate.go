@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+)
+
+// ValidateStream checks that r holds exactly one well-formed JSON document and returns the first
+// error found, with its line and column, or nil when the document is valid. It streams through
+// the input without building an AST, so memory use does not grow with the size of the document,
+// only with its nesting depth and its longest token. Content after the document is reported as
+// an error, and so is a failure reading r.
+func ValidateStream(r io.Reader) *ParseError {
+	lexer := NewLexer(r)
+
+	p := NewParser(lexer)
+	p.start()
+
+	switch p.currentToken.Type {
+	case TokenBraceOpen, TokenBracketOpen:
+		if p.skipValue() && p.peekToken.Type != TokenEOF {
+			p.nextToken()
+			p.addError("unexpected token %s after the document", p.currentToken.Type)
+		}
+	case TokenEOF:
+		p.addError("unexpected end of input: empty document")
+	default:
+		p.addError("expected { or [, got %s", p.currentToken.Type)
+	}
+
+	if lexer.readErr != nil {
+		return &ParseError{
+			Line:    p.currentToken.Line,
+			Column:  p.currentToken.Column,
+			Message: "reading input: " + lexer.readErr.Error(),
+		}
+	}
+
+	if p.failed() {
+		return p.errors[0]
+	}
+
+	return nil
+}
+
+// CountRecords returns the number of records in r, a stream of JSON values such as a JSON Lines
+// file, for cheap statistics on files too large to parse. Each record must be an object or an
+// array, like a record of TransformStream, and the records may be separated by any whitespace,
+// newlines or none included. Records are validated like ValidateStream does, without building
+// an AST, so memory use does not grow with the size of the input. A malformed record stops the
+// count with an error that wraps its ParseError with the index of the record, counting from zero;
+// the count returned is then that of the records before it. A failure reading r is returned
+// along with the number of records read completely.
+func CountRecords(r io.Reader) (int, error) {
+	lexer := NewLexer(r)
+
+	p := NewParser(lexer)
+	p.start()
+
+	count := 0
+
+	for p.currentToken.Type != TokenEOF {
+		switch p.currentToken.Type {
+		case TokenBraceOpen, TokenBracketOpen:
+			if p.skipValue() {
+				count++
+				p.nextToken() // move to the next record
+			}
+		default:
+			p.addError("expected { or [, got %s", p.currentToken.Type)
+		}
+
+		if lexer.readErr != nil {
+			return count, fmt.Errorf("reading input: %w", lexer.readErr)
+		}
+
+		if p.failed() {
+			return count, fmt.Errorf("record %d: %w", count, p.errors[0])
+		}
+	}
+
+	if lexer.readErr != nil {
+		return count, fmt.Errorf("reading input: %w", lexer.readErr)
+	}
+
+	return count, nil
+}
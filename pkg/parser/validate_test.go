@@ -0,0 +1,126 @@
+package parser_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+// recordsReader lazily produces a large JSON array of records followed by tail, without ever
+// holding the whole document in memory.
+type recordsReader struct {
+	remaining int
+	started   bool
+	tail      *strings.Reader
+	pending   string
+}
+
+func (r *recordsReader) Read(b []byte) (int, error) {
+	for r.pending == "" {
+		switch {
+		case !r.started:
+			r.started = true
+			r.pending = "["
+		case r.remaining > 0:
+			r.remaining--
+			r.pending = `{"id": 12345, "name": "record", "tags": ["a", "b"], "ok": true},` + "\n"
+		default:
+			return r.tail.Read(b)
+		}
+	}
+
+	n := copy(b, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+func TestValidateStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   io.Reader
+		expected string
+	}{
+		{"Valid", strings.NewReader(`{"a": [1, 2, {"b": null}]}`), ""},
+		{"Large valid stream", &recordsReader{remaining: 200000, tail: strings.NewReader(`null]`)}, ""},
+		{"Large stream with an error", &recordsReader{remaining: 200000, tail: strings.NewReader(`nul]`)}, "Line 200001, Column 1: expected string key"},
+		{"Missing comma", strings.NewReader("{\n  \"a\": 1\n  \"b\": 2\n}"), "Line 3, Column 3: expected , between members, got STRING"},
+		{"Truncated", strings.NewReader("{\"a\": [1, {\"b\": 2"), "Line 1, Column 17: expected }, got EOF: the object opened at line 1, column 11 is not closed"},
+		{"Truncated after a key", strings.NewReader(`[{"a"`), "Line 1, Column 5: expected }, got EOF: the object opened at line 1, column 2 is not closed"},
+		{"Trailing content", strings.NewReader(`{} []`), "Line 1, Column 4: unexpected token [ after the document"},
+		{"Empty", strings.NewReader(""), "Line 1, Column 0: unexpected end of input: empty document"},
+		{"Read error", iotest.ErrReader(errors.New("disk failure")), "Line 1, Column 0: reading input: disk failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parser.ValidateStream(tt.reader)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCountRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   io.Reader
+		count    int
+		expected string
+	}{
+		{"JSON Lines", strings.NewReader("{\"a\": 1}\n[1, 2]\n\n{\"b\": {\"c\": []}}\n"), 3, ""},
+		{"Concatenated", strings.NewReader(`{}[]{"a":[{}]}`), 3, ""},
+		{"Empty", strings.NewReader(" \n"), 0, ""},
+		{"Malformed record", strings.NewReader("{\"a\": 1}\n{\"a\": 2}\n{\"a\" 3}\n{}\n"), 2,
+			`record 2: Line 3, Column 6: expected : after key "a", got NUMBER`},
+		{"Scalar record", strings.NewReader("{}\n42\n"), 1, "record 1: Line 2, Column 1: expected { or [, got NUMBER"},
+		{"Truncated", strings.NewReader("{}\n{\"a\": ["), 1,
+			"record 1: Line 2, Column 7: expected ], got EOF: the array opened at line 2, column 7 is not closed"},
+		{"Read error", io.MultiReader(strings.NewReader("{}\n[]\n"), iotest.ErrReader(errors.New("disk failure"))), 2,
+			"reading input: disk failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := parser.CountRecords(tt.reader)
+			if count != tt.count {
+				t.Errorf("Expected %d records, got %d", tt.count, count)
+			}
+
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	records := strings.NewReader(strings.Repeat(`{"id": 12345, "tags": ["a", "b"], "ok": true}`+"\n", 100000))
+	if count, err := parser.CountRecords(records); err != nil || count != 100000 {
+		t.Errorf("Expected 100000 records, got %d and %v", count, err)
+	}
+
+	var perr *parser.ParseError
+	if _, err := parser.CountRecords(strings.NewReader(`{} {`)); !errors.As(err, &perr) {
+		t.Errorf("Expected the error to wrap a ParseError, got %v", err)
+	}
+}
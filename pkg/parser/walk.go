@@ -0,0 +1,221 @@
+package parser
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WalkFunc is the type of the function called by Walk for each value in a tree. The pointer is
+// the RFC 6901 JSON Pointer of the value relative to the root, which is the empty string for the
+// root itself.
+//
+// If the function returns SkipChildren while visiting an object or array, Walk does not descend
+// into it. Any other non-nil error stops the walk and is returned by Walk.
+type WalkFunc func(pointer string, v Value) error
+
+// SkipChildren is used as a return value from a WalkFunc to indicate that the children of the
+// current container are to be skipped. It is not returned as an error by Walk.
+var SkipChildren = errors.New("skip children")
+
+// Walk traverses the tree rooted at v in depth-first pre-order, calling fn for every value,
+// containers included. Object members are visited in sorted key order and array elements in
+// index order.
+func Walk(v Value, fn WalkFunc) error {
+	err := walk("", v, fn)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+
+	return err
+}
+
+// walk visits v and its children.
+func walk(pointer string, v Value, fn WalkFunc) error {
+	if err := fn(pointer, v); err != nil {
+		return err
+	}
+
+	switch val := v.(type) {
+	case *Object:
+		for _, k := range val.SortedKeys() {
+			if err := walkChild(pointer+"/"+escapePointerToken(k), val.Pairs[k], fn); err != nil {
+				return err
+			}
+		}
+
+	case *Array:
+		for i, elem := range val.Elements {
+			if err := walkChild(pointer+"/"+strconv.Itoa(i), elem, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkChild visits a child value, swallowing a SkipChildren returned for it.
+func walkChild(pointer string, v Value, fn WalkFunc) error {
+	if err := walk(pointer, v, fn); err != nil && !errors.Is(err, SkipChildren) {
+		return err
+	}
+
+	return nil
+}
+
+// escapePointerToken escapes a reference token for use in a JSON Pointer, as defined by RFC 6901.
+func escapePointerToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// Strings returns every string value reachable from v, in the order Walk visits them. When
+// includeKeys is true, the keys of each object are included too, in sorted order, right before
+// the strings found in the object's values. This is convenient for full-text indexing.
+func Strings(v Value, includeKeys bool) []string {
+	var result []string
+
+	_ = Walk(v, func(_ string, v Value) error {
+		switch val := v.(type) {
+		case *StringLiteral:
+			result = append(result, val.Value)
+		case *Object:
+			if includeKeys {
+				result = append(result, val.SortedKeys()...)
+			}
+		}
+
+		return nil
+	})
+
+	return result
+}
+
+// CountKey returns how many objects anywhere in the tree rooted at v contain key.
+func CountKey(v Value, key string) int {
+	return len(CollectKey(v, key))
+}
+
+// CollectKey returns the values stored under key in every object of the tree rooted at v, in the
+// order Walk visits the objects.
+func CollectKey(v Value, key string) []Value {
+	var result []Value
+
+	_ = Walk(v, func(_ string, v Value) error {
+		if obj, ok := v.(*Object); ok {
+			if value, ok := obj.Pairs[key]; ok {
+				result = append(result, value)
+			}
+		}
+
+		return nil
+	})
+
+	return result
+}
+
+// KeyPaths returns every distinct dotted key path leading to a leaf of the tree rooted at v, in
+// sorted order, with array indexes collapsed to [], so {"a":{"b":1},"c":[{"d":2}]} yields a.b and
+// c[].d. The leaves are scalars and empty objects and arrays. Merging the paths of many sample
+// documents into a set gives an inferred schema. Keys are written as is, so a key containing a
+// dot or brackets makes its path ambiguous. A scalar root has no key path.
+func KeyPaths(v Value) []string {
+	set := make(map[string]struct{})
+	collectKeyPaths("", v, set)
+
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// LeafPointers returns the RFC 6901 JSON Pointer of every leaf of the tree rooted at v, in the
+// order Walk visits them, so {"a":{"b~":1},"c":[{"d":2}]} yields /a/b~0 and /c/0/d. Unlike
+// KeyPaths, keys are escaped and every array index is kept, so each pointer resolves to exactly
+// one value. The leaves are scalars and empty objects and arrays, which are listed as their own
+// pointer so that no part of the document goes unlisted. A scalar or empty root yields the single
+// pointer "", which names the whole document.
+func LeafPointers(v Value) []string {
+	pointers := []string{}
+
+	_ = Walk(v, func(pointer string, v Value) error {
+		if isLeaf(v) {
+			pointers = append(pointers, pointer)
+		}
+
+		return nil
+	})
+
+	return pointers
+}
+
+// DeepestPath returns the JSON Pointer of the deepest leaf of the tree rooted at v, as listed by
+// LeafPointers, and its depth, the number of reference tokens in the pointer, so that the most
+// nested part of a document can be found when tuning MaxDepth. Of the leaves at the greatest
+// depth, the first one Walk visits is returned, which is the one with the smallest keys and
+// indexes along its path. A scalar or empty root yields "" and 0.
+func DeepestPath(v Value) (string, int) {
+	deepest, maxDepth := "", 0
+
+	_ = Walk(v, func(pointer string, v Value) error {
+		// Slashes inside keys are escaped as ~1, so each slash starts a reference token
+		if depth := strings.Count(pointer, "/"); isLeaf(v) && depth > maxDepth {
+			deepest, maxDepth = pointer, depth
+		}
+
+		return nil
+	})
+
+	return deepest, maxDepth
+}
+
+// isLeaf reports whether v is a leaf of a tree: a scalar or an empty object or array.
+func isLeaf(v Value) bool {
+	switch val := v.(type) {
+	case *Object:
+		return len(val.Pairs) == 0
+	case *Array:
+		return len(val.Elements) == 0
+	}
+
+	return true
+}
+
+// collectKeyPaths adds to set the key paths of the leaves below v, which is found at path.
+func collectKeyPaths(path string, v Value, set map[string]struct{}) {
+	switch val := v.(type) {
+	case *Object:
+		if len(val.Pairs) == 0 && path != "" {
+			set[path] = struct{}{}
+		}
+
+		for k, child := range val.Pairs {
+			if path == "" {
+				collectKeyPaths(k, child, set)
+			} else {
+				collectKeyPaths(path+"."+k, child, set)
+			}
+		}
+	case *Array:
+		if len(val.Elements) == 0 && path != "" {
+			set[path] = struct{}{}
+		}
+
+		for _, elem := range val.Elements {
+			collectKeyPaths(path+"[]", elem, set)
+		}
+	default:
+		if path != "" {
+			set[path] = struct{}{}
+		}
+	}
+}
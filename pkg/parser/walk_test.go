@@ -0,0 +1,168 @@
+package parser_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rafaelmgr12/jingo/pkg/parser"
+)
+
+func mustParse(t *testing.T, input string, opts ...parser.Option) parser.Value {
+	t.Helper()
+
+	value, err := parser.Parse(input, opts...)
+	if err != nil {
+		t.Fatalf("Error parsing JSON %s: %v", input, err)
+	}
+
+	return value
+}
+
+func TestWalk(t *testing.T) {
+	value := mustParse(t, `{"b": [1, {"c": true}], "a/x": "s", "skip": {"hidden": 1}}`)
+
+	var pointers []string
+
+	err := parser.Walk(value, func(pointer string, v parser.Value) error {
+		pointers = append(pointers, pointer)
+
+		if pointer == "/skip" {
+			return parser.SkipChildren
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"", "/a~1x", "/b", "/b/0", "/b/1", "/b/1/c", "/skip"}
+	if !reflect.DeepEqual(pointers, expected) {
+		t.Fatalf("Expected %v, got %v", expected, pointers)
+	}
+
+	stop := errors.New("stop")
+
+	err = parser.Walk(value, func(pointer string, _ parser.Value) error {
+		if pointer == "/b/0" {
+			return stop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected walk to stop with the callback error, got %v", err)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	value := mustParse(t, `{"title": "Hello", "tags": ["go", 1, "json"], "meta": {"author": "ana"}}`)
+
+	got := parser.Strings(value, false)
+	if expected := []string{"ana", "go", "json", "Hello"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	got = parser.Strings(value, true)
+	if expected := []string{"meta", "tags", "title", "author", "ana", "go", "json", "Hello"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCountKey(t *testing.T) {
+	value := mustParse(t, `[{"id": 1, "tags": {"id": "x"}}, {"name": "b"}, {"id": 3}]`)
+
+	if got := parser.CountKey(value, "id"); got != 3 {
+		t.Errorf("Expected 3 objects with id, got %d", got)
+	}
+
+	if got := parser.CountKey(value, "missing"); got != 0 {
+		t.Errorf("Expected 0 objects with missing, got %d", got)
+	}
+
+	var literals []string
+	for _, v := range parser.CollectKey(value, "id") {
+		literals = append(literals, v.TokenLiteral())
+	}
+
+	if expected := []string{"1", "x", "3"}; !reflect.DeepEqual(literals, expected) {
+		t.Errorf("Expected %v, got %v", expected, literals)
+	}
+}
+
+func TestKeyPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Nested", `{"a": {"b": 1}, "c": [{"d": 2}]}`, []string{"a.b", "c[].d"}},
+		{"Distinct across elements", `{"items": [{"id": 1, "tags": ["x"]}, {"id": 2, "price": 9.5}]}`, []string{"items[].id", "items[].price", "items[].tags[]"}},
+		{"Empty containers", `{"a": {}, "b": [], "c": null}`, []string{"a", "b", "c"}},
+		{"Root array", `[[1, 2], {"x": true}]`, []string{"[].x", "[][]"}},
+		{"Empty root", `{}`, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.KeyPaths(mustParse(t, tt.input)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLeafPointers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    parser.Value
+		expected []string
+	}{
+		{"Nested", mustParse(t, `{"a": {"b~": 1}, "c": [{"d": 2}, 3], "e/f": null}`), []string{"/a/b~0", "/c/0/d", "/c/1", "/e~1f"}},
+		{"Empty containers", mustParse(t, `{"a": {}, "b": [], "c": [[]]}`), []string{"/a", "/b", "/c/0"}},
+		{"Root array", mustParse(t, `[true, "x"]`), []string{"/0", "/1"}},
+		{"Empty root", mustParse(t, `{}`), []string{""}},
+		{"Scalar root", &parser.StringLiteral{Value: "x"}, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parser.LeafPointers(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+
+			for _, pointer := range got {
+				if _, ok := parser.ResolvePointer(tt.input, pointer); !ok {
+					t.Errorf("Expected %q to resolve", pointer)
+				}
+			}
+		})
+	}
+}
+
+func TestDeepestPath(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         parser.Value
+		expected      string
+		expectedDepth int
+	}{
+		{"Nested", mustParse(t, `{"a": 1, "b": {"c": [1, {"d": null}]}, "e": {"f": 2}}`), "/b/c/1/d", 4},
+		{"Ties take the first in walk order", mustParse(t, `{"z": {"y": 1}, "a": {"b": 2}}`), "/a/b", 2},
+		{"Empty container leaf", mustParse(t, `[1, [[{}]]]`), "/1/0/0", 3},
+		{"Escaped slash", mustParse(t, `{"a/b": {"c": 1}}`), "/a~1b/c", 2},
+		{"Empty root", mustParse(t, `[]`), "", 0},
+		{"Scalar root", &parser.NumberLiteral{Value: "1"}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointer, depth := parser.DeepestPath(tt.input)
+			if pointer != tt.expected || depth != tt.expectedDepth {
+				t.Errorf("Expected %q at depth %d, got %q at depth %d", tt.expected, tt.expectedDepth, pointer, depth)
+			}
+		})
+	}
+}